@@ -7,6 +7,7 @@ require (
 	github.com/evanphx/json-patch v4.1.0+incompatible
 	github.com/getkin/kin-openapi v0.115.0
 	github.com/graphql-go/graphql v0.7.6
+	github.com/invopop/yaml v0.1.0
 	github.com/jinzhu/inflection v1.0.0
 	github.com/lib/pq v1.10.7
 	github.com/rs/cors v1.6.0
@@ -21,7 +22,6 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-openapi/jsonpointer v0.19.5 // indirect
 	github.com/go-openapi/swag v0.19.5 // indirect
-	github.com/invopop/yaml v0.1.0 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
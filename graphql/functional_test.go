@@ -17,6 +17,7 @@ import (
 	"github.com/entropyinf/rest-layer/resource/testing/mem"
 	"github.com/entropyinf/rest-layer/rest"
 	"github.com/entropyinf/rest-layer/schema"
+	"github.com/entropyinf/rest-layer/schema/query"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -216,11 +217,206 @@ func TestHandler(t *testing.T) {
 	r.Header.Set("Content-Type", "application/json")
 	s, b = performRequest(gql, r)
 	assert.Equal(t, 400, s)
-	assert.Equal(t, "Cannot unmarshal JSON: invalid character 'i' looking for beginning of object key string\n{\"data\":null,\"errors\":[{\"message\":\"Must provide an operation.\",\"locations\":[]}]}\n", b)
+	assert.Equal(t, "Cannot unmarshal JSON: invalid character 'i' looking for beginning of object key string\n", b)
+
+	r, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"query":"query P($id: String) {posts(id: $id){id}}","variables":{"id":"ar5qrgukj5l7a6eq2ps0"},"operationName":"P"}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "{\"data\":{\"posts\":{\"id\":\"ar5qrgukj5l7a6eq2ps0\"}}}\n", b)
+
+	r, _ = http.NewRequest("GET", `/?query=query+A{postsList{id}}+query+B{usersAdmin{id}}&operationName=B`, nil)
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "{\"data\":{\"usersAdmin\":[{\"id\":\"johndoe\"}]}}\n", b)
+
+	r, _ = http.NewRequest("GET", "/?query={postsList{", nil)
+	s, _ = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+
+	r, _ = http.NewRequest("GET", "/?query={postsList{", nil)
+	r.Header.Set("Accept", "application/graphql-response+json")
+	s, _ = performRequest(gql, r)
+	assert.Equal(t, 400, s)
 
 	r, _ = http.NewRequest("PUT", "/", nil)
 	s, b = performRequest(gql, r)
 	assert.Equal(t, 405, s)
 	assert.Equal(t, "Method Not Allowed\n", b)
+}
+
+func TestHandlerPersistedQueries(t *testing.T) {
+	oldLogger := resource.Logger
+	resource.Logger = nil
+	defer func() { resource.Logger = oldLogger }()
+	index := resource.NewIndex()
+	index.Bind("users", user, mem.NewHandler(), resource.Conf{
+		AllowedModes: resource.ReadWrite,
+	})
+	gql, err := NewHandler(index)
+	assert.NoError(t, err)
+
+	query := "{usersList{id}}"
+	ext := url.QueryEscape(fmt.Sprintf(`{"persistedQuery":{"version":1,"sha256Hash":"%s"}}`, queryHash(query)))
+
+	r, _ := http.NewRequest("GET", "/?extensions="+ext, nil)
+	s, b := performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "{\"data\":null,\"errors\":[{\"message\":\"PersistedQueryNotSupported\",\"locations\":[],\"extensions\":{\"code\":\"PERSISTED_QUERY_NOT_SUPPORTED\"}}]}\n", b)
+
+	gql.PersistedQueries = NewMemoryPersistedQueryCache(0)
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "{\"data\":null,\"errors\":[{\"message\":\"PersistedQueryNotFound\",\"locations\":[],\"extensions\":{\"code\":\"PERSISTED_QUERY_NOT_FOUND\"}}]}\n", b)
+
+	r, _ = http.NewRequest("GET", "/?query={usersList{name}}&extensions="+ext, nil)
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "{\"data\":null,\"errors\":[{\"message\":\"provided sha does not match query\",\"locations\":[],\"extensions\":{\"code\":\"INVALID_SHA256_HASH\"}}]}\n", b)
+
+	r, _ = http.NewRequest("GET", "/?query="+url.QueryEscape(query)+"&extensions="+ext, nil)
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "{\"data\":{\"usersList\":[]}}\n", b)
+
+	r, _ = http.NewRequest("GET", "/?extensions="+ext, nil)
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "{\"data\":{\"usersList\":[]}}\n", b)
+}
+
+func TestMemoryPersistedQueryCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryPersistedQueryCache(2)
+	c.Add(ctx, "a", "{a}")
+	c.Add(ctx, "b", "{b}")
+	// Using a makes b the least recently used query.
+	_, found := c.Get(ctx, "a")
+	assert.True(t, found)
+	c.Add(ctx, "c", "{c}")
+	_, found = c.Get(ctx, "b")
+	assert.False(t, found)
+	q, found := c.Get(ctx, "a")
+	assert.True(t, found)
+	assert.Equal(t, "{a}", q)
+	q, found = c.Get(ctx, "c")
+	assert.True(t, found)
+	assert.Equal(t, "{c}", q)
+}
+
+func TestHandlerLimits(t *testing.T) {
+	oldLogger := resource.Logger
+	resource.Logger = nil
+	defer func() { resource.Logger = oldLogger }()
+	index := resource.NewIndex()
+	index.Bind("users", user, mem.NewHandler(), resource.Conf{
+		AllowedModes: resource.ReadWrite,
+	})
+	posts := index.Bind("posts", post, mem.NewHandler(), resource.Conf{
+		AllowedModes: resource.ReadWrite,
+	})
+	posts.Bind("followers", "post", postFollower, mem.NewHandler(), resource.Conf{
+		AllowedModes: resource.ReadWrite,
+	})
+	gql, err := NewHandler(index)
+	assert.NoError(t, err)
+	gql.MaxDepth = 3
+	gql.MaxComplexity = 3
+
+	r, _ := http.NewRequest("GET", "/?query={postsList{id,meta{title}}}", nil)
+	s, b := performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Contains(t, b, "query complexity 4 exceeds the maximum allowed complexity of 3")
+
+	r, _ = http.NewRequest("GET", "/?query={postsList{followers{user{id}}}}", nil)
+	r.Header.Set("Accept", "application/graphql-response+json")
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 400, s)
+	assert.Contains(t, b, "query depth 4 exceeds the maximum allowed depth of 3")
 
+	r, _ = http.NewRequest("GET", "/?query={...F}+fragment+F+on+RootQuery{postsList{id}}", nil)
+	r.Header.Set("Accept", "application/graphql-response+json")
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "{\"data\":{\"postsList\":[]}}\n", b)
+}
+
+func TestCheckLimitsNestedFragments(t *testing.T) {
+	// Each fragment spreads the next one twice: the query selects 2^40 fields.
+	var b strings.Builder
+	b.WriteString("{...F0}")
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&b, " fragment F%d on RootQuery{...F%d ...F%d}", i, i+1, i+1)
+	}
+	b.WriteString(" fragment F40 on RootQuery{postsList{id}}")
+	query := b.String()
+
+	assert.NoError(t, checkLimits(query, "", 0, 0))
+	assert.NoError(t, checkLimits(query, "", 2, 0))
+	assert.EqualError(t, checkLimits(query, "", 1, 0), "query depth 2 exceeds the maximum allowed depth of 1")
+	assert.EqualError(t, checkLimits(query, "", 0, 100), "query complexity 128 exceeds the maximum allowed complexity of 100")
+}
+
+func TestHandlerQueryLimits(t *testing.T) {
+	oldLogger := resource.Logger
+	resource.Logger = nil
+	defer func() { resource.Logger = oldLogger }()
+	index := resource.NewIndex()
+	index.Bind("users", user, mem.NewHandler(), resource.Conf{
+		AllowedModes: resource.ReadWrite,
+		QueryLimits: query.Limits{
+			MaxPredicateDepth: 1,
+			MaxLimit:          10,
+		},
+	})
+	gql, err := NewHandler(index)
+	assert.NoError(t, err)
+
+	r, _ := http.NewRequest("GET", `/?query={usersList(filter:"{$or:[{admin:true},{id:\"ab\"}]}"){id}}`, nil)
+	s, b := performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Contains(t, b, "invalid `filter` parameter: predicate depth of 2 exceeds the maximum of 1")
+
+	r, _ = http.NewRequest("GET", "/?query={usersList(limit:20){id}}", nil)
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Contains(t, b, "invalid `limit` parameter: 20 exceeds the maximum of 10")
+
+	r, _ = http.NewRequest("GET", `/?query={usersList(limit:5,filter:"{admin:true}"){id}}`, nil)
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "{\"data\":{\"usersList\":[]}}\n", b)
+}
+
+func TestHandlerDefaultLimit(t *testing.T) {
+	oldLogger := resource.Logger
+	resource.Logger = nil
+	defer func() { resource.Logger = oldLogger }()
+	index := resource.NewIndex()
+	users := index.Bind("users", user, mem.NewHandler(), resource.Conf{
+		AllowedModes:           resource.ReadWrite,
+		PaginationDefaultLimit: 100,
+		QueryLimits:            query.Limits{MaxLimit: 2},
+	})
+	for _, id := range []string{"user1", "user2", "user3"} {
+		item, _ := resource.NewItem(map[string]interface{}{"id": id})
+		assert.NoError(t, users.Insert(context.Background(), []*resource.Item{item}))
+	}
+	gql, err := NewHandler(index)
+	assert.NoError(t, err)
+
+	r, _ := http.NewRequest("GET", "/?query={usersList{id}}", nil)
+	s, b := performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Equal(t, "{\"data\":{\"usersList\":[{\"id\":\"user1\"},{\"id\":\"user2\"}]}}\n", b)
+
+	r, _ = http.NewRequest("GET", "/?query={usersList(limit:-1){id}}", nil)
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Contains(t, b, "invalid `limit` parameter: must be between 0 and 999")
+
+	r, _ = http.NewRequest("GET", "/?query={usersList(limit:1000){id}}", nil)
+	s, b = performRequest(gql, r)
+	assert.Equal(t, 200, s)
+	assert.Contains(t, b, "invalid `limit` parameter: must be between 0 and 999")
 }
@@ -3,20 +3,76 @@ package graphql
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"strings"
 
-	"github.com/graphql-go/graphql"
 	"github.com/entropyinf/rest-layer/resource"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+const (
+	// contentTypeJSON is the legacy GraphQL over HTTP response media type.
+	contentTypeJSON = "application/json"
+	// contentTypeGraphQLResponse is the GraphQL over HTTP response media type.
+	contentTypeGraphQLResponse = "application/graphql-response+json"
 )
 
 // Handler is a net/http compatible handler used to serve the configured GraphQL
 // API.
 type Handler struct {
+	// PersistedQueries enables automatic persisted queries when set. Clients
+	// can then send the SHA-256 hash of a query in the
+	// extensions.persistedQuery.sha256Hash parameter instead of the query.
+	PersistedQueries PersistedQueryCache
+	// MaxDepth is the maximum depth of the selection set of an operation. A
+	// value of 0 means no limit.
+	MaxDepth int
+	// MaxComplexity is the maximum number of fields an operation may select,
+	// including fields selected through fragments. A value of 0 means no
+	// limit.
+	MaxComplexity int
+
 	schema graphql.Schema
 }
 
+// request holds the parameters of a GraphQL over HTTP request.
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    struct {
+		PersistedQuery *persistedQuery `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// persistedQuery is the automatic persisted query request extension.
+type persistedQuery struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// requestError is an error preventing the execution of a request.
+type requestError struct {
+	message string
+	code    string
+}
+
+func (e requestError) Error() string {
+	return e.message
+}
+
+var (
+	errPersistedQueryNotSupported = requestError{"PersistedQueryNotSupported", "PERSISTED_QUERY_NOT_SUPPORTED"}
+	errPersistedQueryNotFound     = requestError{"PersistedQueryNotFound", "PERSISTED_QUERY_NOT_FOUND"}
+	errPersistedQueryMismatch     = requestError{"provided sha does not match query", "INVALID_SHA256_HASH"}
+	errMissingQuery               = requestError{"Must provide an operation.", "BAD_REQUEST"}
+)
+
 // NewHandler creates an new GraphQL API HTTP handler with the specified
 // resource index.
 func NewHandler(i resource.Index) (*Handler, error) {
@@ -43,36 +99,139 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // ServeHTTPC handles requests as a xhandler.HandlerC (deprecated).
 func (h *Handler) ServeHTTPC(ctx context.Context, w http.ResponseWriter, r *http.Request) {
-	var query string
+	req, err := parseRequest(r)
+	if err != nil {
+		if err == errMethodNotAllowed {
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var result *graphql.Result
+	if err := h.resolveQuery(ctx, req); err != nil {
+		result = newErrorResult(err)
+	} else if err := checkLimits(req.Query, req.OperationName, h.MaxDepth, h.MaxComplexity); err != nil {
+		result = newErrorResult(requestError{err.Error(), "QUERY_TOO_COMPLEX"})
+	} else {
+		result = graphql.Do(graphql.Params{
+			Context:        ctx,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Schema:         h.schema,
+		})
+	}
+	if resource.Logger != nil {
+		if len(result.Errors) > 0 {
+			resource.Logger(ctx, resource.LogLevelError, fmt.Sprintf("wrong result, unexpected errors: %v", result.Errors), nil)
+		}
+	}
+	status := http.StatusOK
+	contentType := contentTypeJSON
+	if acceptsGraphQLResponse(r) {
+		contentType = contentTypeGraphQLResponse
+		// With the graphql-response media type, a request error (a response
+		// without data) must be reported with a 4xx status.
+		if result.Data == nil && len(result.Errors) > 0 {
+			status = http.StatusBadRequest
+		}
+	}
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// resolveQuery resolves the query of an automatic persisted query request,
+// storing it in the persisted query cache or fetching it from there.
+func (h *Handler) resolveQuery(ctx context.Context, req *request) error {
+	pq := req.Extensions.PersistedQuery
+	if pq == nil {
+		if req.Query == "" {
+			return errMissingQuery
+		}
+		return nil
+	}
+	if h.PersistedQueries == nil {
+		return errPersistedQueryNotSupported
+	}
+	if req.Query == "" {
+		q, found := h.PersistedQueries.Get(ctx, pq.Sha256Hash)
+		if !found {
+			return errPersistedQueryNotFound
+		}
+		req.Query = q
+		return nil
+	}
+	if queryHash(req.Query) != pq.Sha256Hash {
+		return errPersistedQueryMismatch
+	}
+	h.PersistedQueries.Add(ctx, pq.Sha256Hash, req.Query)
+	return nil
+}
+
+var errMethodNotAllowed = errors.New("Method Not Allowed")
+
+// parseRequest extracts GraphQL request parameters from r.
+func parseRequest(r *http.Request) (*request, error) {
+	req := &request{}
 	switch r.Method {
 	case "GET":
-		query = r.URL.Query().Get("query")
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return nil, fmt.Errorf("Cannot unmarshal variables: %v", err)
+			}
+		}
+		if e := q.Get("extensions"); e != "" {
+			if err := json.Unmarshal([]byte(e), &req.Extensions); err != nil {
+				return nil, fmt.Errorf("Cannot unmarshal extensions: %v", err)
+			}
+		}
 	case "POST":
-		b, _ := ioutil.ReadAll(r.Body)
+		b, err := ioutil.ReadAll(r.Body)
 		r.Body.Close()
-		if r.Header.Get("Content-Type") == "application/json" {
-			q := map[string]interface{}{}
-			if err := json.Unmarshal(b, &q); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot unmarshal JSON: %v", err), http.StatusBadRequest)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read body: %v", err)
+		}
+		mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mt == contentTypeJSON {
+			if err := json.Unmarshal(b, req); err != nil {
+				return nil, fmt.Errorf("Cannot unmarshal JSON: %v", err)
 			}
-			query, _ = q["query"].(string)
 		} else {
-			query = string(b)
+			req.Query = string(b)
+		}
+		// Allow operationName to be passed in the URL for non JSON bodies.
+		if req.OperationName == "" {
+			req.OperationName = r.URL.Query().Get("operationName")
 		}
 	default:
-		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
-		return
+		return nil, errMethodNotAllowed
 	}
-	result := graphql.Do(graphql.Params{
-		Context:       ctx,
-		RequestString: query,
-		Schema:        h.schema,
-	})
-	if resource.Logger != nil {
-		if len(result.Errors) > 0 {
-			resource.Logger(ctx, resource.LogLevelError, fmt.Sprintf("wrong result, unexpected errors: %v", result.Errors), nil)
+	return req, nil
+}
+
+// acceptsGraphQLResponse returns true if the client accepts the
+// application/graphql-response+json media type.
+func acceptsGraphQLResponse(r *http.Request) bool {
+	for _, a := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(a))
+		if err == nil && mt == contentTypeGraphQLResponse {
+			return true
 		}
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	return false
+}
+
+// newErrorResult creates a result without data reporting err.
+func newErrorResult(err error) *graphql.Result {
+	fe := gqlerrors.NewFormattedError(err.Error())
+	if re, ok := err.(requestError); ok {
+		fe.Extensions = map[string]interface{}{"code": re.code}
+	}
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{fe}}
 }
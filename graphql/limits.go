@@ -0,0 +1,141 @@
+package graphql
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// checkLimits parses query and ensures the selected operation does not exceed
+// the given maximum depth and complexity. A limit of 0 disables the check.
+// Parse errors are ignored as they are reported by the executor.
+func checkLimits(query, operationName string, maxDepth, maxComplexity int) error {
+	if maxDepth <= 0 && maxComplexity <= 0 {
+		return nil
+	}
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(query),
+			Name: "GraphQL request",
+		}),
+	})
+	if err != nil {
+		return nil
+	}
+	fragments := map[string]*ast.FragmentDefinition{}
+	ops := []*ast.OperationDefinition{}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			if def.Name != nil {
+				fragments[def.Name.Value] = def
+			}
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				ops = append(ops, def)
+			}
+		}
+	}
+	m := measurer{
+		fragments:     fragments,
+		maxDepth:      maxDepth,
+		maxComplexity: maxComplexity,
+		measured:      map[string]fragmentSize{},
+		visiting:      map[string]bool{},
+	}
+	for _, op := range ops {
+		m.complexity = 0
+		if m.measure(op.SelectionSet, 0); m.err != nil {
+			return m.err
+		}
+	}
+	return nil
+}
+
+// measurer walks a selection set computing its depth and complexity. The
+// complexity is the total number of fields selected, fragments included. The
+// size of each fragment is measured once, so fragments spreading other
+// fragments several times don't take exponential time, and the walk stops as
+// soon as a limit is exceeded.
+type measurer struct {
+	fragments     map[string]*ast.FragmentDefinition
+	maxDepth      int
+	maxComplexity int
+	// measured holds the size of the fragments already measured.
+	measured   map[string]fragmentSize
+	visiting   map[string]bool
+	complexity int
+	err        error
+}
+
+// fragmentSize is the depth and complexity of a fragment, relative to the
+// selection set it is spread in.
+type fragmentSize struct {
+	depth      int
+	complexity int
+}
+
+// measure returns the depth of set relative to depth, the depth of the
+// selection set containing it, and adds its fields to the complexity.
+func (m *measurer) measure(set *ast.SelectionSet, depth int) int {
+	if set == nil {
+		return 0
+	}
+	max := 0
+	for _, sel := range set.Selections {
+		if m.err != nil {
+			return max
+		}
+		d := 0
+		switch sel := sel.(type) {
+		case *ast.Field:
+			m.checkDepth(depth + 1)
+			m.addComplexity(1)
+			d = 1 + m.measure(sel.SelectionSet, depth+1)
+		case *ast.InlineFragment:
+			d = m.measure(sel.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			if sel.Name == nil {
+				continue
+			}
+			name := sel.Name.Value
+			if size, found := m.measured[name]; found {
+				m.checkDepth(depth + size.depth)
+				m.addComplexity(size.complexity)
+				d = size.depth
+				break
+			}
+			f, found := m.fragments[name]
+			if !found || m.visiting[name] {
+				// Unknown or cyclic fragments are reported by the validator.
+				continue
+			}
+			m.visiting[name] = true
+			before := m.complexity
+			d = m.measure(f.SelectionSet, depth)
+			m.visiting[name] = false
+			if m.err == nil {
+				m.measured[name] = fragmentSize{depth: d, complexity: m.complexity - before}
+			}
+		}
+		if d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+func (m *measurer) addComplexity(n int) {
+	m.complexity += n
+	if m.err == nil && m.maxComplexity > 0 && m.complexity > m.maxComplexity {
+		m.err = fmt.Errorf("query complexity %d exceeds the maximum allowed complexity of %d", m.complexity, m.maxComplexity)
+	}
+}
+
+func (m *measurer) checkDepth(depth int) {
+	if m.err == nil && m.maxDepth > 0 && depth > m.maxDepth {
+		m.err = fmt.Errorf("query depth %d exceeds the maximum allowed depth of %d", depth, m.maxDepth)
+	}
+}
@@ -0,0 +1,87 @@
+package graphql
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// DefaultPersistedQueryCacheSize is the number of queries kept by a
+// MemoryPersistedQueryCache created with no size.
+const DefaultPersistedQueryCacheSize = 1000
+
+// PersistedQueryCache stores GraphQL documents by the hex encoded SHA-256 hash
+// of their body so clients implementing the automatic persisted queries
+// protocol can send the hash instead of the full query.
+type PersistedQueryCache interface {
+	// Get returns the query stored for hash, if any.
+	Get(ctx context.Context, hash string) (query string, found bool)
+	// Add stores query for hash.
+	Add(ctx context.Context, hash string, query string)
+}
+
+// MemoryPersistedQueryCache is a simple in-memory PersistedQueryCache. As any
+// client can add queries, it holds a bounded number of them and evicts the
+// least recently used one when full.
+type MemoryPersistedQueryCache struct {
+	mu      sync.Mutex
+	size    int
+	lru     *list.List
+	queries map[string]*list.Element
+}
+
+type persistedQueryEntry struct {
+	hash  string
+	query string
+}
+
+// NewMemoryPersistedQueryCache creates an empty in-memory persisted query
+// cache holding up to size queries. If size is zero or negative,
+// DefaultPersistedQueryCacheSize is used.
+func NewMemoryPersistedQueryCache(size int) *MemoryPersistedQueryCache {
+	if size <= 0 {
+		size = DefaultPersistedQueryCacheSize
+	}
+	return &MemoryPersistedQueryCache{
+		size:    size,
+		lru:     list.New(),
+		queries: map[string]*list.Element{},
+	}
+}
+
+// Get implements PersistedQueryCache.
+func (c *MemoryPersistedQueryCache) Get(ctx context.Context, hash string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, found := c.queries[hash]
+	if !found {
+		return "", false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*persistedQueryEntry).query, true
+}
+
+// Add implements PersistedQueryCache.
+func (c *MemoryPersistedQueryCache) Add(ctx context.Context, hash string, query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, found := c.queries[hash]; found {
+		e.Value.(*persistedQueryEntry).query = query
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.queries[hash] = c.lru.PushFront(&persistedQueryEntry{hash: hash, query: query})
+	if c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.queries, e.Value.(*persistedQueryEntry).hash)
+	}
+}
+
+// queryHash returns the hex encoded SHA-256 hash of query.
+func queryHash(query string) string {
+	h := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(h[:])
+}
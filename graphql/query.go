@@ -90,10 +90,17 @@ func listParamResolver(r *resource.Resource, p graphql.ResolveParams, params url
 	if i, ok := p.Args["page"].(int); ok && i > 0 && i < 1000 {
 		page = i
 	}
-	if i, ok := p.Args["limit"].(int); ok && i >= 0 && i < 1000 {
+	limits := r.Conf().QueryLimits
+	if i, ok := p.Args["limit"].(int); ok {
+		if i < 0 || i >= 1000 {
+			return nil, errors.New("invalid `limit` parameter: must be between 0 and 999")
+		}
+		if err := limits.CheckWindow(&query.Window{Limit: i}); err != nil {
+			return nil, fmt.Errorf("invalid `limit` parameter: %v", err)
+		}
 		limit = i
-	} else if l := r.Conf().PaginationDefaultLimit; l > 0 {
-		limit = l
+	} else {
+		limit = limits.DefaultLimit(r.Conf().PaginationDefaultLimit)
 	}
 	if page != 1 && limit == -1 {
 		return nil, errors.New("cannot use `page' parameter with no `limit' parameter on a resource with no default pagination size")
@@ -105,20 +112,29 @@ func listParamResolver(r *resource.Resource, p graphql.ResolveParams, params url
 		if err == nil {
 			err = s.Validate(r.Validator())
 		}
+		if err == nil {
+			err = s.CheckReadPermission(p.Context, r.Validator())
+		}
 		if err != nil {
 			return nil, fmt.Errorf("invalid `sort` parameter: %v", err)
 		}
 		q.Sort = s
 	}
 	if filter, ok := p.Args["filter"].(string); ok && filter != "" {
-		p, err := query.ParsePredicate(filter)
+		pr, err := query.ParsePredicate(filter)
+		if err == nil {
+			err = pr.Prepare(r.Validator())
+		}
+		if err == nil {
+			err = limits.CheckPredicate(pr)
+		}
 		if err == nil {
-			err = p.Prepare(r.Validator())
+			err = pr.CheckReadPermission(p.Context, r.Validator())
 		}
 		if err != nil {
 			return nil, fmt.Errorf("invalid `filter` parameter: %v", err)
 		}
-		q.Predicate = p
+		q.Predicate = pr
 	}
 	if params != nil {
 		if filter := params.Get("filter"); filter != "" {
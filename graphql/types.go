@@ -155,16 +155,21 @@ func getFArgs(p schema.Params) graphql.FieldConfigArgument {
 // getFResolver returns a GraphQL field resolver for REST layer field handler.
 func getFResolver(fieldName string, f schema.Field) graphql.FieldResolveFn {
 	s, serialize := f.Validator.(schema.FieldSerializer)
-	if !serialize && f.Handler == nil {
+	if !serialize && f.Handler == nil && f.ReadPermission == nil && f.Compute == nil {
 		return nil
 	}
 	return func(rp graphql.ResolveParams) (interface{}, error) {
 		data, ok := rp.Source.(map[string]interface{})
-		if !ok {
+		if !ok || !f.CanRead(rp.Context, data) {
 			return nil, nil
 		}
 		var err error
 		val := data[fieldName]
+		if f.Compute != nil {
+			if val, err = f.Compute(rp.Context, data); err != nil {
+				return nil, err
+			}
+		}
 		if f.Handler != nil {
 			val, err = f.Handler(rp.Context, val, rp.Args)
 		}
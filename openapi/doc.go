@@ -0,0 +1,35 @@
+/*
+Package openapi generates an OpenAPI 3 document describing the resources of a
+REST Layer resource index, and serves it over HTTP.
+
+The generated document can either be filled into an existing document using
+FillOpenapiFromIndex, or served along with the API using Handler:
+
+	api, err := rest.NewHandler(index)
+	if err != nil {
+		log.Fatal(err)
+	}
+	api.FallbackHandlerFunc = openapi.Handler(index, &openapi3.Info{
+		Title:   "My API",
+		Version: "1.0",
+	}).ServeHTTPC
+
+The specification is then available as /openapi.json and /openapi.yaml, and a
+browsable HTML version as /openapi.html.
+
+The Importer goes the other way around: it creates schemas and a resource index
+skeleton from an existing OpenAPI document, which is handy to stand up a mock
+API from a contract:
+
+	doc, err := openapi3.NewLoader().LoadFromFile("api.yaml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	index, err := openapi.NewImporter(doc).ImportIndex(func(path string) resource.Storer {
+		return mem.NewHandler()
+	})
+
+This package is part of the rest-layer project. See http://rest-layer.io for
+full REST Layer documentation.
+*/
+package openapi
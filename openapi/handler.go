@@ -0,0 +1,221 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/entropyinf/rest-layer/resource"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/yaml"
+)
+
+// SpecHandler is a net/http compatible handler serving the OpenAPI document
+// generated from a resource index as /openapi.json and /openapi.yaml, as well
+// as a static HTML explorer of the API as /openapi.html.
+//
+// The document is generated on the first request and cached afterward, the
+// index must thus not be modified once the handler started serving requests.
+//
+// The handler can be mounted as the fallback of a rest.Handler so the
+// document is served along with the API:
+//
+//	api, _ := rest.NewHandler(index)
+//	api.FallbackHandlerFunc = openapi.Handler(index, info).ServeHTTPC
+type SpecHandler struct {
+	index resource.Index
+	info  *openapi3.Info
+
+	once     sync.Once
+	err      error
+	doc      *openapi3.T
+	jsonSpec []byte
+	yamlSpec []byte
+	htmlSpec []byte
+}
+
+// Handler creates a handler serving the OpenAPI document describing the
+// resources of index.
+func Handler(index resource.Index, info *openapi3.Info) *SpecHandler {
+	return &SpecHandler{index: index, info: info}
+}
+
+// Doc returns the OpenAPI document generated from the index.
+func (h *SpecHandler) Doc() (*openapi3.T, error) {
+	h.once.Do(h.generate)
+	return h.doc, h.err
+}
+
+// generate builds the OpenAPI document and its serialized forms.
+func (h *SpecHandler) generate() {
+	doc := &openapi3.T{
+		Info:  h.info,
+		Paths: openapi3.Paths{},
+	}
+	FillOpenapiFromIndex(h.index, doc)
+	if h.jsonSpec, h.err = json.Marshal(doc); h.err != nil {
+		return
+	}
+	if h.yamlSpec, h.err = yaml.JSONToYAML(h.jsonSpec); h.err != nil {
+		return
+	}
+	buf := &bytes.Buffer{}
+	if h.err = explorerTemplate.Execute(buf, newExplorer(doc)); h.err != nil {
+		return
+	}
+	h.htmlSpec = buf.Bytes()
+	h.doc = doc
+}
+
+// ServeHTTP handles requests as a http.Handler.
+func (h *SpecHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.ServeHTTPC(r.Context(), w, r)
+}
+
+// ServeHTTPC handles requests as a xhandler.HandlerC (deprecated). Its
+// signature matches rest.Handler.FallbackHandlerFunc.
+func (h *SpecHandler) ServeHTTPC(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var contentType string
+	var body func() []byte
+	switch r.URL.Path {
+	case "/openapi.json":
+		contentType, body = "application/json", func() []byte { return h.jsonSpec }
+	case "/openapi.yaml":
+		contentType, body = "application/yaml", func() []byte { return h.yamlSpec }
+	case "/openapi.html":
+		contentType, body = "text/html; charset=utf-8", func() []byte { return h.htmlSpec }
+	default:
+		sendError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		sendError(w, http.StatusMethodNotAllowed, "Invalid Method")
+		return
+	}
+	if _, err := h.Doc(); err != nil {
+		sendError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method != "HEAD" {
+		w.Write(body())
+	}
+}
+
+// sendError sends an error formatted the same way as rest errors.
+func sendError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    code,
+		"message": message,
+	})
+}
+
+// explorer is the data rendered by explorerTemplate.
+type explorer struct {
+	Info       *openapi3.Info
+	Operations []explorerOperation
+	Schemas    []explorerSchema
+}
+
+type explorerOperation struct {
+	Method     string
+	Path       string
+	Tag        string
+	Operation  *openapi3.Operation
+	Parameters []*openapi3.Parameter
+}
+
+type explorerSchema struct {
+	Name string
+	JSON string
+}
+
+func newExplorer(doc *openapi3.T) explorer {
+	e := explorer{Info: doc.Info}
+	components := doc.Components.Parameters
+	for _, path := range doc.Paths.InMatchingOrder() {
+		for method, op := range doc.Paths[path].Operations() {
+			eo := explorerOperation{Method: method, Path: path, Operation: op}
+			if len(op.Tags) > 0 {
+				eo.Tag = op.Tags[0]
+			}
+			for _, p := range op.Parameters {
+				if p.Value == nil && strings.HasPrefix(p.Ref, "#/components/parameters/") {
+					if c := components[strings.TrimPrefix(p.Ref, "#/components/parameters/")]; c != nil {
+						eo.Parameters = append(eo.Parameters, c.Value)
+					}
+					continue
+				}
+				if p.Value != nil {
+					eo.Parameters = append(eo.Parameters, p.Value)
+				}
+			}
+			e.Operations = append(e.Operations, eo)
+		}
+	}
+	sort.Slice(e.Operations, func(i, j int) bool {
+		a, b := e.Operations[i], e.Operations[j]
+		if a.Tag != b.Tag {
+			return a.Tag < b.Tag
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	for name, s := range doc.Components.Schemas {
+		j, _ := json.MarshalIndent(s, "", "  ")
+		e.Schemas = append(e.Schemas, explorerSchema{Name: name, JSON: string(j)})
+	}
+	sort.Slice(e.Schemas, func(i, j int) bool {
+		return e.Schemas[i].Name < e.Schemas[j].Name
+	})
+	return e
+}
+
+var explorerTemplate = template.Must(template.New("explorer").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{with .Info}}{{.Title}}{{end}}</title>
+<style>
+body{font-family:sans-serif;max-width:960px;margin:auto;padding:1em}
+details{border:1px solid #ddd;border-radius:4px;margin:.5em 0;padding:.5em}
+summary{cursor:pointer}
+code.method{display:inline-block;width:5em;font-weight:bold}
+pre{background:#f6f6f6;padding:.5em;overflow:auto}
+td,th{text-align:left;padding:0 1em 0 0;vertical-align:top}
+</style>
+</head>
+<body>
+{{with .Info}}<h1>{{.Title}} <small>{{.Version}}</small></h1>
+{{if .Description}}<p>{{.Description}}</p>{{end}}{{end}}
+<p>Download the specification as <a href="openapi.json">JSON</a> or <a href="openapi.yaml">YAML</a>.</p>
+<h2>Operations</h2>
+{{range .Operations}}<details id="{{.Operation.OperationID}}">
+<summary><code class="method">{{.Method}}</code> <code>{{.Path}}</code> {{.Operation.Summary}}</summary>
+{{if .Parameters}}<table>
+<tr><th>Parameter</th><th>In</th><th>Description</th></tr>
+{{range .Parameters}}<tr><td><code>{{.Name}}</code>{{if .Required}}*{{end}}</td><td>{{.In}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>{{end}}
+<ul>{{range $code, $resp := .Operation.Responses}}<li><code>{{$code}}</code>{{with $resp.Value}}{{with .Description}} {{.}}{{end}}{{else}} {{$resp.Ref}}{{end}}</li>{{end}}</ul>
+</details>
+{{end}}
+<h2>Schemas</h2>
+{{range .Schemas}}<details id="schema-{{.Name}}">
+<summary><code>{{.Name}}</code></summary>
+<pre>{{.JSON}}</pre>
+</details>
+{{end}}
+</body>
+</html>
+`))
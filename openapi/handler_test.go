@@ -0,0 +1,71 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/resource/testing/mem"
+	"github.com/entropyinf/rest-layer/rest"
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	index := newTestIndex()
+	index.Bind("readonly", schema.Schema{
+		Fields: schema.Fields{"id": schema.IDField},
+	}, mem.NewHandler(), resource.Conf{AllowedModes: resource.ReadOnly})
+	api, err := rest.NewHandler(index)
+	if !assert.NoError(t, err) {
+		return
+	}
+	h := Handler(index, &openapi3.Info{Title: "Test API", Version: "1.0"})
+	api.FallbackHandlerFunc = h.ServeHTTPC
+
+	tests := []struct {
+		method, path string
+		status       int
+		contentType  string
+		contains     string
+	}{
+		{"GET", "/openapi.json", 200, "application/json", `"title":"Test API"`},
+		{"GET", "/openapi.yaml", 200, "application/yaml", "title: Test API"},
+		{"GET", "/openapi.html", 200, "text/html; charset=utf-8", "<code>/users/{userId}</code>"},
+		{"HEAD", "/openapi.json", 200, "application/json", ""},
+		{"POST", "/openapi.json", 405, "application/json", `"message":"Invalid Method"`},
+		{"GET", "/unknown", 404, "application/json", `"message":"Not Found"`},
+		{"GET", "/users", 200, "application/json", "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest(tt.method, tt.path, nil)
+			api.ServeHTTP(w, r)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Body.String(), tt.contains)
+		})
+	}
+
+	doc, err := h.Doc()
+	assert.NoError(t, err)
+	doc2, _ := h.Doc()
+	assert.True(t, doc == doc2, "document should be cached")
+	item := doc.Paths.Find("/readonly/{readonlyId}")
+	if assert.NotNil(t, item) {
+		assert.NotNil(t, item.Get)
+		assert.Nil(t, item.Put)
+		assert.Nil(t, item.Patch)
+		assert.Nil(t, item.Delete)
+	}
+	list := doc.Paths.Find("/readonly")
+	if assert.NotNil(t, list) {
+		assert.Nil(t, list.Post)
+		assert.Nil(t, list.Delete)
+	}
+	assert.False(t, strings.Contains(string(h.jsonSpec), `"operationId":"CreateReadonly"`))
+}
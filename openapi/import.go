@@ -0,0 +1,540 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/jinzhu/inflection"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// dateLayout is the layout of the date format (RFC 3339 full-date).
+const dateLayout = "2006-01-02"
+
+// Importer converts OpenAPI 3 documents into REST Layer schemas and resource
+// indexes. It is the reverse of FillOpenapiFromIndex.
+type Importer struct {
+	// Doc is the OpenAPI document to import.
+	Doc *openapi3.T
+	// References maps component schema names to the path of the resource
+	// storing them. Properties referencing those component schemas are
+	// imported as schema.Reference fields instead of being inlined. When
+	// ImportIndex is used, it is filled from the discovered resources.
+	References map[string]string
+
+	// visiting holds the names of the component schemas being imported, to
+	// detect recursive references.
+	visiting map[string]bool
+}
+
+// NewImporter creates an importer for doc.
+func NewImporter(doc *openapi3.T) *Importer {
+	return &Importer{
+		Doc:        doc,
+		References: map[string]string{},
+	}
+}
+
+// ImportSchemas returns a schema for each object schema defined in the
+// components of the document, indexed by component name.
+func (im *Importer) ImportSchemas() (map[string]schema.Schema, error) {
+	schemas := map[string]schema.Schema{}
+	if im.Doc.Components.Schemas == nil {
+		return schemas, nil
+	}
+	for name, ref := range im.Doc.Components.Schemas {
+		s, err := im.resolve(ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if s.Type != "object" && len(s.Properties) == 0 {
+			continue
+		}
+		im.visit(name)
+		schemas[name], err = im.ImportSchema(s)
+		im.leave(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return schemas, nil
+}
+
+// ImportSchema converts an OpenAPI object schema into a REST Layer schema.
+func (im *Importer) ImportSchema(s *openapi3.Schema) (schema.Schema, error) {
+	ret := schema.Schema{
+		Description: s.Description,
+		Fields:      schema.Fields{},
+		MinLen:      int(s.MinProps),
+	}
+	if s.MaxProps != nil {
+		ret.MaxLen = int(*s.MaxProps)
+	}
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	for name, prop := range s.Properties {
+		f, err := im.importField(prop)
+		if err != nil {
+			return ret, fmt.Errorf("%s: %v", name, err)
+		}
+		f.Required = required[name]
+		ret.Fields[name] = f
+	}
+	return ret, nil
+}
+
+// importField converts an OpenAPI property schema into a field. Schemas are
+// imported into a tree, so a component schema referencing itself can only be
+// imported if it is stored by a resource (see References).
+func (im *Importer) importField(ref *openapi3.SchemaRef) (schema.Field, error) {
+	s, err := im.resolve(ref)
+	if err != nil {
+		return schema.Field{}, err
+	}
+	f := schema.Field{
+		Description: s.Description,
+		ReadOnly:    s.ReadOnly,
+		Default:     s.Default,
+	}
+	name := refName(ref)
+	if path, found := im.References[name]; found {
+		f.Validator = &schema.Reference{Path: path}
+		return f, nil
+	}
+	if name != "" {
+		if im.visiting[name] {
+			return f, fmt.Errorf("recursive reference: %s", ref.Ref)
+		}
+		im.visit(name)
+		defer im.leave(name)
+	}
+	f.Validator, err = im.importValidator(s)
+	return f, err
+}
+
+func (im *Importer) visit(name string) {
+	if im.visiting == nil {
+		im.visiting = map[string]bool{}
+	}
+	im.visiting[name] = true
+}
+
+func (im *Importer) leave(name string) {
+	delete(im.visiting, name)
+}
+
+// importValidator converts an OpenAPI schema into a field validator.
+func (im *Importer) importValidator(s *openapi3.Schema) (schema.FieldValidator, error) {
+	switch {
+	case len(s.AllOf) > 0:
+		vs, err := im.importValidators(s.AllOf)
+		v := schema.AllOf(vs)
+		return &v, err
+	case len(s.AnyOf) > 0:
+		vs, err := im.importValidators(s.AnyOf)
+		v := schema.AnyOf(vs)
+		return &v, err
+	case len(s.OneOf) > 0 && s.Discriminator != nil:
+		return im.importOneOf(s)
+	case len(s.OneOf) > 0:
+		vs, err := im.importValidators(s.OneOf)
+		v := schema.AnyOf(vs)
+		return &v, err
+	}
+	switch s.Type {
+	case "string":
+		return importString(s), nil
+	case "integer":
+		v := &schema.Integer{Boundaries: importBoundaries(s)}
+		for _, e := range s.Enum {
+			if n, ok := e.(float64); ok {
+				v.Allowed = append(v.Allowed, int(n))
+			} else if n, ok := e.(int); ok {
+				v.Allowed = append(v.Allowed, n)
+			}
+		}
+		return v, nil
+	case "number":
+		v := &schema.Float{Boundaries: importBoundaries(s)}
+		for _, e := range s.Enum {
+			switch n := e.(type) {
+			case float64:
+				v.Allowed = append(v.Allowed, n)
+			case int:
+				v.Allowed = append(v.Allowed, float64(n))
+			}
+		}
+		return v, nil
+	case "boolean":
+		return &schema.Bool{}, nil
+	case "array":
+		v := &schema.Array{MinLen: int(s.MinItems)}
+		if s.MaxItems != nil {
+			v.MaxLen = int(*s.MaxItems)
+		}
+		if s.Items != nil {
+			var err error
+			if v.Values, err = im.importField(s.Items); err != nil {
+				return nil, fmt.Errorf("items: %v", err)
+			}
+		}
+		return v, nil
+	case "object", "":
+		if len(s.Properties) > 0 {
+			sub, err := im.ImportSchema(s)
+			if err != nil {
+				return nil, err
+			}
+			return &schema.Object{Schema: &sub}, nil
+		}
+		if s.Type == "" {
+			// No type means any value.
+			return nil, nil
+		}
+		v := &schema.Dict{MinLen: int(s.MinProps)}
+		if s.MaxProps != nil {
+			v.MaxLen = int(*s.MaxProps)
+		}
+		if s.AdditionalProperties.Schema != nil {
+			var err error
+			if v.Values, err = im.importField(s.AdditionalProperties.Schema); err != nil {
+				return nil, fmt.Errorf("additionalProperties: %v", err)
+			}
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", s.Type)
+	}
+}
+
+// importOneOf converts a oneOf schema with a discriminator into a
+// OneOfSchema. The discriminator value of each variant is taken from the
+// discriminator mapping, the enum of the discriminator property or the name of
+// the referenced component, in that order.
+func (im *Importer) importOneOf(s *openapi3.Schema) (schema.FieldValidator, error) {
+	v := &schema.OneOfSchema{
+		Discriminator: s.Discriminator.PropertyName,
+		Mapping:       map[string]*schema.Schema{},
+	}
+	keys := map[string]string{}
+	for key, ref := range s.Discriminator.Mapping {
+		keys[ref] = key
+	}
+	for i, ref := range s.OneOf {
+		sub, err := im.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		key, found := keys[ref.Ref]
+		if !found {
+			if p, ok := sub.Properties[v.Discriminator]; ok && p.Value != nil && len(p.Value.Enum) == 1 {
+				key, _ = p.Value.Enum[0].(string)
+			}
+		}
+		if key == "" {
+			key = refName(ref)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("oneOf #%d: can't determine the discriminator value", i)
+		}
+		ss, err := im.ImportSchema(sub)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+		v.Mapping[key] = &ss
+	}
+	return v, nil
+}
+
+func (im *Importer) importValidators(refs openapi3.SchemaRefs) ([]schema.FieldValidator, error) {
+	vs := make([]schema.FieldValidator, 0, len(refs))
+	for _, ref := range refs {
+		f, err := im.importField(ref)
+		if err != nil {
+			return nil, err
+		}
+		if f.Validator != nil {
+			vs = append(vs, f.Validator)
+		}
+	}
+	return vs, nil
+}
+
+// importString maps a string schema to a validator, using the format to pick
+// a specialized validator when one exists.
+func importString(s *openapi3.Schema) schema.FieldValidator {
+	switch s.Format {
+	case "date-time":
+		return &schema.Time{}
+	case "date":
+		return &schema.Time{TimeLayouts: []string{dateLayout}}
+	case "ipv4", "ipv6", "ip":
+		return &schema.IP{}
+	case "uri", "url":
+		return &schema.URL{}
+	case "email":
+		return &schema.Email{}
+	case "uuid":
+		return &schema.UUID{}
+	case "decimal":
+		return &schema.Decimal{}
+	case "duration":
+		return &schema.Duration{}
+	case "password":
+		v := &schema.Password{MinLen: int(s.MinLength)}
+		if s.MaxLength != nil {
+			v.MaxLen = int(*s.MaxLength)
+		}
+		return v
+	}
+	v := &schema.String{
+		Regexp: s.Pattern,
+		MinLen: int(s.MinLength),
+	}
+	if s.MaxLength != nil {
+		v.MaxLen = int(*s.MaxLength)
+	}
+	for _, e := range s.Enum {
+		if str, ok := e.(string); ok {
+			v.Allowed = append(v.Allowed, str)
+		}
+	}
+	return v
+}
+
+// importBoundaries returns the boundaries of a number schema, or nil if it has
+// none.
+func importBoundaries(s *openapi3.Schema) *schema.Boundaries {
+	if s.Min == nil && s.Max == nil {
+		return nil
+	}
+	b := &schema.Boundaries{Min: math.Inf(-1), Max: math.Inf(1)}
+	if s.Min != nil {
+		b.Min = *s.Min
+	}
+	if s.Max != nil {
+		b.Max = *s.Max
+	}
+	return b
+}
+
+// resolve returns the schema pointed by ref, looking it up in the document
+// components if the reference was not resolved by a loader.
+func (im *Importer) resolve(ref *openapi3.SchemaRef) (*openapi3.Schema, error) {
+	seen := map[*openapi3.SchemaRef]bool{}
+	for {
+		if ref == nil {
+			return nil, errors.New("missing schema")
+		}
+		if ref.Value != nil {
+			return ref.Value, nil
+		}
+		name := refName(ref)
+		if name == "" {
+			return nil, fmt.Errorf("unsupported reference: %s", ref.Ref)
+		}
+		seen[ref] = true
+		target, found := im.Doc.Components.Schemas[name]
+		if !found || seen[target] {
+			return nil, fmt.Errorf("unresolved reference: %s", ref.Ref)
+		}
+		ref = target
+	}
+}
+
+// refName returns the name of the component schema referenced by ref, or an
+// empty string if ref does not reference a component schema.
+func refName(ref *openapi3.SchemaRef) string {
+	if ref == nil || !strings.HasPrefix(ref.Ref, schemaRefPrefix) {
+		return ""
+	}
+	name := strings.TrimPrefix(ref.Ref, schemaRefPrefix)
+	if strings.Contains(name, "/") {
+		return ""
+	}
+	return name
+}
+
+// importedResource holds the information gathered from the document paths
+// about a resource.
+type importedResource struct {
+	name      string
+	parents   []string
+	component string
+	modes     map[resource.Mode]bool
+}
+
+func (r *importedResource) path() string {
+	return strings.Join(append(append([]string{}, r.parents...), r.name), ".")
+}
+
+// ImportIndex creates a resource index from the paths of the document. Each
+// collection path (i.e. /users or /users/{userId}/posts) is bound as a
+// resource, using the component schema of its operations and the modes
+// matching the operations present. Sub-resources are bound using the field
+// named after the singular form of their parent (i.e. user) as parent field.
+// The storer func is called for each resource to get its storage handler.
+func (im *Importer) ImportIndex(storer func(path string) resource.Storer) (resource.Index, error) {
+	resources := map[string]*importedResource{}
+	for path, item := range im.Doc.Paths {
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		isItem := len(segments)%2 == 0
+		var collection []string
+		for i, seg := range segments {
+			isParam := strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+			if (i%2 == 1) != isParam {
+				collection = nil
+				break
+			}
+			if !isParam {
+				collection = append(collection, seg)
+			}
+		}
+		if len(collection) == 0 {
+			// Not a REST Layer style path.
+			continue
+		}
+		key := strings.Join(collection, ".")
+		r := resources[key]
+		if r == nil {
+			r = &importedResource{
+				name:    collection[len(collection)-1],
+				parents: collection[:len(collection)-1],
+				modes:   map[resource.Mode]bool{},
+			}
+			resources[key] = r
+		}
+		for method, op := range item.Operations() {
+			mode, component := im.operationInfo(isItem, method, op)
+			if mode < 0 {
+				continue
+			}
+			r.modes[mode] = true
+			// Prefer the item schema returned by read operations, which
+			// includes read-only fields.
+			if component != "" && (r.component == "" || method == "GET") {
+				r.component = component
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(resources))
+	for key, r := range resources {
+		if r.component == "" {
+			return nil, fmt.Errorf("%s: cannot find resource schema", key)
+		}
+		im.References[r.component] = r.path()
+		keys = append(keys, key)
+	}
+	// Sorting by path ensures parents are bound before their children.
+	sort.Strings(keys)
+
+	index := resource.NewIndex()
+	for _, key := range keys {
+		r := resources[key]
+		s, err := im.resolve(&openapi3.SchemaRef{Ref: schemaRefPrefix + r.component})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+		// The resource own schema must not be turned into a reference.
+		delete(im.References, r.component)
+		sch, err := im.ImportSchema(s)
+		im.References[r.component] = r.path()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+		if _, found := sch.Fields["id"]; !found {
+			sch.Fields["id"] = schema.IDField
+		}
+		conf := resource.Conf{}
+		for _, m := range resource.ReadWrite {
+			if r.modes[m] {
+				conf.AllowedModes = append(conf.AllowedModes, m)
+			}
+		}
+		if len(r.parents) == 0 {
+			index.Bind(r.name, sch, storer(r.path()), conf)
+			continue
+		}
+		parent, found := index.GetResource(strings.Join(r.parents, "."), nil)
+		if !found {
+			return nil, fmt.Errorf("%s: parent resource not found", key)
+		}
+		field := inflection.Singular(parent.Name())
+		if _, found := sch.Fields[field]; !found {
+			return nil, fmt.Errorf("%s: parent field `%s' not found", key, field)
+		}
+		parent.Bind(r.name, field, sch, storer(r.path()), conf)
+	}
+	return index, nil
+}
+
+// operationInfo returns the mode matching the operation and the name of the
+// component schema it exchanges, if any. A negative mode is returned for
+// operations not matching any mode.
+func (im *Importer) operationInfo(isItem bool, method string, op *openapi3.Operation) (resource.Mode, string) {
+	var mode resource.Mode
+	switch {
+	case !isItem && method == "GET":
+		mode = resource.List
+	case !isItem && method == "POST":
+		mode = resource.Create
+	case !isItem && method == "DELETE":
+		mode = resource.Clear
+	case isItem && method == "GET":
+		mode = resource.Read
+	case isItem && method == "PUT":
+		mode = resource.Replace
+	case isItem && method == "PATCH":
+		mode = resource.Update
+	case isItem && method == "DELETE":
+		mode = resource.Delete
+	default:
+		return -1, ""
+	}
+	if method == "GET" {
+		for _, code := range []string{"200", "201"} {
+			if resp := op.Responses[code]; resp != nil && resp.Value != nil {
+				if name := im.mediaTypeComponent(resp.Value.Content); name != "" {
+					return mode, name
+				}
+			}
+		}
+		return mode, ""
+	}
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		return mode, im.mediaTypeComponent(op.RequestBody.Value.Content)
+	}
+	return mode, ""
+}
+
+// mediaTypeComponent returns the name of the component schema referenced by
+// the JSON media type of content, looking through arrays.
+func (im *Importer) mediaTypeComponent(content openapi3.Content) string {
+	mt := content.Get("application/json")
+	if mt == nil || mt.Schema == nil {
+		return ""
+	}
+	ref := mt.Schema
+	if ref.Value != nil && ref.Value.Type == "array" && ref.Value.Items != nil {
+		ref = ref.Value.Items
+	}
+	name := refName(ref)
+	// Write operations may exchange a source schema without read-only fields
+	// (see FillOpenapiFromIndex), use the full schema instead when available.
+	if full := strings.TrimSuffix(name, "Source"); full != name {
+		if _, found := im.Doc.Components.Schemas[full]; found {
+			return full
+		}
+	}
+	return name
+}
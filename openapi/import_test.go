@@ -0,0 +1,248 @@
+package openapi
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/resource/testing/mem"
+	"github.com/entropyinf/rest-layer/rest"
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+const petstore = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: "1.0"
+paths:
+  /owners:
+    get:
+      responses:
+        "200":
+          description: owners
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Owner"
+  /owners/{ownerId}/pets:
+    get:
+      responses:
+        "200":
+          description: pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+      responses:
+        "201":
+          description: created
+  /owners/{ownerId}/pets/{petId}:
+    get:
+      responses:
+        "200":
+          description: pet
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+    delete:
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    Owner:
+      type: object
+      properties:
+        id:
+          type: string
+          readOnly: true
+        name:
+          type: string
+          minLength: 1
+          maxLength: 50
+        website:
+          type: string
+          format: uri
+    Pet:
+      type: object
+      required: [name, kind]
+      properties:
+        id:
+          type: string
+        owner:
+          $ref: "#/components/schemas/Owner"
+        name:
+          type: string
+          pattern: "^[A-Z]"
+        kind:
+          type: string
+          enum: [cat, dog]
+        age:
+          type: integer
+          minimum: 0
+        weight:
+          type: number
+          maximum: 100
+        born:
+          type: string
+          format: date-time
+        ip:
+          type: string
+          format: ipv4
+        tags:
+          type: array
+          maxItems: 3
+          items:
+            type: string
+        attributes:
+          type: object
+          additionalProperties:
+            type: boolean
+        collar:
+          type: object
+          properties:
+            color:
+              type: string
+`
+
+func TestImporter(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(petstore))
+	if !assert.NoError(t, err) {
+		return
+	}
+	index, err := NewImporter(doc).ImportIndex(func(path string) resource.Storer {
+		return mem.NewHandler()
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	owners, found := index.GetResource("owners", nil)
+	if !assert.True(t, found) {
+		return
+	}
+	assert.Equal(t, []resource.Mode{resource.List}, owners.Conf().AllowedModes)
+	assert.Equal(t, &schema.URL{}, owners.Schema().Fields["website"].Validator)
+	assert.True(t, owners.Schema().Fields["id"].ReadOnly)
+
+	pets, found := index.GetResource("owners.pets", nil)
+	if !assert.True(t, found) {
+		return
+	}
+	assert.Equal(t, []resource.Mode{resource.Create, resource.Read, resource.Delete, resource.List}, pets.Conf().AllowedModes)
+	assert.Equal(t, "owner", pets.ParentField())
+	fields := pets.Schema().Fields
+	assert.Equal(t, &schema.Reference{Path: "owners"}, fields["owner"].Validator)
+	assert.True(t, fields["name"].Required)
+	assert.Equal(t, &schema.String{Regexp: "^[A-Z]"}, fields["name"].Validator)
+	assert.Equal(t, &schema.String{Allowed: []string{"cat", "dog"}}, fields["kind"].Validator)
+	assert.Equal(t, &schema.Integer{Boundaries: &schema.Boundaries{Min: 0, Max: math.Inf(1)}}, fields["age"].Validator)
+	assert.Equal(t, &schema.Float{Boundaries: &schema.Boundaries{Min: math.Inf(-1), Max: 100}}, fields["weight"].Validator)
+	assert.Equal(t, &schema.Time{}, fields["born"].Validator)
+	assert.Equal(t, &schema.IP{}, fields["ip"].Validator)
+	assert.Equal(t, &schema.Array{MaxLen: 3, Values: schema.Field{Validator: &schema.String{}}}, fields["tags"].Validator)
+	assert.Equal(t, &schema.Dict{Values: schema.Field{Validator: &schema.Bool{}}}, fields["attributes"].Validator)
+	assert.IsType(t, &schema.Object{}, fields["collar"].Validator)
+
+	// The imported index can serve a mock API.
+	api, err := rest.NewHandler(index)
+	if !assert.NoError(t, err) {
+		return
+	}
+	owners.Insert(context.Background(), []*resource.Item{{ID: "o1", ETag: "x", Payload: map[string]interface{}{"id": "o1", "name": "John"}}})
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/owners/o1/pets", strings.NewReader(`{"id": "p1", "name": "Rex", "kind": "dog", "age": 3}`))
+	api.ServeHTTP(w, r)
+	assert.Equal(t, 201, w.Code, w.Body.String())
+}
+
+func TestImporterRoundTrip(t *testing.T) {
+	doc := &openapi3.T{
+		Info:  &openapi3.Info{Title: "test", Version: "1"},
+		Paths: openapi3.Paths{},
+	}
+	FillOpenapiFromIndex(newTestIndex(), doc)
+	index, err := NewImporter(doc).ImportIndex(func(path string) resource.Storer {
+		return mem.NewHandler()
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	users, found := index.GetResource("users", nil)
+	if !assert.True(t, found) {
+		return
+	}
+	assert.Equal(t, resource.ReadWrite, users.Conf().AllowedModes)
+	fields := users.Schema().Fields
+	assert.True(t, fields["name"].Required)
+	assert.Equal(t, &schema.String{MaxLen: 150}, fields["name"].Validator)
+	assert.Equal(t, &schema.String{Allowed: []string{"admin", "user"}}, fields["role"].Validator)
+	assert.Equal(t, &schema.Integer{Boundaries: &schema.Boundaries{Min: 0, Max: 150}}, fields["age"].Validator)
+	assert.Equal(t, &schema.Email{}, fields["email"].Validator)
+	assert.Equal(t, &schema.UUID{}, fields["token"].Validator)
+	assert.Equal(t, &schema.Decimal{}, fields["balance"].Validator)
+	assert.Equal(t, &schema.Duration{}, fields["timeout"].Validator)
+	if payment, ok := fields["payment"].Validator.(*schema.OneOfSchema); assert.True(t, ok) {
+		assert.Equal(t, "type", payment.Discriminator)
+		assert.Equal(t, []string{"bank", "card"}, payment.Keys())
+	}
+}
+
+func TestImporterRecursiveReference(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(`
+openapi: 3.0.3
+info:
+  title: Tree
+  version: "1.0"
+paths: {}
+components:
+  schemas:
+    Node:
+      type: object
+      properties:
+        day:
+          type: string
+          format: date
+        children:
+          type: array
+          items:
+            $ref: "#/components/schemas/Node"
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = NewImporter(doc).ImportSchemas()
+	assert.EqualError(t, err, "Node: children: items: recursive reference: #/components/schemas/Node")
+
+	// Recursive references to a resource are imported as references.
+	im := NewImporter(doc)
+	im.References["Node"] = "nodes"
+	schemas, err := im.ImportSchemas()
+	if !assert.NoError(t, err) {
+		return
+	}
+	fields := schemas["Node"].Fields
+	assert.Equal(t, &schema.Array{Values: schema.Field{Validator: &schema.Reference{Path: "nodes"}}}, fields["children"].Validator)
+	day := fields["day"].Validator.(*schema.Time)
+	assert.NoError(t, day.Compile(nil))
+	_, err = day.Validate("2024-01-02")
+	assert.NoError(t, err)
+}
@@ -7,7 +7,7 @@ import (
 
 func FillOpenapiFromIndex(index resource.Index, doc *openapi3.T) {
 	doc.OpenAPI = "3.0.3"
-	doc.Components = staticComponents
+	doc.Components = newComponents()
 	for _, rsc := range index.GetResources() {
 		addResource(doc, []*resource.Resource{}, rsc)
 	}
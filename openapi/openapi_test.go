@@ -0,0 +1,155 @@
+package openapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/resource/testing/mem"
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIndex() resource.Index {
+	index := resource.NewIndex()
+	index.Bind("users", schema.Schema{
+		Fields: schema.Fields{
+			"id": schema.IDField,
+			"name": {
+				Required:   true,
+				Filterable: true,
+				Sortable:   true,
+				Validator:  &schema.String{MaxLen: 150},
+			},
+			"role": {
+				Filterable: true,
+				Validator:  &schema.String{Allowed: []string{"admin", "user"}},
+			},
+			"age": {
+				Sortable:  true,
+				Validator: &schema.Integer{Boundaries: &schema.Boundaries{Min: 0, Max: 150}},
+			},
+			"tags": {
+				Validator: &schema.Array{MinLen: 1, MaxLen: 5, Values: schema.Field{Validator: &schema.String{}}},
+			},
+			"labels": {
+				Validator: &schema.Dict{
+					KeysValidator: &schema.String{Regexp: "^[a-z]+$"},
+					Values:        schema.Field{Validator: &schema.String{}},
+				},
+			},
+			"email":    {Validator: &schema.Email{}},
+			"token":    {Validator: &schema.UUID{Version: 4}},
+			"balance":  {Validator: &schema.Decimal{Scale: 2}},
+			"timeout":  {Validator: &schema.Duration{}},
+			"location": {Validator: &schema.GeoPoint{}},
+			"status":   {Validator: &schema.EnumMap{Values: map[string]interface{}{"active": 1, "disabled": 0}}},
+			"initials": {
+				Validator: &schema.String{},
+				Compute: func(ctx context.Context, doc map[string]interface{}) (interface{}, error) {
+					return nil, nil
+				},
+			},
+			"payment": {
+				Validator: &schema.OneOfSchema{
+					Discriminator: "type",
+					Mapping: map[string]*schema.Schema{
+						"card": {Fields: schema.Fields{"type": {Validator: &schema.String{}}, "last4": {Validator: &schema.String{}}}},
+						"bank": {Fields: schema.Fields{"type": {Validator: &schema.String{}}, "iban": {Validator: &schema.String{}}}},
+					},
+				},
+			},
+			"meta": {
+				Schema: &schema.Schema{
+					Fields: schema.Fields{
+						"score": {Filterable: true, Validator: &schema.Float{}},
+					},
+				},
+				Validator: &schema.Object{Schema: &schema.Schema{
+					Fields: schema.Fields{
+						"score": {Filterable: true, Validator: &schema.Float{}},
+					},
+				}},
+			},
+		},
+	}, mem.NewHandler(), resource.Conf{AllowedModes: resource.ReadWrite})
+	index.Bind("logs", schema.Schema{
+		Fields: schema.Fields{"id": {ReadOnly: true, Validator: &schema.String{}}},
+	}, mem.NewHandler(), resource.Conf{AllowedModes: resource.ReadWrite})
+	return index
+}
+
+func findParameter(op *openapi3.Operation, name string) *openapi3.Parameter {
+	for _, p := range op.Parameters {
+		if p.Value != nil && p.Value.Name == name {
+			return p.Value
+		}
+	}
+	return nil
+}
+
+func TestFillOpenapiFromIndex(t *testing.T) {
+	doc := &openapi3.T{
+		Info:  &openapi3.Info{Title: "test", Version: "1"},
+		Paths: openapi3.Paths{},
+	}
+	FillOpenapiFromIndex(newTestIndex(), doc)
+	assert.NoError(t, openapi3.NewLoader().ResolveRefsIn(doc, nil))
+	assert.NoError(t, doc.Validate(context.Background()))
+
+	list := doc.Paths.Find("/users").Get
+	filter := findParameter(list, "filter")
+	if assert.NotNil(t, filter) {
+		assert.Equal(t, []string{"id", "meta.score", "name", "role"}, filter.Extensions["x-filterable-fields"])
+	}
+	sort := findParameter(list, "sort")
+	if assert.NotNil(t, sort) {
+		assert.Equal(t, []interface{}{"age", "-age", "id", "-id", "name", "-name"}, sort.Schema.Value.Items.Value.Enum)
+	}
+	assert.NotNil(t, findParameter(doc.Paths.Find("/users").Delete, "filter"))
+	if aggregate := doc.Paths.Find("/users/_aggregate"); assert.NotNil(t, aggregate) {
+		assert.True(t, findParameter(aggregate.Get, "aggregate").Required)
+		assert.NotNil(t, findParameter(aggregate.Get, "filter"))
+	}
+
+	// Resources without filterable or sortable fields don't expose them.
+	list = doc.Paths.Find("/logs").Get
+	assert.Nil(t, findParameter(list, "filter"))
+	assert.Nil(t, findParameter(list, "sort"))
+	assert.Nil(t, doc.Paths.Find("/logs/_aggregate"))
+
+	user := doc.Components.Schemas["user"].Value
+	assert.Equal(t, []string{"id", "name"}, user.Required)
+	assert.Equal(t, []string{"name"}, doc.Components.Schemas["userSource"].Value.Required)
+	assert.Equal(t, []interface{}{"admin", "user"}, user.Properties["role"].Value.Enum)
+	assert.Equal(t, 150.0, *user.Properties["age"].Value.Max)
+	assert.Equal(t, 0.0, *user.Properties["age"].Value.Min)
+	assert.Equal(t, uint64(1), user.Properties["tags"].Value.MinItems)
+	assert.Equal(t, uint64(5), *user.Properties["tags"].Value.MaxItems)
+	labels := user.Properties["labels"].Value
+	assert.Equal(t, "string", labels.AdditionalProperties.Schema.Value.Type)
+	assert.Equal(t, "^[a-z]+$", labels.Extensions["x-propertyNames"].(*openapi3.Schema).Pattern)
+
+	assert.Equal(t, "email", user.Properties["email"].Value.Format)
+	assert.Equal(t, "uuid", user.Properties["token"].Value.Format)
+	assert.Equal(t, "decimal", user.Properties["balance"].Value.Format)
+	assert.Equal(t, "duration", user.Properties["timeout"].Value.Format)
+	location := user.Properties["location"].Value
+	assert.Equal(t, "object", location.Type)
+	assert.Equal(t, []string{"type", "coordinates"}, location.Required)
+	assert.Equal(t, []interface{}{"active", "disabled"}, user.Properties["status"].Value.Enum)
+	assert.Equal(t, "string", user.Properties["initials"].Value.Type)
+	assert.True(t, user.Properties["initials"].Value.ReadOnly)
+	assert.NotContains(t, doc.Components.Schemas["userSource"].Value.Properties, "initials")
+	payment := user.Properties["payment"].Value
+	if assert.Len(t, payment.OneOf, 2) && assert.NotNil(t, payment.Discriminator) {
+		assert.Equal(t, "type", payment.Discriminator.PropertyName)
+		assert.Equal(t, []interface{}{"bank"}, payment.OneOf[0].Value.Properties["type"].Value.Enum)
+	}
+
+	errSchema := doc.Components.Schemas["Error"].Value
+	assert.Equal(t, []string{"code", "message"}, errSchema.Required)
+	assert.Contains(t, errSchema.Properties, "issues")
+	assert.Equal(t, "#/components/schemas/Error", doc.Components.Responses["ValidationError"].Value.Content["application/json"].Schema.Ref)
+}
@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// queryableFields returns the sorted list of fields of s, using the dotted
+// notation for sub-schemas fields, for which pred returns true.
+func queryableFields(s schema.Schema, pred func(f schema.Field) bool) []string {
+	names := []string{}
+	collectFields(&names, "", s, pred)
+	sort.Strings(names)
+	return names
+}
+
+func collectFields(names *[]string, prefix string, s schema.Schema, pred func(f schema.Field) bool) {
+	for name, f := range s.Fields {
+		if f.Hidden {
+			continue
+		}
+		if pred(f) {
+			*names = append(*names, prefix+name)
+		}
+		sub := f.Schema
+		if o, ok := f.Validator.(*schema.Object); ok && o.Schema != nil {
+			sub = o.Schema
+		}
+		if sub != nil {
+			collectFields(names, prefix+name+".", *sub, pred)
+		}
+	}
+}
+
+// quoteFields returns names as a markdown list of code spans.
+func quoteFields(names []string) string {
+	q := make([]string, len(names))
+	for i, n := range names {
+		q[i] = "`" + n + "`"
+	}
+	return strings.Join(q, ", ")
+}
+
+// filterParameter returns the filter parameter documenting the filterable
+// fields of s, or nil if none of its fields are filterable.
+func filterParameter(s schema.Schema) *openapi3.ParameterRef {
+	fields := queryableFields(s, func(f schema.Field) bool { return f.Filterable })
+	if len(fields) == 0 {
+		return nil
+	}
+	return &openapi3.ParameterRef{
+		Value: &openapi3.Parameter{
+			Extensions:  map[string]interface{}{"x-filterable-fields": fields},
+			Description: fmt.Sprintf("%s Filterable fields: %s.", filterDescription, quoteFields(fields)),
+			Name:        "filter",
+			In:          "query",
+			Schema: &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type: "string",
+				},
+			},
+		},
+	}
+}
+
+// aggregateParameter returns the aggregate parameter documenting the fields of
+// s usable in aggregations, or nil if none of its fields are filterable.
+func aggregateParameter(s schema.Schema) *openapi3.ParameterRef {
+	fields := queryableFields(s, func(f schema.Field) bool { return f.Filterable })
+	if len(fields) == 0 {
+		return nil
+	}
+	return &openapi3.ParameterRef{
+		Value: &openapi3.Parameter{
+			Description: fmt.Sprintf("%s Aggregatable fields: %s.", aggregateDescription, quoteFields(fields)),
+			Name:        "aggregate",
+			In:          "query",
+			Required:    true,
+			Schema: &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type: "string",
+				},
+			},
+		},
+	}
+}
+
+// sortParameter returns the sort parameter enumerating the sortable fields of
+// s, in ascending and descending (prefixed with -) order, or nil if none of
+// its fields are sortable.
+func sortParameter(s schema.Schema) *openapi3.ParameterRef {
+	fields := queryableFields(s, func(f schema.Field) bool { return f.Sortable })
+	if len(fields) == 0 {
+		return nil
+	}
+	enum := make([]interface{}, 0, len(fields)*2)
+	for _, f := range fields {
+		enum = append(enum, f, "-"+f)
+	}
+	return &openapi3.ParameterRef{
+		Value: &openapi3.Parameter{
+			Description: fmt.Sprintf("%s Sortable fields: %s.", sortDescription, quoteFields(fields)),
+			Name:        "sort",
+			In:          "query",
+			Style:       "form",
+			Explode:     openapi3.BoolPtr(false),
+			Schema: &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type: "array",
+					Items: &openapi3.SchemaRef{
+						Value: &openapi3.Schema{
+							Type: "string",
+							Enum: enum,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// appendParameters appends non nil parameters to params.
+func appendParameters(params []*openapi3.ParameterRef, add ...*openapi3.ParameterRef) []*openapi3.ParameterRef {
+	for _, p := range add {
+		if p != nil {
+			params = append(params, p)
+		}
+	}
+	return params
+}
@@ -64,7 +64,7 @@ func addResource(doc *openapi3.T, prevRscList []*resource.Resource, rsc *resourc
 	}
 	tagName := cases.Title(language.English).String(topResource.Name())
 
-	if tag := doc.Tags.Get(tagName); tag == nil {
+	if tag := doc.Tags.Get(tagName); tag == nil && len(rsc.Conf().AllowedModes) > 0 {
 		doc.Tags = append(doc.Tags, &openapi3.Tag{
 			Name:        tagName,
 			Description: rsc.Schema().Description,
@@ -77,15 +77,17 @@ func addResource(doc *openapi3.T, prevRscList []*resource.Resource, rsc *resourc
 			Summary:     "List" + resourceName,
 			OperationID: "List" + resourceName,
 			Parameters: append(
-				[]*openapi3.ParameterRef{
-					{Ref: "#/components/parameters/filter"},
-					{Ref: "#/components/parameters/fields"},
-					{Ref: "#/components/parameters/limit"},
-					{Ref: "#/components/parameters/page"},
-					{Ref: "#/components/parameters/skip"},
-					{Ref: "#/components/parameters/total"},
-					{Ref: "#/components/parameters/sort"},
-				},
+				appendParameters(
+					[]*openapi3.ParameterRef{
+						{Ref: "#/components/parameters/fields"},
+						{Ref: "#/components/parameters/limit"},
+						{Ref: "#/components/parameters/page"},
+						{Ref: "#/components/parameters/skip"},
+						{Ref: "#/components/parameters/total"},
+					},
+					filterParameter(rsc.Schema()),
+					sortParameter(rsc.Schema()),
+				),
 				params...,
 			),
 			Responses: map[string]*openapi3.ResponseRef{
@@ -118,6 +120,44 @@ func addResource(doc *openapi3.T, prevRscList []*resource.Resource, rsc *resourc
 		doc.AddOperation(path, "GET", op)
 	}
 
+	if aggregate := aggregateParameter(rsc.Schema()); aggregate != nil && rsc.Conf().IsModeAllowed(resource.List) {
+		op := &openapi3.Operation{
+			Tags:        []string{tagName},
+			Summary:     "Aggregate" + resourceName,
+			OperationID: "Aggregate" + resourceName,
+			Parameters: append(
+				appendParameters(
+					[]*openapi3.ParameterRef{aggregate},
+					filterParameter(rsc.Schema()),
+				),
+				params...,
+			),
+			Responses: map[string]*openapi3.ResponseRef{
+				"200": {
+					Value: &openapi3.Response{
+						Description: StringPtr(fmt.Sprintf("Aggregation of %s", schemaNamePlural)),
+						Content: map[string]*openapi3.MediaType{
+							"application/json": {
+								Schema: &openapi3.SchemaRef{
+									Value: &openapi3.Schema{
+										Type: "array",
+										Items: &openapi3.SchemaRef{
+											Value: &openapi3.Schema{Type: "object"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				"default": {
+					Ref: "#/components/responses/Error",
+				},
+			},
+		}
+		doc.AddOperation(path+"/_aggregate", "GET", op)
+	}
+
 	if rsc.Conf().IsModeAllowed(resource.Create) {
 		op := &openapi3.Operation{
 			Tags:        []string{tagName},
@@ -172,9 +212,7 @@ func addResource(doc *openapi3.T, prevRscList []*resource.Resource, rsc *resourc
 			Summary:     "Clear" + cases.Title(language.English).String(rsc.Name()) + operationSufix,
 			OperationID: "Clear" + cases.Title(language.English).String(rsc.Name()) + operationSufix,
 			Parameters: append(
-				[]*openapi3.ParameterRef{
-					{Ref: "#/components/parameters/filter"},
-				},
+				appendParameters(nil, filterParameter(rsc.Schema())),
 				params...,
 			),
 			Responses: map[string]*openapi3.ResponseRef{
@@ -247,13 +285,7 @@ func addResource(doc *openapi3.T, prevRscList []*resource.Resource, rsc *resourc
 			Parameters: append(
 				[]*openapi3.ParameterRef{
 					{Ref: fmt.Sprintf("#/components/parameters/%s", schemaIdParameter)},
-					{Value: &openapi3.Parameter{
-						Name: "If-Match",
-						In:   "header",
-						Schema: &openapi3.SchemaRef{
-							Ref: "#/components/headers/If-Match",
-						},
-					}},
+					{Ref: "#/components/parameters/If-Match"},
 				},
 				params...,
 			),
@@ -309,13 +341,7 @@ func addResource(doc *openapi3.T, prevRscList []*resource.Resource, rsc *resourc
 			Parameters: append(
 				[]*openapi3.ParameterRef{
 					{Ref: fmt.Sprintf("#/components/parameters/%s", schemaIdParameter)},
-					{Value: &openapi3.Parameter{
-						Name: "If-Match",
-						In:   "header",
-						Schema: &openapi3.SchemaRef{
-							Ref: "#/components/headers/If-Match",
-						},
-					}},
+					{Ref: "#/components/parameters/If-Match"},
 				},
 				params...,
 			),
@@ -376,13 +402,7 @@ func addResource(doc *openapi3.T, prevRscList []*resource.Resource, rsc *resourc
 			Parameters: append(
 				[]*openapi3.ParameterRef{
 					{Ref: fmt.Sprintf("#/components/parameters/%s", schemaIdParameter)},
-					{Value: &openapi3.Parameter{
-						Name: "If-Match",
-						In:   "header",
-						Schema: &openapi3.SchemaRef{
-							Ref: "#/components/headers/If-Match",
-						},
-					}},
+					{Ref: "#/components/parameters/If-Match"},
 				},
 				params...,
 			),
@@ -1,10 +1,13 @@
 package openapi
 
 import (
-	"github.com/getkin/kin-openapi/openapi3"
-	"github.com/entropyinf/rest-layer/schema"
 	"log"
+	"math"
 	"reflect"
+	"sort"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
 func generateSchema(s schema.Schema, hideReadOnly bool) *openapi3.Schema {
@@ -12,18 +15,46 @@ func generateSchema(s schema.Schema, hideReadOnly bool) *openapi3.Schema {
 		Type:        "object",
 		Description: s.Description,
 		Properties:  map[string]*openapi3.SchemaRef{},
+		MinProps:    uint64(s.MinLen),
+	}
+	if s.MaxLen > 0 {
+		ret.MaxProps = openapi3.Uint64Ptr(uint64(s.MaxLen))
 	}
 
 	for fieldName, field := range s.Fields {
+		if field.Compute != nil {
+			// Computed fields are read-only and never part of request bodies.
+			if !hideReadOnly {
+				ret.Properties[fieldName] = &openapi3.SchemaRef{Value: generateSchemaFromComputedField(field)}
+			}
+			continue
+		}
 		if !(hideReadOnly && field.ReadOnly) {
 			ret.Properties[fieldName] = &openapi3.SchemaRef{}
 			ret.Properties[fieldName].Value = generateSchemaFromField(field, hideReadOnly)
+			if field.Required {
+				ret.Required = append(ret.Required, fieldName)
+			}
 		}
 	}
+	sort.Strings(ret.Required)
 
 	return ret
 }
 
+// generateSchemaFromComputedField generates the read-only schema of a computed
+// field. The type is only known when a validator is set.
+func generateSchemaFromComputedField(field schema.Field) *openapi3.Schema {
+	var ret *openapi3.Schema
+	if field.Validator != nil {
+		ret = generateSchemaFromField(field, false)
+	} else {
+		ret = &openapi3.Schema{Description: field.Description}
+	}
+	ret.ReadOnly = true
+	return ret
+}
+
 func generateSchemaFromField(field schema.Field, hideReadOnly bool) *openapi3.Schema {
 	if field.Validator == nil {
 		log.Fatalln("validator required")
@@ -45,13 +76,32 @@ func generateSchemaFromField(field schema.Field, hideReadOnly bool) *openapi3.Sc
 	case *schema.Integer:
 		return generateSchemaFromFieldInteger(field)
 	case *schema.Dict:
-		return generateSchemaFieldDict(field)
+		return generateSchemaFieldDict(field, hideReadOnly)
 	case *schema.Password:
 		return generateSchemaFromFieldPassword(field)
 	case *schema.URL:
 		return generateSchemaFromFieldURL(field)
 	case *schema.Float:
 		return generateSchemaFromFieldFloat(field)
+	case *schema.Email:
+		return generateSchemaFromFieldFormat(field, "email")
+	case *schema.UUID:
+		return generateSchemaFromFieldFormat(field, "uuid")
+	case *schema.Decimal:
+		return generateSchemaFromFieldFormat(field, "decimal")
+	case *schema.Duration:
+		return generateSchemaFromFieldFormat(field, "duration")
+	case *schema.GeoPoint:
+		return generateSchemaFromFieldGeoPoint(field)
+	case *schema.EnumMap:
+		return generateSchemaFromFieldEnumMap(field)
+	case *schema.Encrypted:
+		// Describe the decrypted value, as seen by clients.
+		field.Validator = t.Validator
+		return generateSchemaFromField(field, hideReadOnly)
+
+	case *schema.OneOfSchema:
+		return generateSchemaFromFieldOneOf(field, hideReadOnly)
 
 	case *schema.Object:
 		if t.Schema != nil {
@@ -64,32 +114,98 @@ func generateSchemaFromField(field schema.Field, hideReadOnly bool) *openapi3.Sc
 	return nil
 }
 
+// setBoundaries sets the minimum and maximum of ret from b, ignoring infinite
+// and NaN bounds.
+func setBoundaries(ret *openapi3.Schema, b *schema.Boundaries) {
+	if b == nil {
+		return
+	}
+	if !math.IsNaN(b.Min) && !math.IsInf(b.Min, -1) {
+		ret.Min = openapi3.Float64Ptr(b.Min)
+	}
+	if !math.IsNaN(b.Max) && !math.IsInf(b.Max, 1) {
+		ret.Max = openapi3.Float64Ptr(b.Max)
+	}
+}
+
 func generateSchemaFromFieldFloat(f schema.Field) *openapi3.Schema {
-	return &openapi3.Schema{
+	v := f.Validator.(*schema.Float)
+	ret := &openapi3.Schema{
 		Type:        "number",
 		Description: f.Description,
 		ReadOnly:    f.ReadOnly,
 		Default:     f.Default,
 	}
+	for _, a := range v.Allowed {
+		ret.Enum = append(ret.Enum, a)
+	}
+	setBoundaries(ret, v.Boundaries)
+
+	return ret
 }
 
-func generateSchemaFieldDict(f schema.Field) *openapi3.Schema {
-	return &openapi3.Schema{
+func generateSchemaFieldDict(f schema.Field, hideReadOnly bool) *openapi3.Schema {
+	v := f.Validator.(*schema.Dict)
+	ret := &openapi3.Schema{
 		Type:        "object",
 		Description: f.Description,
 		ReadOnly:    f.ReadOnly,
 		Default:     f.Default,
+		MinProps:    uint64(v.MinLen),
 	}
+	if v.MaxLen > 0 {
+		ret.MaxProps = openapi3.Uint64Ptr(uint64(v.MaxLen))
+	}
+
+	values := &openapi3.SchemaRef{Value: &openapi3.Schema{}}
+	if v.Values.Validator != nil {
+		values.Value = generateSchemaFromField(v.Values, hideReadOnly)
+	}
+
+	kv, _ := v.KeysValidator.(*schema.String)
+	if kv != nil && len(kv.Allowed) > 0 {
+		// A closed set of keys is best described as a set of optional
+		// properties.
+		ret.Properties = map[string]*openapi3.SchemaRef{}
+		for _, key := range kv.Allowed {
+			ret.Properties[key] = values
+		}
+		ret.AdditionalProperties = openapi3.AdditionalProperties{Has: openapi3.BoolPtr(false)}
+		return ret
+	}
+	ret.AdditionalProperties = openapi3.AdditionalProperties{Schema: values}
+	if kv != nil && (kv.Regexp != "" || kv.MinLen > 0 || kv.MaxLen > 0) {
+		// OpenAPI 3.0 has no propertyNames keyword, use an extension to
+		// document keys constraints.
+		keys := &openapi3.Schema{
+			Type:      "string",
+			MinLength: uint64(kv.MinLen),
+			Pattern:   kv.Regexp,
+		}
+		if kv.MaxLen > 0 {
+			keys.MaxLength = openapi3.Uint64Ptr(uint64(kv.MaxLen))
+		}
+		ret.Extensions = map[string]interface{}{"x-propertyNames": keys}
+	}
+
+	return ret
 }
 
 func generateSchemaFromFieldInteger(f schema.Field) *openapi3.Schema {
-	return &openapi3.Schema{
+	v := f.Validator.(*schema.Integer)
+	ret := &openapi3.Schema{
 		Type:        "integer",
 		Description: f.Description,
 		ReadOnly:    f.ReadOnly,
 		Default:     f.Default,
 		Example:     f.Default,
 	}
+	for _, a := range v.Allowed {
+		ret.Enum = append(ret.Enum, a)
+	}
+	setBoundaries(ret, v.Boundaries)
+
+	return ret
 }
 
 func generateSchemaFromFieldNil(f schema.Field) *openapi3.Schema {
@@ -124,6 +240,9 @@ func generateSchemaFromFieldTime(f schema.Field) *openapi3.Schema {
 		ReadOnly:    f.ReadOnly,
 		Default:     f.Default,
 	}
+	if v := f.Validator.(*schema.Time); len(v.TimeLayouts) == 1 && v.TimeLayouts[0] == dateLayout {
+		ret.Format = "date"
+	}
 
 	return ret
 
@@ -134,20 +253,17 @@ func generateSchemaFromFieldString(f schema.Field) *openapi3.Schema {
 	ret := &openapi3.Schema{
 		Type:        "string",
 		Description: f.Description,
+		ReadOnly:    f.ReadOnly,
+		Default:     f.Default,
 		MinLength:   uint64(v.MinLen),
 		Pattern:     v.Regexp,
 		Example:     f.Default,
-		Enum:        []any{},
 	}
 	if v.MaxLen > 0 {
 		ret.MaxLength = openapi3.Uint64Ptr(uint64(v.MaxLen))
 	}
-
-	allowed := v.Allowed
-	if allowed != nil && len(allowed) > 0 {
-		for _, a := range allowed {
-			ret.Enum = append(ret.Enum, a)
-		}
+	for _, a := range v.Allowed {
+		ret.Enum = append(ret.Enum, a)
 	}
 
 	return ret
@@ -156,8 +272,11 @@ func generateSchemaFromFieldString(f schema.Field) *openapi3.Schema {
 func generateSchemaFromFieldPassword(f schema.Field) *openapi3.Schema {
 	v := f.Validator.(*schema.Password)
 	ret := &openapi3.Schema{
-		Type:      "string",
-		MinLength: uint64(v.MinLen),
+		Type:        "string",
+		Format:      "password",
+		Description: f.Description,
+		WriteOnly:   true,
+		MinLength:   uint64(v.MinLen),
 	}
 	if v.MaxLen > 0 {
 		ret.MaxLength = openapi3.Uint64Ptr(uint64(v.MaxLen))
@@ -169,7 +288,9 @@ func generateSchemaFromFieldPassword(f schema.Field) *openapi3.Schema {
 func generateSchemaFromFieldURL(f schema.Field) *openapi3.Schema {
 	ret := &openapi3.Schema{
 		Type:        "string",
+		Format:      "uri",
 		Description: f.Description,
+		ReadOnly:    f.ReadOnly,
 	}
 
 	return ret
@@ -177,11 +298,17 @@ func generateSchemaFromFieldURL(f schema.Field) *openapi3.Schema {
 
 func generateSchemaFromFieldArray(f schema.Field, hideReadOnly bool) *openapi3.Schema {
 	v := f.Validator.(*schema.Array)
+	items := &openapi3.Schema{}
+	if v.Values.Validator != nil {
+		items = generateSchemaFromField(v.Values, hideReadOnly)
+	}
 	ret := &openapi3.Schema{
-		Type:     "array",
-		MinItems: uint64(v.MinLen),
+		Type:        "array",
+		Description: f.Description,
+		ReadOnly:    f.ReadOnly,
+		MinItems:    uint64(v.MinLen),
 		Items: &openapi3.SchemaRef{
-			Value: generateSchemaFromField(v.Values, hideReadOnly),
+			Value: items,
 		},
 	}
 	if v.MaxLen > 0 {
@@ -201,3 +328,78 @@ func generateSchemaFromFieldReference(f schema.Field) *openapi3.Schema {
 
 	return ret
 }
+
+// generateSchemaFromFieldFormat describes validators of string values in a
+// given format.
+func generateSchemaFromFieldFormat(f schema.Field, format string) *openapi3.Schema {
+	ret := &openapi3.Schema{
+		Type:        "string",
+		Format:      format,
+		Description: f.Description,
+		ReadOnly:    f.ReadOnly,
+		Default:     f.Default,
+	}
+
+	return ret
+}
+
+// generateSchemaFromFieldGeoPoint describes the GeoJSON point a geo point is
+// stored as.
+func generateSchemaFromFieldGeoPoint(f schema.Field) *openapi3.Schema {
+	ret := &openapi3.Schema{
+		Type:        "object",
+		Description: f.Description,
+		ReadOnly:    f.ReadOnly,
+		Required:    []string{"type", "coordinates"},
+		Properties: map[string]*openapi3.SchemaRef{
+			"type": {Value: &openapi3.Schema{
+				Type: "string",
+				Enum: []interface{}{"Point"},
+			}},
+			"coordinates": {Value: &openapi3.Schema{
+				Type:        "array",
+				Description: "[longitude, latitude]",
+				Items:       &openapi3.SchemaRef{Value: &openapi3.Schema{Type: "number"}},
+				MinItems:    2,
+				MaxItems:    openapi3.Uint64Ptr(2),
+			}},
+		},
+	}
+
+	return ret
+}
+
+func generateSchemaFromFieldEnumMap(f schema.Field) *openapi3.Schema {
+	v := f.Validator.(*schema.EnumMap)
+	ret := &openapi3.Schema{
+		Type:        "string",
+		Description: f.Description,
+		ReadOnly:    f.ReadOnly,
+		Default:     f.Default,
+	}
+	for _, key := range v.Keys() {
+		ret.Enum = append(ret.Enum, key)
+	}
+
+	return ret
+}
+
+func generateSchemaFromFieldOneOf(f schema.Field, hideReadOnly bool) *openapi3.Schema {
+	v := f.Validator.(*schema.OneOfSchema)
+	ret := &openapi3.Schema{
+		Description:   f.Description,
+		ReadOnly:      f.ReadOnly,
+		Default:       f.Default,
+		Discriminator: &openapi3.Discriminator{PropertyName: v.Discriminator},
+	}
+	for _, key := range v.Keys() {
+		s := generateSchema(*v.Mapping[key], hideReadOnly)
+		// Restrict the discriminator of each variant to its value so the
+		// variant can be identified without a mapping.
+		if p, found := s.Properties[v.Discriminator]; found {
+			p.Value.Enum = []interface{}{key}
+		}
+		ret.OneOf = append(ret.OneOf, &openapi3.SchemaRef{Value: s})
+	}
+	return ret
+}
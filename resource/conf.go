@@ -1,5 +1,7 @@
 package resource
 
+import "github.com/entropyinf/rest-layer/schema/query"
+
 // Conf defines the configuration for a given resource.
 type Conf struct {
 	// AllowedModes is the list of Mode allowed for the resource.
@@ -21,6 +23,12 @@ type Conf struct {
 	//
 	// TotalDenied prevents the user from requesting the total.
 	ForceTotal ForceTotalMode
+	// QueryLimits bounds the complexity of the queries accepted on the
+	// resource, like the depth of the filter or the number of sub-resources
+	// embedded by the projection. When MaxLimit is set, it is also used as the
+	// page size of list requests with no limit and no default page size. By
+	// default, no limit is enforced.
+	QueryLimits query.Limits
 }
 
 // ForceTotalMode defines Conf.ForceTotal modes.
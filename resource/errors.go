@@ -18,4 +18,7 @@ var (
 	// ErrNoStorage is returned when not storage handler has been set on the
 	// resource.
 	ErrNoStorage = errors.New("No Storage Defined")
+	// ErrTooManyItems is returned when an aggregation computed in memory
+	// matches more items than allowed by Conf.QueryLimits.
+	ErrTooManyItems = errors.New("Too Many Items")
 )
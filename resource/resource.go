@@ -67,6 +67,19 @@ func (v validatorFallback) GetField(name string) *schema.Field {
 	return v.fallback.GetField(name)
 }
 
+// DefaultComputedFields implements the schema.ComputedFieldLister interface.
+func (v validatorFallback) DefaultComputedFields() []string {
+	if l, ok := v.Validator.(schema.ComputedFieldLister); ok {
+		return l.DefaultComputedFields()
+	}
+	return nil
+}
+
+// ValidateContext implements the schema.ContextValidator interface.
+func (v validatorFallback) ValidateContext(ctx context.Context, changes map[string]interface{}, base map[string]interface{}) (map[string]interface{}, map[string][]interface{}) {
+	return schema.ValidateContext(ctx, v.Validator, changes, base)
+}
+
 // newResource creates a new resource with provided spec, handler and config.
 func newResource(name string, s schema.Schema, h Storer, c Conf) *Resource {
 	return &Resource{
@@ -245,7 +258,9 @@ func (r *Resource) Get(ctx context.Context, id interface{}) (item *Item, err err
 		}(time.Now())
 	}
 	if err = r.hooks.onGet(ctx, id); err == nil {
-		item, err = r.storage.Get(ctx, id)
+		if item, err = r.storage.Get(ctx, id); err == nil {
+			err = r.upgrade([]*Item{item})
+		}
 	}
 	r.hooks.onGot(ctx, &item, &err)
 	return
@@ -273,7 +288,9 @@ func (r *Resource) MultiGet(ctx context.Context, ids []interface{}) (items []*It
 	}
 	// Perform the storage request if none of the pre-hook returned an err.
 	if err == nil {
-		items, err = r.storage.MultiGet(ctx, ids)
+		if items, err = r.storage.MultiGet(ctx, ids); err == nil {
+			err = r.upgrade(items)
+		}
 	}
 	var errOverwrite error
 	for i := range ids {
@@ -304,6 +321,8 @@ func (r *Resource) MultiGet(ctx context.Context, ids []interface{}) (items []*It
 }
 
 // Find calls the Find method on the storage handler with the corresponding pre/post hooks.
+// If the query predicate can never match, an empty list is returned without
+// calling the storage handler.
 func (r *Resource) Find(ctx context.Context, q *query.Query) (list *ItemList, err error) {
 	return r.find(ctx, q, false)
 }
@@ -332,7 +351,18 @@ func (r *Resource) find(ctx context.Context, q *query.Query, forceTotal bool) (l
 		}(time.Now())
 	}
 	if err = r.hooks.onFind(ctx, q); err == nil {
-		list, err = r.storage.Find(ctx, q)
+		if _, ok := q.Predicate.Normalize(); !ok {
+			// The predicate can't match, don't bother the storage.
+			list = &ItemList{Total: 0, Items: []*Item{}}
+			if q.Window != nil {
+				list.Offset, list.Limit = q.Window.Offset, q.Window.Limit
+			}
+		} else {
+			list, err = r.storage.Find(ctx, q)
+		}
+		if err == nil {
+			err = r.upgrade(list.Items)
+		}
 		if err == nil && list.Total == -1 && forceTotal {
 			// Send a query with no window so the storage won't be tempted to
 			// count within the window.
@@ -343,6 +373,58 @@ func (r *Resource) find(ctx context.Context, q *query.Query, forceTotal bool) (l
 	return
 }
 
+// Aggregate groups the items matching the predicate of the query and computes
+// the accumulators of the prepared aggregation a on each group. The sort and
+// window of the query are ignored. Find hooks are called with the query, but
+// found hooks are not as items are not returned.
+//
+// If the storage handler does not implement the Aggregator interface, the
+// items matching the predicate are fetched and aggregated in memory. If they
+// are more than allowed by the MaxAggregatedItems query limit of the resource,
+// ErrTooManyItems is returned.
+func (r *Resource) Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) (groups []map[string]interface{}, err error) {
+	if LoggerLevel <= LogLevelDebug && Logger != nil {
+		defer func(t time.Time) {
+			Logger(ctx, LogLevelDebug, fmt.Sprintf("%s.Aggregate(...)", r.path), map[string]interface{}{
+				"duration": time.Since(t),
+				"groups":   len(groups),
+				"error":    err,
+			})
+		}(time.Now())
+	}
+	if err = r.hooks.onFind(ctx, q); err != nil {
+		return nil, err
+	}
+	if _, ok := q.Predicate.Normalize(); !ok {
+		return a.Eval(nil), nil
+	}
+	groups, err = r.storage.Aggregate(ctx, q, a)
+	if err != ErrNotImplemented {
+		return groups, err
+	}
+	var window *query.Window
+	max := r.conf.QueryLimits.AggregatedItems()
+	if max >= 0 {
+		// Fetch one more item to detect the overflow.
+		window = &query.Window{Limit: max + 1}
+	}
+	list, err := r.storage.Find(ctx, &query.Query{Predicate: q.Predicate, Window: window})
+	if err != nil {
+		return nil, err
+	}
+	if max >= 0 && len(list.Items) > max {
+		return nil, ErrTooManyItems
+	}
+	if err = r.upgrade(list.Items); err != nil {
+		return nil, err
+	}
+	payloads := make([]map[string]interface{}, len(list.Items))
+	for i, item := range list.Items {
+		payloads[i] = item.Payload
+	}
+	return a.Eval(payloads), nil
+}
+
 // Insert implements Storer interface.
 func (r *Resource) Insert(ctx context.Context, items []*Item) (err error) {
 	if LoggerLevel <= LogLevelDebug && Logger != nil {
@@ -355,7 +437,9 @@ func (r *Resource) Insert(ctx context.Context, items []*Item) (err error) {
 	}
 	if err = r.hooks.onInsert(ctx, items); err == nil {
 		if err = recalcEtag(items); err == nil {
+			unstamp := r.stamp(items)
 			err = r.storage.Insert(ctx, items)
+			unstamp()
 		}
 	}
 	r.hooks.onInserted(ctx, items, &err)
@@ -392,7 +476,9 @@ func (r *Resource) Update(ctx context.Context, item *Item, original *Item) (err
 	}
 	if err = r.hooks.onUpdate(ctx, item, original); err == nil {
 		if err = recalcEtag([]*Item{item}); err == nil {
+			unstamp := r.stamp([]*Item{item})
 			err = r.storage.Update(ctx, item, original)
+			unstamp()
 		}
 	}
 	r.hooks.onUpdated(ctx, item, original, &err)
@@ -416,7 +502,8 @@ func (r *Resource) Delete(ctx context.Context, item *Item) (err error) {
 	return
 }
 
-// Clear implements Storer interface.
+// Clear implements Storer interface. If the query predicate can never match,
+// the storage handler is not called.
 func (r *Resource) Clear(ctx context.Context, q *query.Query) (deleted int, err error) {
 	if LoggerLevel <= LogLevelDebug && Logger != nil {
 		defer func(t time.Time) {
@@ -428,7 +515,9 @@ func (r *Resource) Clear(ctx context.Context, q *query.Query) (deleted int, err
 		}(time.Now())
 	}
 	if err = r.hooks.onClear(ctx, q); err == nil {
-		deleted, err = r.storage.Clear(ctx, q)
+		if _, ok := q.Predicate.Normalize(); ok {
+			deleted, err = r.storage.Clear(ctx, q)
+		}
 	}
 	r.hooks.onCleared(ctx, q, &deleted, &err)
 	return
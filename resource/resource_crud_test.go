@@ -365,6 +365,38 @@ func TestResourceFind(t *testing.T) {
 	assert.True(t, postHook)
 }
 
+func TestResourceFindNeverMatch(t *testing.T) {
+	var postHook, handler bool
+	i := NewIndex()
+	s := newTestMStorer()
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		handler = true
+		return &ItemList{Items: []*Item{{ID: 1}}}, nil
+	}
+	s.clear = func(ctx context.Context, q *query.Query) (int, error) {
+		handler = true
+		return 1, nil
+	}
+	r := i.Bind("foo", schema.Schema{}, s, DefaultConf)
+	r.Use(FoundEventHandlerFunc(func(ctx context.Context, q *query.Query, list **ItemList, err *error) {
+		postHook = true
+		assert.Equal(t, &ItemList{Total: 0, Limit: 2, Items: []*Item{}}, *list)
+	}))
+	ctx := context.Background()
+	q := &query.Query{
+		Predicate: query.MustParsePredicate(`{foo: {$gt: 2}, foo: {$lt: 1}}`),
+		Window:    &query.Window{Limit: 2},
+	}
+	list, err := r.FindWithTotal(ctx, q)
+	assert.NoError(t, err)
+	assert.Equal(t, &ItemList{Total: 0, Limit: 2, Items: []*Item{}}, list)
+	deleted, err := r.Clear(ctx, q)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+	assert.True(t, postHook)
+	assert.False(t, handler)
+}
+
 func TestResourceMultiFindPostHookOverwrite(t *testing.T) {
 	i := NewIndex()
 	s := newTestMStorer()
@@ -929,3 +961,74 @@ func TestResourceClearPostHookError(t *testing.T) {
 	assert.True(t, handler)
 	assert.True(t, postHook)
 }
+
+type testAggregatorStorer struct {
+	*testStorer
+	aggregate func(ctx context.Context, q *query.Query, a *query.Aggregation) ([]map[string]interface{}, error)
+}
+
+func (s testAggregatorStorer) Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) ([]map[string]interface{}, error) {
+	return s.aggregate(ctx, q, a)
+}
+
+func TestResourceAggregate(t *testing.T) {
+	var preHook bool
+	i := NewIndex()
+	s := newTestStorer()
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		assert.Equal(t, &query.Window{Limit: query.DefaultMaxAggregatedItems + 1}, q.Window)
+		return &ItemList{Total: -1, Items: []*Item{
+			{ID: "1", Payload: map[string]interface{}{"id": "1", "foo": "a"}},
+			{ID: "2", Payload: map[string]interface{}{"id": "2", "foo": "b"}},
+			{ID: "3", Payload: map[string]interface{}{"id": "3", "foo": "a"}},
+		}}, nil
+	}
+	r := i.Bind("foo", schema.Schema{Fields: schema.Fields{"foo": {Filterable: true}}}, s, DefaultConf)
+	r.Use(FindEventHandlerFunc(func(ctx context.Context, q *query.Query) error {
+		preHook = true
+		return nil
+	}))
+	a := query.MustParseAggregation("foo,count()")
+	assert.NoError(t, a.Prepare(r.Validator()))
+	groups, err := r.Aggregate(context.Background(), &query.Query{Window: &query.Window{Limit: 1}}, a)
+	assert.NoError(t, err)
+	assert.True(t, preHook)
+	assert.Equal(t, []map[string]interface{}{
+		{"foo": "a", "count": 2},
+		{"foo": "b", "count": 1},
+	}, groups)
+}
+
+func TestResourceAggregateTooManyItems(t *testing.T) {
+	i := NewIndex()
+	s := newTestStorer()
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		assert.Equal(t, &query.Window{Limit: 3}, q.Window)
+		return &ItemList{Total: -1, Items: []*Item{
+			{ID: "1", Payload: map[string]interface{}{"id": "1"}},
+			{ID: "2", Payload: map[string]interface{}{"id": "2"}},
+			{ID: "3", Payload: map[string]interface{}{"id": "3"}},
+		}}, nil
+	}
+	conf := DefaultConf
+	conf.QueryLimits.MaxAggregatedItems = 2
+	r := i.Bind("foo", schema.Schema{}, s, conf)
+	_, err := r.Aggregate(context.Background(), &query.Query{}, query.MustParseAggregation("count()"))
+	assert.Equal(t, ErrTooManyItems, err)
+}
+
+func TestResourceAggregateStorer(t *testing.T) {
+	i := NewIndex()
+	s := testAggregatorStorer{testStorer: newTestStorer()}
+	s.aggregate = func(ctx context.Context, q *query.Query, a *query.Aggregation) ([]map[string]interface{}, error) {
+		return []map[string]interface{}{{"count": 42}}, nil
+	}
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		t.Error("unexpected find")
+		return nil, nil
+	}
+	r := i.Bind("foo", schema.Schema{}, s, DefaultConf)
+	groups, err := r.Aggregate(context.Background(), &query.Query{}, query.MustParseAggregation("count()"))
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"count": 42}}, groups)
+}
@@ -107,10 +107,22 @@ type Counter interface {
 	Count(ctx context.Context, q *query.Query) (int, error)
 }
 
+// Aggregator is an optional interface a Storer can implement to compute
+// aggregations in the storage engine. When not implemented, or when
+// ErrNotImplemented is returned, REST Layer aggregates the items returned by
+// Find in memory.
+type Aggregator interface {
+	// Aggregate groups the items matching the query predicate and computes the
+	// accumulators of the aggregation on each group, as described by
+	// query.Aggregation.Eval. The sort and window of the query must be ignored.
+	Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) ([]map[string]interface{}, error)
+}
+
 type storageHandler interface {
 	Storer
 	MultiGetter
 	Counter
+	Aggregator
 	Get(ctx context.Context, id interface{}) (item *Item, err error)
 }
 
@@ -272,3 +284,16 @@ func (s storageWrapper) Count(ctx context.Context, q *query.Query) (total int, e
 	}
 	return -1, ErrNotImplemented
 }
+
+func (s storageWrapper) Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) (groups []map[string]interface{}, err error) {
+	if s.Storer == nil {
+		return nil, ErrNoStorage
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if ag, ok := s.Storer.(Aggregator); ok {
+		return ag.Aggregate(ctx, q, a)
+	}
+	return nil, ErrNotImplemented
+}
@@ -0,0 +1,211 @@
+package mem
+
+import (
+	"math"
+
+	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/schema/query"
+)
+
+// searchIndex is an inverted index of the words of string fields, used to
+// find the items matching a $search and to rank them by relevance.
+type searchIndex struct {
+	fields []string
+
+	// docs holds the number of occurrences of each word of each field, per
+	// item id.
+	docs map[interface{}]map[string]map[string]int
+
+	// postings holds the ids of the items containing each word, per field.
+	postings map[string]map[string]map[interface{}]struct{}
+}
+
+func newSearchIndex(fields []string) *searchIndex {
+	idx := &searchIndex{
+		fields:   fields,
+		docs:     map[interface{}]map[string]map[string]int{},
+		postings: map[string]map[string]map[interface{}]struct{}{},
+	}
+	for _, field := range fields {
+		idx.postings[field] = map[string]map[interface{}]struct{}{}
+	}
+	return idx
+}
+
+// covers tells if all the searched fields are indexed.
+func (idx *searchIndex) covers(searches []*query.Search) bool {
+	for _, e := range searches {
+		if _, found := idx.postings[e.Field]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+// add indexes the words of the item.
+func (idx *searchIndex) add(item *resource.Item) {
+	doc := map[string]map[string]int{}
+	for _, field := range idx.fields {
+		text, ok := item.GetField(field).(string)
+		if !ok {
+			continue
+		}
+		counts := map[string]int{}
+		for _, w := range query.Tokenize(text) {
+			counts[w]++
+			ids := idx.postings[field][w]
+			if ids == nil {
+				ids = map[interface{}]struct{}{}
+				idx.postings[field][w] = ids
+			}
+			ids[item.ID] = struct{}{}
+		}
+		doc[field] = counts
+	}
+	idx.docs[item.ID] = doc
+}
+
+// remove removes the words of the item from the index.
+func (idx *searchIndex) remove(id interface{}) {
+	for field, counts := range idx.docs[id] {
+		for w := range counts {
+			ids := idx.postings[field][w]
+			delete(ids, id)
+			if len(ids) == 0 {
+				delete(idx.postings[field], w)
+			}
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// lookup returns the ids of the items containing a word matching the term in
+// the field.
+func (idx *searchIndex) lookup(field string, t query.SearchTerm) map[interface{}]struct{} {
+	if !t.Prefix {
+		return idx.postings[field][t.Word]
+	}
+	ids := map[interface{}]struct{}{}
+	for w, wids := range idx.postings[field] {
+		if t.Match(w) {
+			for id := range wids {
+				ids[id] = struct{}{}
+			}
+		}
+	}
+	return ids
+}
+
+// candidates returns the ids of the items matching the indexed $search
+// expressions of the top level of the predicate. Nil is returned if the
+// predicate has no such expression, in which case all the items are
+// candidates.
+func (idx *searchIndex) candidates(p query.Predicate) map[interface{}]struct{} {
+	var candidates map[interface{}]struct{}
+	for _, exp := range p {
+		e, ok := exp.(*query.Search)
+		if !ok {
+			continue
+		}
+		if _, found := idx.postings[e.Field]; !found {
+			continue
+		}
+		for _, t := range e.Terms() {
+			ids := idx.lookup(e.Field, t)
+			if candidates == nil {
+				candidates = make(map[interface{}]struct{}, len(ids))
+				for id := range ids {
+					candidates[id] = struct{}{}
+				}
+				continue
+			}
+			for id := range candidates {
+				if _, found := ids[id]; !found {
+					delete(candidates, id)
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// scorer returns a function computing the TF-IDF score of an item for the
+// $search expressions. The term frequency is the number of occurrences of the
+// term in the field divided by the number of words of the field, and the
+// inverse document frequency is log(1 + N/df), N being the number of indexed
+// items and df the number of items containing the term.
+func (idx *searchIndex) scorer(searches []*query.Search) func(id interface{}) float64 {
+	type term struct {
+		field string
+		query.SearchTerm
+		idf float64
+	}
+	n := float64(len(idx.docs))
+	terms := []term{}
+	for _, e := range searches {
+		for _, t := range e.Terms() {
+			df := len(idx.lookup(e.Field, t))
+			if df == 0 {
+				continue
+			}
+			terms = append(terms, term{e.Field, t, math.Log(1 + n/float64(df))})
+		}
+	}
+	return func(id interface{}) float64 {
+		doc := idx.docs[id]
+		score := 0.0
+		for _, t := range terms {
+			tf, length := 0, 0
+			for w, c := range doc[t.field] {
+				length += c
+				if t.Match(w) {
+					tf += c
+				}
+			}
+			if tf > 0 {
+				score += float64(tf) / float64(length) * t.idf
+			}
+		}
+		return score
+	}
+}
+
+// searchExpressions returns the $search expressions of the predicate, except
+// the negated ones.
+func searchExpressions(exps []query.Expression) []*query.Search {
+	var searches []*query.Search
+	for _, exp := range exps {
+		switch t := exp.(type) {
+		case *query.Search:
+			searches = append(searches, t)
+		case *query.And:
+			searches = append(searches, searchExpressions(*t)...)
+		case *query.Or:
+			searches = append(searches, searchExpressions(*t)...)
+		}
+	}
+	return searches
+}
+
+// searchFields returns the fields of the $search expressions.
+func searchFields(searches []*query.Search) []string {
+	fields := []string{}
+	seen := map[string]bool{}
+	for _, e := range searches {
+		if !seen[e.Field] {
+			seen[e.Field] = true
+			fields = append(fields, e.Field)
+		}
+	}
+	return fields
+}
+
+// sortedByScore tells if the sort uses the relevance of a $search.
+func sortedByScore(s query.Sort) bool {
+	for _, field := range s {
+		if field.Name == query.ScoreField {
+			return true
+		}
+	}
+	return false
+}
@@ -1,16 +1,31 @@
 package mem
 
 import (
+	"math"
 	"time"
 
 	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/schema"
 	"github.com/entropyinf/rest-layer/schema/query"
+	"golang.org/x/text/collate"
 )
 
 // sortableItems is an item slice implementing sort.Interface
 type sortableItems struct {
-	sort  query.Sort
-	items []*resource.Item
+	sort      query.Sort
+	collators []*collate.Collator
+	items     []*resource.Item
+
+	// scores holds the relevance of the items to a $search, by id.
+	scores map[interface{}]float64
+}
+
+func newSortableItems(sort query.Sort, items []*resource.Item, scores map[interface{}]float64) sortableItems {
+	collators := make([]*collate.Collator, len(sort))
+	for i, field := range sort {
+		collators[i] = field.Collator()
+	}
+	return sortableItems{sort: sort, collators: collators, items: items, scores: scores}
 }
 
 func (s sortableItems) Len() int {
@@ -22,7 +37,18 @@ func (s sortableItems) Swap(i, j int) {
 }
 
 func (s sortableItems) Less(i, j int) bool {
-	for _, field := range s.sort {
+	for k, field := range s.sort {
+		if field.Name == query.ScoreField {
+			// The most relevant items first.
+			score1, score2 := s.scores[s.items[i].ID], s.scores[s.items[j].ID]
+			if field.Reversed {
+				score1, score2 = score2, score1
+			}
+			if score1 != score2 {
+				return score1 > score2
+			}
+			continue
+		}
 		var field1 interface{}
 		var field2 interface{}
 		if field.Reversed {
@@ -32,6 +58,15 @@ func (s sortableItems) Less(i, j int) bool {
 			field1 = s.items[i].GetField(field.Name)
 			field2 = s.items[j].GetField(field.Name)
 		}
+		if field.Near != nil {
+			// Geo points are sorted by distance, values which are not geo
+			// points last.
+			d1, d2 := distance(*field.Near, field1), distance(*field.Near, field2)
+			if d1 != d2 {
+				return d1 < d2
+			}
+			continue
+		}
 		if field1 == field2 {
 			continue
 		}
@@ -61,6 +96,12 @@ func (s sortableItems) Less(i, j int) bool {
 		case float64:
 			return t < field2.(float64)
 		case string:
+			if c := s.collators[k]; c != nil {
+				if r := c.CompareString(t, field2.(string)); r != 0 {
+					return r < 0
+				}
+				continue
+			}
 			return t < field2.(string)
 		case bool:
 			return t
@@ -70,3 +111,13 @@ func (s sortableItems) Less(i, j int) bool {
 	}
 	return false
 }
+
+// distance returns the distance in meters between p and the geo point value,
+// or +Inf if value is not a geo point.
+func distance(p query.GeoCoordinates, value interface{}) float64 {
+	lng, lat, ok := schema.GeoPointCoordinates(value)
+	if !ok {
+		return math.Inf(1)
+	}
+	return p.Distance(query.GeoCoordinates{Lng: lng, Lat: lat})
+}
@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"context"
+	"errors"
+
+	"github.com/entropyinf/rest-layer/schema/query"
+)
+
+// SchemaVersionField is the name of the payload field storing the version of
+// the schema an item has been written with. The field is only set in the
+// storage when the resource schema defines a version, and is removed from the
+// items returned by the resource.
+const SchemaVersionField = "_schema_version"
+
+// stamp sets the schema version on the items payload before they are stored.
+// The returned function removes it once the storage is done with the items.
+func (r *Resource) stamp(items []*Item) func() {
+	if r.schema.Version == 0 {
+		return func() {}
+	}
+	for _, item := range items {
+		if item != nil && item.Payload != nil {
+			item.Payload[SchemaVersionField] = r.schema.Version
+		}
+	}
+	return func() {
+		for _, item := range items {
+			if item != nil && item.Payload != nil {
+				delete(item.Payload, SchemaVersionField)
+			}
+		}
+	}
+}
+
+// upgrade removes the schema version from the items payload as read from the
+// storage, and upgrades the items written with a previous version of the
+// schema.
+func (r *Resource) upgrade(items []*Item) error {
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		v, stamped := item.Payload[SchemaVersionField]
+		version := schemaVersion(v)
+		if !stamped && (r.schema.Version == 0 || version == r.schema.Version) {
+			continue
+		}
+		// Copy the payload as the storage may reuse it.
+		payload := make(map[string]interface{}, len(item.Payload))
+		for k, v := range item.Payload {
+			payload[k] = v
+		}
+		delete(payload, SchemaVersionField)
+		if r.schema.Version > 0 && version != r.schema.Version {
+			var err error
+			if payload, err = r.schema.Upgrade(payload, version); err != nil {
+				return err
+			}
+		}
+		item.Payload = payload
+	}
+	return nil
+}
+
+// schemaVersion returns the version stored in the SchemaVersionField of a
+// payload, or 1 if the item has been stored before versioning was enabled.
+func schemaVersion(v interface{}) int {
+	switch v := v.(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 1
+}
+
+// Migrate upgrades all the items of the resource written with a previous
+// version of its schema and saves them back to the storage. Items are walked
+// by windows of batchSize items sorted by id. No hook is called on the saved
+// items.
+//
+// The number of items saved is returned, along with the first error
+// encountered.
+func Migrate(ctx context.Context, r *Resource, batchSize int) (migrated int, err error) {
+	if r.schema.Version == 0 {
+		return 0, errors.New("resource schema is not versioned")
+	}
+	if batchSize <= 0 {
+		return 0, errors.New("invalid batch size")
+	}
+	for offset := 0; ; offset += batchSize {
+		q := &query.Query{
+			Sort:   query.Sort{{Name: "id"}},
+			Window: &query.Window{Offset: offset, Limit: batchSize},
+		}
+		list, err := r.storage.Find(ctx, q)
+		if err != nil {
+			return migrated, err
+		}
+		for _, original := range list.Items {
+			if schemaVersion(original.Payload[SchemaVersionField]) >= r.schema.Version {
+				continue
+			}
+			item := *original
+			if err = r.upgrade([]*Item{&item}); err != nil {
+				return migrated, err
+			}
+			if err = recalcEtag([]*Item{&item}); err != nil {
+				return migrated, err
+			}
+			unstamp := r.stamp([]*Item{&item})
+			err = r.storage.Update(ctx, &item, original)
+			unstamp()
+			if err != nil {
+				return migrated, err
+			}
+			migrated++
+		}
+		if len(list.Items) < batchSize {
+			return migrated, nil
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/entropyinf/rest-layer/schema/query"
+	"github.com/stretchr/testify/assert"
+)
+
+// newVersionStorer returns a storer keeping items in memory in insertion order.
+func newVersionStorer(items *[]*Item) *testStorer {
+	s := newTestStorer()
+	s.find = func(ctx context.Context, q *query.Query) (*ItemList, error) {
+		list := &ItemList{Total: len(*items)}
+		start, end := 0, len(*items)
+		if q.Window != nil {
+			start = q.Window.Offset
+			if start > end {
+				start = end
+			}
+			if q.Window.Limit >= 0 && start+q.Window.Limit < end {
+				end = start + q.Window.Limit
+			}
+		}
+		for _, i := range (*items)[start:end] {
+			list.Items = append(list.Items, copyItem(i))
+		}
+		return list, nil
+	}
+	s.insert = func(ctx context.Context, in []*Item) error {
+		for _, i := range in {
+			*items = append(*items, copyItem(i))
+		}
+		return nil
+	}
+	s.update = func(ctx context.Context, item *Item, original *Item) error {
+		for n, i := range *items {
+			if i.ID == original.ID {
+				(*items)[n] = copyItem(item)
+			}
+		}
+		return nil
+	}
+	return s
+}
+
+func copyItem(i *Item) *Item {
+	c := *i
+	c.Payload = map[string]interface{}{}
+	for k, v := range i.Payload {
+		c.Payload[k] = v
+	}
+	return &c
+}
+
+func newVersionedResource(items *[]*Item) *Resource {
+	return newResource("foo", schema.Schema{
+		Fields:  schema.Fields{"id": {}, "name": {}},
+		Version: 2,
+		Upgrades: map[int]schema.UpgradeFunc{
+			1: func(doc map[string]interface{}) (map[string]interface{}, error) {
+				doc["name"] = doc["title"]
+				delete(doc, "title")
+				return doc, nil
+			},
+		},
+	}, newVersionStorer(items), DefaultConf)
+}
+
+func TestResourceSchemaVersion(t *testing.T) {
+	ctx := context.Background()
+	stored := []*Item{
+		{ID: "1", ETag: "a", Payload: map[string]interface{}{"id": "1", "title": "old"}},
+	}
+	r := newVersionedResource(&stored)
+
+	item := &Item{ID: "2", Payload: map[string]interface{}{"id": "2", "name": "new"}}
+	assert.NoError(t, r.Insert(ctx, []*Item{item}))
+	assert.Equal(t, map[string]interface{}{"id": "2", "name": "new"}, item.Payload)
+	assert.Equal(t, map[string]interface{}{"id": "2", "name": "new", SchemaVersionField: 2}, stored[1].Payload)
+
+	list, err := r.Find(ctx, &query.Query{})
+	assert.NoError(t, err)
+	if assert.Len(t, list.Items, 2) {
+		assert.Equal(t, map[string]interface{}{"id": "1", "name": "old"}, list.Items[0].Payload)
+		assert.Equal(t, "a", list.Items[0].ETag)
+		assert.Equal(t, map[string]interface{}{"id": "2", "name": "new"}, list.Items[1].Payload)
+	}
+	// Lazy upgrades are not saved.
+	assert.Equal(t, map[string]interface{}{"id": "1", "title": "old"}, stored[0].Payload)
+
+	// Items written with a newer version can't be read.
+	stored[0].Payload[SchemaVersionField] = 3
+	_, err = r.Find(ctx, &query.Query{})
+	assert.EqualError(t, err, "document version 3 is newer than schema version 2")
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	stored := []*Item{
+		{ID: "1", ETag: "a", Payload: map[string]interface{}{"id": "1", "title": "a"}},
+		{ID: "2", ETag: "b", Payload: map[string]interface{}{"id": "2", "name": "b", SchemaVersionField: 2}},
+		{ID: "3", ETag: "c", Payload: map[string]interface{}{"id": "3", "title": "c", SchemaVersionField: 1}},
+	}
+	r := newVersionedResource(&stored)
+
+	migrated, err := Migrate(ctx, r, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, migrated)
+	assert.Equal(t, map[string]interface{}{"id": "1", "name": "a", SchemaVersionField: 2}, stored[0].Payload)
+	assert.NotEqual(t, "a", stored[0].ETag)
+	assert.Equal(t, "b", stored[1].ETag)
+	assert.Equal(t, map[string]interface{}{"id": "3", "name": "c", SchemaVersionField: 2}, stored[2].Payload)
+
+	migrated, err = Migrate(ctx, r, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, migrated)
+
+	_, err = Migrate(ctx, newResource("bar", schema.Schema{}, newVersionStorer(&stored), DefaultConf), 10)
+	assert.EqualError(t, err, "resource schema is not versioned")
+}
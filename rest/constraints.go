@@ -0,0 +1,211 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/entropyinf/rest-layer/schema/query"
+)
+
+// constraints holds the storage dependent checks to perform on a document
+// before it is stored.
+type constraints struct {
+	// refs lists the references to check per referenced resource path.
+	refs map[string][]refConstraint
+	// uniques lists the fields flagged as unique.
+	uniques []uniqueConstraint
+}
+
+type refConstraint struct {
+	// key is the issue key the error is reported under.
+	key string
+	// items holds the positions of the reference in the arrays it is nested
+	// in, if any.
+	items []int
+	id    interface{}
+}
+
+// error returns the error to report when the reference is not valid.
+func (r refConstraint) error(err error) error {
+	for i := len(r.items) - 1; i >= 0; i-- {
+		err = schema.NewValidationError("invalid_item", map[string]interface{}{"index": r.items[i], "error": err})
+	}
+	return err
+}
+
+type uniqueConstraint struct {
+	field string
+	value interface{}
+}
+
+// checkConstraints verifies that doc satisfies the constraints requiring a
+// storage lookup: the existence of items referenced by schema.Reference
+// validators with CheckExistence set, and the uniqueness of fields flagged as
+// Unique. Only the top level fields present in changes are checked, so
+// unchanged fields don't fail or cost a lookup. References are fetched with a
+// single MultiGet per referenced resource. Failed constraints are returned as
+// issues using the same format as validation errors while storage errors are
+// returned as err.
+func checkConstraints(ctx context.Context, rsrc *resource.Resource, changes, doc map[string]interface{}) (issues map[string][]interface{}, err error) {
+	c := constraints{refs: map[string][]refConstraint{}}
+	fields := schema.Fields{}
+	for name, f := range rsrc.Schema().Fields {
+		if _, found := changes[name]; found {
+			fields[name] = f
+		}
+	}
+	c.collect(fields, doc, "")
+	if len(c.refs) == 0 && len(c.uniques) == 0 {
+		return nil, nil
+	}
+	issues = map[string][]interface{}{}
+	if err = c.checkRefs(ctx, issues); err != nil {
+		return nil, err
+	}
+	if err = c.checkUniques(ctx, rsrc, doc, issues); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// collect walks fields and the matching values of doc to gather the
+// constraints to check.
+func (c *constraints) collect(fields schema.Fields, doc map[string]interface{}, prefix string) {
+	// Walk fields in a stable order so issues are reported deterministically.
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, found := doc[name]
+		if !found || value == nil {
+			continue
+		}
+		f := fields[name]
+		key := prefix + name
+		if f.Unique {
+			c.uniques = append(c.uniques, uniqueConstraint{field: key, value: value})
+		}
+		if f.Schema != nil {
+			if sub, ok := value.(map[string]interface{}); ok {
+				c.collect(f.Schema.Fields, sub, key+".")
+			}
+			continue
+		}
+		c.collectValue(f.Validator, value, key, nil)
+	}
+}
+
+// collectValue gathers the references held by value as described by v.
+func (c *constraints) collectValue(v schema.FieldValidator, value interface{}, key string, items []int) {
+	switch v := v.(type) {
+	case *schema.Reference:
+		if v.CheckExistence {
+			c.refs[v.Path] = append(c.refs[v.Path], refConstraint{key: key, items: items, id: value})
+		}
+	case *schema.Object:
+		if sub, ok := value.(map[string]interface{}); ok && v.Schema != nil {
+			c.collect(v.Schema.Fields, sub, key+".")
+		}
+	case *schema.OneOfSchema:
+		if sub, ok := value.(map[string]interface{}); ok {
+			if s, err := v.Resolve(sub); err == nil {
+				c.collect(s.Fields, sub, key+".")
+			}
+		}
+	case *schema.Array:
+		if values, ok := value.([]interface{}); ok {
+			for i, item := range values {
+				c.collectValue(v.Values.Validator, item, key, append(items[:len(items):len(items)], i+1))
+			}
+		}
+	}
+}
+
+// checkRefs fetches the referenced items and reports the missing ones.
+func (c constraints) checkRefs(ctx context.Context, issues map[string][]interface{}) error {
+	if len(c.refs) == 0 {
+		return nil
+	}
+	index, ok := IndexFromContext(ctx)
+	if !ok {
+		return &Error{500, "Router not available in context", nil}
+	}
+	paths := make([]string, 0, len(c.refs))
+	for path := range c.refs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		refs := c.refs[path]
+		rsrc, found := index.GetResource(path, nil)
+		if !found {
+			return &Error{500, fmt.Sprintf("Invalid resource reference: %s", path), nil}
+		}
+		ids := make([]interface{}, 0, len(refs))
+		seen := map[string]bool{}
+		for _, ref := range refs {
+			if k := idKey(ref.id); !seen[k] {
+				seen[k] = true
+				ids = append(ids, ref.id)
+			}
+		}
+		items, err := rsrc.MultiGet(ctx, ids)
+		if err == resource.ErrNoStorage {
+			for _, ref := range refs {
+				issues[ref.key] = append(issues[ref.key], ref.error(schema.NewValidationError("no_storage", nil)))
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		existing := map[string]bool{}
+		for _, item := range items {
+			if item != nil {
+				existing[idKey(item.ID)] = true
+			}
+		}
+		for _, ref := range refs {
+			if !existing[idKey(ref.id)] {
+				issues[ref.key] = append(issues[ref.key], ref.error(schema.NewValidationError("not_found", nil)))
+			}
+		}
+	}
+	return nil
+}
+
+// idKey returns a string identifying id, usable as a map key whatever the type
+// of id (IDs may be maps or slices, which can't be hashed).
+func idKey(id interface{}) string {
+	return fmt.Sprintf("%T %#v", id, id)
+}
+
+// checkUniques looks for other items of rsrc sharing the value of unique
+// fields. The item being written, doc, is excluded. For sub-resources, the
+// lookup is scoped to the items of the same parent.
+func (c constraints) checkUniques(ctx context.Context, rsrc *resource.Resource, doc map[string]interface{}, issues map[string][]interface{}) error {
+	for _, u := range c.uniques {
+		q := &query.Query{
+			Predicate: query.Predicate{&query.Equal{Field: u.field, Value: u.value}},
+			Window:    &query.Window{Limit: 1},
+		}
+		if parent := rsrc.ParentField(); parent != "" {
+			q.Predicate = append(q.Predicate, &query.Equal{Field: parent, Value: doc[parent]})
+		}
+		if id := doc["id"]; id != nil {
+			q.Predicate = append(q.Predicate, &query.NotEqual{Field: "id", Value: id})
+		}
+		list, err := rsrc.Find(ctx, q)
+		if err != nil {
+			return err
+		}
+		if len(list.Items) > 0 {
+			issues[u.field] = append(issues[u.field], schema.NewValidationError("not_unique", nil))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package rest
+
+import "testing"
+
+func TestIDKey(t *testing.T) {
+	// Maps and slices can't be used as map keys, their key can.
+	seen := map[string]bool{}
+	for _, id := range []interface{}{"1", 1, 1.0, map[string]interface{}{"a": 1}, []interface{}{"a"}} {
+		k := idKey(id)
+		if seen[k] {
+			t.Errorf("idKey(%#v) = %q, already used", id, k)
+		}
+		seen[k] = true
+	}
+	if idKey(map[string]interface{}{"a": 1, "b": 2}) != idKey(map[string]interface{}{"b": 2, "a": 1}) {
+		t.Error("idKey of equal maps differ")
+	}
+}
@@ -66,6 +66,8 @@ func NewError(err error) *Error {
 		return ErrNotImplemented
 	case resource.ErrNoStorage:
 		return &Error{501, err.Error(), nil}
+	case resource.ErrTooManyItems:
+		return &Error{422, "Too many items to aggregate", nil}
 	case nil:
 		return nil
 	default:
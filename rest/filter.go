@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"net/url"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/entropyinf/rest-layer/schema/query"
+)
+
+// FilterParser parses the filter of a request from its query-string
+// parameters. The returned predicate is prepared by the caller.
+type FilterParser interface {
+	ParseFilter(params url.Values, validator schema.Validator) (query.Predicate, error)
+}
+
+// FilterParserFunc is an adapter to use a function as a FilterParser.
+type FilterParserFunc func(params url.Values, validator schema.Validator) (query.Predicate, error)
+
+// ParseFilter implements the FilterParser interface.
+func (f FilterParserFunc) ParseFilter(params url.Values, validator schema.Validator) (query.Predicate, error) {
+	return f(params, validator)
+}
+
+var (
+	// JSONFilter parses the MongoDB like JSON filter of the filter parameter
+	// (i.e.: filter={age: {$gte: 18}}). It is the default filter parser.
+	JSONFilter FilterParser = FilterParserFunc(parseJSONFilter)
+
+	// RSQLFilter parses the RSQL/FIQL filter of the filter parameter (i.e.:
+	// filter=age=ge=18;name==bob*). See query.ParseRSQL for the syntax.
+	RSQLFilter FilterParser = FilterParserFunc(parseRSQLFilter)
+
+	// FlatFilter parses the query-string parameters not reserved by REST Layer
+	// as constraints on the field they are named after (i.e.:
+	// ?age[gte]=18&status=active). See query.ParseFlatFilter for the syntax.
+	FlatFilter FilterParser = FilterParserFunc(parseFlatFilter)
+)
+
+// reservedParams are the query-string parameters which are not filters for
+// the FlatFilter parser.
+var reservedParams = map[string]bool{
+	"filter":    true,
+	"fields":    true,
+	"sort":      true,
+	"limit":     true,
+	"skip":      true,
+	"page":      true,
+	"total":     true,
+	"aggregate": true,
+}
+
+func parseJSONFilter(params url.Values, validator schema.Validator) (query.Predicate, error) {
+	// If several filter parameters are present, merge them using $and.
+	var p query.Predicate
+	for _, filter := range params["filter"] {
+		f, err := query.ParsePredicate(filter)
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, f...)
+	}
+	return p, nil
+}
+
+func parseRSQLFilter(params url.Values, validator schema.Validator) (query.Predicate, error) {
+	var p query.Predicate
+	for _, filter := range params["filter"] {
+		f, err := query.ParseRSQL(filter, validator)
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, f...)
+	}
+	return p, nil
+}
+
+func parseFlatFilter(params url.Values, validator schema.Validator) (query.Predicate, error) {
+	filters := url.Values{}
+	for key, values := range params {
+		if !reservedParams[key] {
+			filters[key] = values
+		}
+	}
+	return query.ParseFlatFilter(filters, validator)
+}
@@ -18,6 +18,9 @@ type Handler struct {
 	// FallbackHandlerFunc is called when REST layer doesn't find a route for
 	// the request. If not set, a 404 or 405 standard REST error is returned.
 	FallbackHandlerFunc func(ctx context.Context, w http.ResponseWriter, r *http.Request)
+	// FilterParser parses the filter of the requests. If not set, JSONFilter
+	// is used. Use RSQLFilter or FlatFilter for alternative syntaxes.
+	FilterParser FilterParser
 	// index stores the resource router.
 	index resource.Index
 }
@@ -60,6 +63,7 @@ func (h *Handler) ServeHTTPC(ctx context.Context, w http.ResponseWriter, r *http
 		return
 	}
 	defer route.Release()
+	route.FilterParser = h.FilterParser
 	// Store the route and the router in the context
 	ctx = contextWithRoute(ctx, route)
 	ctx = contextWithIndex(ctx, h.index)
@@ -94,6 +98,16 @@ func routeHandler(ctx context.Context, r *http.Request, route *RouteMatch) (stat
 		return http.StatusNotFound, nil, errResourceNotFound
 	}
 	conf := rsrc.Conf()
+	if route.Aggregate {
+		if route.Method != http.MethodGet || !conf.IsModeAllowed(resource.List) {
+			headers = http.Header{}
+			if conf.IsModeAllowed(resource.List) {
+				headers.Set("Allow", http.MethodGet)
+			}
+			return ErrInvalidMethod.Code, headers, ErrInvalidMethod
+		}
+		return listAggregate(ctx, r, route)
+	}
 	isItem := route.ResourceID() != nil
 	mh := getAllowedMethodHandler(isItem, route.Method, conf)
 	if mh == nil {
@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+)
+
+// listAggregate handles GET requests on the aggregation URL of a resource.
+func listAggregate(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
+	q, e := route.Query(ctx)
+	if e != nil {
+		return e.Code, nil, e
+	}
+	a, e := route.Aggregation()
+	if e != nil {
+		return e.Code, nil, e
+	}
+	rsrc := route.Resource()
+	if err := a.CheckReadPermission(ctx, rsrc.Validator()); err != nil {
+		e = &Error{403, "Forbidden", map[string][]interface{}{"aggregate": {err.Error()}}}
+		return e.Code, nil, e
+	}
+	groups, err := rsrc.Aggregate(ctx, q, a)
+	if err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	}
+	return 200, nil, groups
+}
@@ -0,0 +1,152 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/resource/testing/mem"
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+func TestGetListAggregate(t *testing.T) {
+	sharedInit := func() *requestTestVars {
+		s := mem.NewHandler()
+		s.Insert(context.Background(), []*resource.Item{
+			{ID: "1", Payload: map[string]interface{}{"id": "1", "country": "FR", "age": 30}},
+			{ID: "2", Payload: map[string]interface{}{"id": "2", "country": "US", "age": 20}},
+			{ID: "3", Payload: map[string]interface{}{"id": "3", "country": "FR", "age": 40}},
+			{ID: "4", Payload: map[string]interface{}{"id": "4", "country": "US", "age": 50}},
+		})
+		idx := resource.NewIndex()
+		idx.Bind("users", schema.Schema{
+			Fields: schema.Fields{
+				"id":      {},
+				"country": {Filterable: true},
+				"age":     {Filterable: true, Validator: &schema.Integer{}},
+				"name":    {},
+				"salary": {
+					Filterable:     true,
+					ReadPermission: func(ctx context.Context, doc map[string]interface{}) bool { return false },
+					Validator:      &schema.Integer{},
+				},
+				"secret": {Filterable: true, Hidden: true},
+			},
+		}, s, resource.Conf{AllowedModes: resource.ReadWrite})
+		return &requestTestVars{
+			Index:   idx,
+			Storers: map[string]resource.Storer{"users": s},
+		}
+	}
+
+	tests := map[string]requestTest{
+		"group": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_aggregate?aggregate="+url.QueryEscape("country,count(),avg(age),oldest:max(age)"), nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[
+				{"country": "FR", "count": 2, "avg_age": 35, "oldest": 40},
+				{"country": "US", "count": 2, "avg_age": 35, "oldest": 50}
+			]`,
+		},
+		"filter": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_aggregate?aggregate="+url.QueryEscape("count(),sum(age)")+"&filter="+url.QueryEscape(`{age:{$gt:25}}`), nil)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `[{"count": 3, "sum_age": 120}]`,
+		},
+		"aggregate:missing": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_aggregate", nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"aggregate": ["required"]
+				}
+			}`,
+		},
+		"aggregate:invalid": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_aggregate?aggregate="+url.QueryEscape("name,count()"), nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"aggregate": ["name: field is not filterable"]
+				}
+			}`,
+		},
+		"aggregate:hidden": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_aggregate?aggregate="+url.QueryEscape("secret,count()"), nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"aggregate": ["secret: hidden field"]
+				}
+			}`,
+		},
+		"aggregate:permission": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_aggregate?aggregate="+url.QueryEscape("max(salary)"), nil)
+			},
+			ResponseCode: http.StatusForbidden,
+			ResponseBody: `{
+				"code": 403,
+				"message": "Forbidden",
+				"issues": {
+					"aggregate": ["salary: permission denied"]
+				}
+			}`,
+		},
+		"aggregate:tooManyItems": {
+			Init: func() *requestTestVars {
+				v := sharedInit()
+				rsrc, _ := v.Index.GetResource("users", nil)
+				conf := rsrc.Conf()
+				conf.QueryLimits.MaxAggregatedItems = 3
+				idx := resource.NewIndex()
+				idx.Bind("users", rsrc.Schema(), v.Storers["users"], conf)
+				v.Index = idx
+				return v
+			},
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/users/_aggregate?aggregate=count()", nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{"code": 422, "message": "Too many items to aggregate"}`,
+		},
+		"method:invalid": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("DELETE", "/users/_aggregate?aggregate=count()", nil)
+			},
+			ResponseCode:   http.StatusMethodNotAllowed,
+			ResponseHeader: http.Header{"Allow": []string{"GET"}},
+			ResponseBody:   `{"code": 405, "message": "Invalid Method"}`,
+		},
+	}
+
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
@@ -8,7 +8,7 @@ import (
 
 // listDelete handles DELETE resquests on a resource URL.
 func listDelete(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
-	q, e := route.Query()
+	q, e := route.Query(ctx)
 	if e != nil {
 		return e.Code, nil, e
 	}
@@ -24,7 +24,7 @@ func listGet(ctx context.Context, r *http.Request, route *RouteMatch) (status in
 			return 422, nil, &Error{422, "Cannot use `total' parameter: denied by configuration", nil}
 		}
 	}
-	q, e := route.Query()
+	q, e := route.Query(ctx)
 	if e != nil {
 		return e.Code, nil, e
 	}
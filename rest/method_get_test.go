@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/entropyinf/rest-layer/resource"
 	"github.com/entropyinf/rest-layer/resource/testing/mem"
+	"github.com/entropyinf/rest-layer/rest"
 	"github.com/entropyinf/rest-layer/schema"
+	"github.com/entropyinf/rest-layer/schema/query"
 )
 
 func TestGetListInvalidQuery(t *testing.T) {
@@ -208,6 +211,450 @@ func TestGetListPagination(t *testing.T) {
 		t.Run(n, tc.Test)
 	}
 }
+func TestGetListSortCollation(t *testing.T) {
+	sharedInit := func() *requestTestVars {
+		s := mem.NewHandler()
+		s.Insert(context.TODO(), []*resource.Item{
+			{ID: "1", Payload: map[string]interface{}{"id": "1", "name": "Zoe"}},
+			{ID: "2", Payload: map[string]interface{}{"id": "2", "name": "adam"}},
+			{ID: "3", Payload: map[string]interface{}{"id": "3", "name": "Bob"}},
+		})
+
+		idx := resource.NewIndex()
+		idx.Bind("foo", schema.Schema{
+			Fields: schema.Fields{
+				"id":   {Sortable: true, Filterable: true},
+				"name": {Sortable: true, Filterable: true, Validator: &schema.String{}},
+			},
+		}, s, resource.DefaultConf)
+
+		return &requestTestVars{
+			Index:   idx,
+			Storers: map[string]resource.Storer{"foo": s},
+		}
+	}
+
+	tests := map[string]requestTest{
+		"sort:bytewise": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?sort=name", nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "3", "name": "Bob"}, {"id": "1", "name": "Zoe"}, {"id": "2", "name": "adam"}]`,
+		},
+		"sort:ci": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?sort=name:ci", nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "2", "name": "adam"}, {"id": "3", "name": "Bob"}, {"id": "1", "name": "Zoe"}]`,
+		},
+		"sort:-ci": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?sort=-name:ci", nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "1", "name": "Zoe"}, {"id": "3", "name": "Bob"}, {"id": "2", "name": "adam"}]`,
+		},
+		"filter:ci": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?filter={name:{$eq:"bob",$options:"i"}}`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "3", "name": "Bob"}]`,
+		},
+		"sort:invalid-collation": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?sort=id:ci", nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"sort": ["id: collation requires a string field"]
+				}
+			}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
+
+func TestGetListGeo(t *testing.T) {
+	sharedInit := func() *requestTestVars {
+		s := mem.NewHandler()
+		s.Insert(context.TODO(), []*resource.Item{
+			{ID: "1", Payload: map[string]interface{}{"id": "1", "loc": schema.NewGeoJSONPoint(2.3522, 48.8566)}},
+			{ID: "2", Payload: map[string]interface{}{"id": "2", "loc": schema.NewGeoJSONPoint(-0.1276, 51.5072)}},
+			{ID: "3", Payload: map[string]interface{}{"id": "3", "loc": schema.NewGeoJSONPoint(2.1301, 48.8049)}},
+		})
+
+		idx := resource.NewIndex()
+		idx.Bind("foo", schema.Schema{
+			Fields: schema.Fields{
+				"id":  {Sortable: true, Filterable: true},
+				"loc": {Sortable: true, Filterable: true, Validator: &schema.GeoPoint{}},
+			},
+		}, s, resource.DefaultConf)
+
+		return &requestTestVars{
+			Index:   idx,
+			Storers: map[string]resource.Storer{"foo": s},
+		}
+	}
+
+	tests := map[string]requestTest{
+		"filter:near": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id&filter={loc:{$near:{lat:48.8566,lng:2.3522},$maxDistance:20000}}`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "1"}, {"id": "3"}]`,
+		},
+		"filter:geoWithin": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id&filter={loc:{$geoWithin:{$box:[[-1,50],[1,52]]}}}`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "2"}]`,
+		},
+		"sort:near": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id&sort=loc:near(2.1301,48.8049)`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "3"}, {"id": "1"}, {"id": "2"}]`,
+		},
+		"filter:not-geo": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?filter={id:{$near:[1,2]}}`, nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"filter": ["id: not a geo point field"]
+				}
+			}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
+
+func TestGetListSearch(t *testing.T) {
+	newInit := func(indexed bool) func() *requestTestVars {
+		return func() *requestTestVars {
+			s := mem.NewHandler()
+			s.Insert(context.TODO(), []*resource.Item{
+				{ID: "1", Payload: map[string]interface{}{"id": "1", "text": "The quick brown fox"}},
+				{ID: "2", Payload: map[string]interface{}{"id": "2", "text": "The lazy dog"}},
+				{ID: "3", Payload: map[string]interface{}{"id": "3", "text": "Quick quick fox jumps"}},
+				{ID: "4", Payload: map[string]interface{}{"id": "4", "text": "Brown dog"}},
+			})
+			if indexed {
+				s.EnableSearchIndex("text")
+			}
+			// Changes made after the index is enabled must be reflected.
+			s.Update(context.TODO(),
+				&resource.Item{ID: "2", Payload: map[string]interface{}{"id": "2", "text": "A quick dog"}},
+				&resource.Item{ID: "2"})
+			s.Delete(context.TODO(), &resource.Item{ID: "4"})
+
+			idx := resource.NewIndex()
+			idx.Bind("foo", schema.Schema{
+				Fields: schema.Fields{
+					"id":   {Sortable: true, Filterable: true},
+					"text": {Filterable: true, Validator: &schema.String{}},
+				},
+			}, s, resource.DefaultConf)
+
+			return &requestTestVars{
+				Index:   idx,
+				Storers: map[string]resource.Storer{"foo": s},
+			}
+		}
+	}
+
+	tests := map[string]requestTest{
+		"score": {
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id&filter={text:{$search:"QUICK"}}&sort=_score`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "3"}, {"id": "2"}, {"id": "1"}]`,
+		},
+		"score:reversed": {
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id&filter={text:{$search:"QUICK"}}&sort=-_score`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "1"}, {"id": "2"}, {"id": "3"}]`,
+		},
+		"terms": {
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id&filter={text:{$search:"fox quick"}}&sort=id`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "1"}, {"id": "3"}]`,
+		},
+		"prefix": {
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id&filter={text:{$search:"do*"}}&sort=id`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "2"}]`,
+		},
+		"updated": {
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id&filter={text:{$search:"lazy"}}`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[]`,
+		},
+		"nested": {
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id&filter={$or:[{text:{$search:"jumps"}},{id:"1"}]}&sort=_score`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "3"}, {"id": "1"}]`,
+		},
+		"invalid": {
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?filter={text:{$search:"*"}}`, nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"filter": ["char 18: text: $search: no search terms"]
+				}
+			}`,
+		},
+	}
+	for n, tc := range tests {
+		scan, indexed := tc, tc
+		scan.Init = newInit(false)
+		t.Run(n, scan.Test)
+		indexed.Init = newInit(true)
+		t.Run(n+":indexed", indexed.Test)
+	}
+}
+
+func TestGetListQueryLimits(t *testing.T) {
+	sharedInit := func() *requestTestVars {
+		s := mem.NewHandler()
+		s.Insert(context.TODO(), []*resource.Item{
+			{ID: "1", Payload: map[string]interface{}{"id": "1"}},
+			{ID: "2", Payload: map[string]interface{}{"id": "2", "parent": "1"}},
+			{ID: "3", Payload: map[string]interface{}{"id": "3", "parent": "2"}},
+		})
+
+		idx := resource.NewIndex()
+		idx.Bind("foo", schema.Schema{
+			Fields: schema.Fields{
+				"id":     {Sortable: true, Filterable: true},
+				"parent": {Filterable: true, Validator: &schema.Reference{Path: "foo"}},
+			},
+		}, s, resource.Conf{
+			AllowedModes:           resource.ReadWrite,
+			PaginationDefaultLimit: 100,
+			QueryLimits: query.Limits{
+				MaxPredicateNodes:  2,
+				MaxRegexComplexity: 20,
+				MaxEmbedDepth:      1,
+				MaxLimit:           2,
+			},
+		})
+
+		return &requestTestVars{
+			Index:   idx,
+			Storers: map[string]resource.Storer{"foo": s},
+		}
+	}
+
+	tests := map[string]requestTest{
+		"default-limit": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id&sort=id`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "1"}, {"id": "2"}]`,
+		},
+		"embed": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=id,parent{id}&filter={id:"2"}`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "2", "parent": {"id": "1"}}]`,
+		},
+		"limits": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?fields=parent{parent{id}}&filter={$or:[{id:"1"},{id:"2"}]}&limit=3`, nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"fields": ["parent.parent: embedding depth exceeds the maximum of 1"],
+					"filter": ["predicate of 3 expressions exceeds the maximum of 2"],
+					"limit": ["3 exceeds the maximum of 2"]
+				}
+			}`,
+		},
+		"regex": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?filter={id:{$regex:"^(a|b|c)+x{20}$"}}`, nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"filter": ["id: $regex: complexity of 28 exceeds the maximum of 20"]
+				}
+			}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
+
+func TestGetListFilterParsers(t *testing.T) {
+	newInit := func(parser rest.FilterParser) func() *requestTestVars {
+		return func() *requestTestVars {
+			s := mem.NewHandler()
+			s.Insert(context.TODO(), []*resource.Item{
+				{ID: "1", Payload: map[string]interface{}{"id": "1", "name": "bob", "age": 20}},
+				{ID: "2", Payload: map[string]interface{}{"id": "2", "name": "bobby", "age": 15}},
+				{ID: "3", Payload: map[string]interface{}{"id": "3", "name": "alice", "age": 30}},
+			})
+
+			idx := resource.NewIndex()
+			foo := idx.Bind("foo", schema.Schema{
+				Fields: schema.Fields{
+					"id":   {Sortable: true},
+					"name": {Filterable: true, Validator: &schema.String{}},
+					"age":  {Filterable: true, Validator: &schema.Integer{}},
+				},
+			}, s, resource.DefaultConf)
+			foo.Alias("adults", url.Values{"filter": []string{`{age: {$gte: 18}}`}, "sort": []string{"id"}})
+
+			return &requestTestVars{
+				Index:        idx,
+				Storers:      map[string]resource.Storer{"foo": s},
+				FilterParser: parser,
+			}
+		}
+	}
+
+	tests := map[string]requestTest{
+		"rsql": {
+			Init: newInit(rest.RSQLFilter),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?sort=id&filter="+url.QueryEscape("age=ge=18;name==bob*"), nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "1", "name": "bob", "age": 20}]`,
+		},
+		"rsql:or": {
+			Init: newInit(rest.RSQLFilter),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?sort=id&filter="+url.QueryEscape("age<18,name==alice"), nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "2", "name": "bobby", "age": 15}, {"id": "3", "name": "alice", "age": 30}]`,
+		},
+		"rsql:invalid": {
+			Init: newInit(rest.RSQLFilter),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?filter="+url.QueryEscape("age=ge=old"), nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"filter": ["char 10: age: =ge=: invalid value \"old\": not a number"]
+				}
+			}`,
+		},
+		"flat": {
+			Init: newInit(rest.FlatFilter),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?sort=id&age[gte]=18&name[in]=bob,alice", nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "1", "name": "bob", "age": 20}, {"id": "3", "name": "alice", "age": 30}]`,
+		},
+		"rsql:alias": {
+			Init: newInit(rest.RSQLFilter),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/adults?filter="+url.QueryEscape("name==bob*"), nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "1", "name": "bob", "age": 20}]`,
+		},
+		"flat:alias": {
+			Init: newInit(rest.FlatFilter),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo/adults?name[in]=bobby,alice", nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "3", "name": "alice", "age": 30}]`,
+		},
+		"flat:filter": {
+			Init: newInit(rest.FlatFilter),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?sort=id&filter=ignored", nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id": "1", "name": "bob", "age": 20}, {"id": "2", "name": "bobby", "age": 15}, {"id": "3", "name": "alice", "age": 30}]`,
+		},
+		"flat:unknown": {
+			Init: newInit(rest.FlatFilter),
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", "/foo?color=red", nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {
+					"filter": ["color: unknown query field"]
+				}
+			}`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
+
 func TestGetListFieldHandler(t *testing.T) {
 	sharedInit := func() *requestTestVars {
 		s := mem.NewHandler()
@@ -344,6 +791,73 @@ func TestGetListFilter(t *testing.T) {
 	}
 }
 
+func TestGetListReadPermission(t *testing.T) {
+	sharedInit := func() *requestTestVars {
+		s := mem.NewHandler()
+		s.Insert(context.TODO(), []*resource.Item{
+			{ID: "1", Payload: map[string]interface{}{"id": "1", "name": "a", "salary": 10}},
+		})
+
+		idx := resource.NewIndex()
+		idx.Bind("foo", schema.Schema{
+			Fields: schema.Fields{
+				"id":   {},
+				"name": {Filterable: true, Sortable: true},
+				"salary": {
+					Filterable:     true,
+					Sortable:       true,
+					ReadPermission: func(ctx context.Context, doc map[string]interface{}) bool { return false },
+					Validator:      &schema.Integer{},
+				},
+			},
+		}, s, resource.DefaultConf)
+
+		return &requestTestVars{
+			Index:   idx,
+			Storers: map[string]resource.Storer{"foo": s},
+		}
+	}
+
+	tests := map[string]requestTest{
+		"filter": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?filter={salary:{$gt:5}}`, nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"filter": ["salary: permission denied"]}
+			}`,
+		},
+		"sort": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?sort=salary`, nil)
+			},
+			ResponseCode: 422,
+			ResponseBody: `{
+				"code": 422,
+				"message": "URL parameters contain error(s)",
+				"issues": {"sort": ["salary: permission denied"]}
+			}`,
+		},
+		"readable": {
+			Init: sharedInit,
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("GET", `/foo?filter={name:"a"}&sort=name`, nil)
+			},
+			ResponseCode: 200,
+			ResponseBody: `[{"id":"1","name":"a"}]`,
+		},
+	}
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
+
 func TestGetListArray(t *testing.T) {
 	sharedInit := func() *requestTestVars {
 		s := mem.NewHandler()
@@ -9,7 +9,7 @@ import (
 
 // itemDelete handles DELETE resquests on an item URL.
 func itemDelete(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
-	q, e := route.Query()
+	q, e := route.Query(ctx)
 	if e != nil {
 		return e.Code, nil, e
 	}
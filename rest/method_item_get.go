@@ -10,7 +10,7 @@ import (
 
 // itemGet handles GET and HEAD resquests on an item URL.
 func itemGet(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
-	q, e := route.Query()
+	q, e := route.Query(ctx)
 	if e != nil {
 		return e.Code, nil, e
 	}
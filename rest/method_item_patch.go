@@ -9,6 +9,7 @@ import (
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/schema"
 	"github.com/entropyinf/rest-layer/schema/query"
 )
 
@@ -19,9 +20,11 @@ func isJSONPatch(r *http.Request) bool {
 	return false
 }
 
-// itemPatch handles PATCH requests on an item URL.
+// itemPatch handles PATCH requests on an item URL. The payload is either a JSON
+// Patch or, by default, a JSON Merge Patch.
 //
-// Reference: http://tools.ietf.org/html/rfc5789, http://tools.ietf.org/html/rfc6902
+// Reference: http://tools.ietf.org/html/rfc5789, http://tools.ietf.org/html/rfc6902,
+// http://tools.ietf.org/html/rfc7396
 func itemPatch(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
 	var payload map[string]interface{}
 	var patchJSON []byte
@@ -38,7 +41,7 @@ func itemPatch(ctx context.Context, r *http.Request, route *RouteMatch) (status
 		}
 	}
 
-	q, e := route.Query()
+	q, e := route.Query(ctx)
 	if e != nil {
 		return e.Code, nil, e
 	}
@@ -87,13 +90,19 @@ func itemPatch(ctx context.Context, r *http.Request, route *RouteMatch) (status
 	for k, v := range route.ResourcePath.Values() {
 		base[k] = v
 	}
-	doc, errs := rsrc.Validator().Validate(changes, base)
+	doc, errs := schema.ValidateContext(ctx, rsrc.Validator(), changes, base)
 	if len(errs) > 0 {
 		return 422, nil, &Error{422, "Document contains error(s)", errs}
 	}
 	if id, found := doc["id"]; found && id != original.ID {
 		return 422, nil, &Error{422, "Cannot change document ID", nil}
 	}
+	if errs, err := checkConstraints(ctx, rsrc, changes, doc); err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	} else if len(errs) > 0 {
+		return 422, nil, &Error{422, "Document contains error(s)", errs}
+	}
 	item, err := resource.NewItem(doc)
 	if err != nil {
 		e = NewError(err)
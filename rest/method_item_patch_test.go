@@ -85,6 +85,53 @@ func TestPatchItem(t *testing.T) {
 			ResponseCode: http.StatusNotImplemented,
 			ResponseBody: `{"code": 501, "message": "No Storage Defined"}`,
 		},
+		`unique:unchanged`: {
+			Init: func() *requestTestVars {
+				s := mem.NewHandler()
+				s.Insert(context.Background(), []*resource.Item{
+					{ID: "1", Payload: map[string]interface{}{"id": "1", "name": "a"}},
+					{ID: "2", Payload: map[string]interface{}{"id": "2", "name": "a"}},
+				})
+				index := resource.NewIndex()
+				index.Bind("foo", schema.Schema{Fields: schema.Fields{
+					"id":   {},
+					"name": {Unique: true},
+					"bar":  {},
+				}}, s, resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				body := bytes.NewReader([]byte(`{"bar": "x"}`))
+				return http.NewRequest("PATCH", "/foo/1", body)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "1", "name": "a", "bar": "x"}`,
+		},
+		`unique:changed`: {
+			Init: func() *requestTestVars {
+				s := mem.NewHandler()
+				s.Insert(context.Background(), []*resource.Item{
+					{ID: "1", Payload: map[string]interface{}{"id": "1", "name": "a"}},
+					{ID: "2", Payload: map[string]interface{}{"id": "2", "name": "b"}},
+				})
+				index := resource.NewIndex()
+				index.Bind("foo", schema.Schema{Fields: schema.Fields{
+					"id":   {},
+					"name": {Unique: true},
+				}}, s, resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				body := bytes.NewReader([]byte(`{"name": "a"}`))
+				return http.NewRequest("PATCH", "/foo/2", body)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "Document contains error(s)",
+				"issues": {"name": ["already used by another item"]}
+			}`,
+		},
 		`pathID:not-found`: {
 			Init: sharedInit,
 			NewRequest: func() (*http.Request, error) {
@@ -268,6 +315,134 @@ func TestPatchItem(t *testing.T) {
 	}
 }
 
+func TestMergePatchItem(t *testing.T) {
+	now := time.Now()
+
+	sharedInit := func() *requestTestVars {
+		s := mem.NewHandler()
+		s.Insert(context.Background(), []*resource.Item{
+			{ID: "1", ETag: "a", Updated: now, Payload: map[string]interface{}{
+				"id":   "1",
+				"foo":  "odd",
+				"meta": map[string]interface{}{"a": "a", "b": "b", "ro": "ro"},
+				"dict": map[string]interface{}{"x": "1", "y": "2"},
+				"list": []interface{}{"a", "b"},
+			}},
+		})
+		idx := resource.NewIndex()
+		idx.Bind("foo", schema.Schema{
+			Fields: schema.Fields{
+				"id":  {},
+				"foo": {},
+				"meta": {
+					Schema: &schema.Schema{
+						Fields: schema.Fields{
+							"a":  {Validator: &schema.String{}},
+							"b":  {Validator: &schema.String{}},
+							"ro": {Validator: &schema.String{}, ReadOnly: true},
+							"updated": {
+								OnUpdate: func(ctx context.Context, value interface{}) interface{} {
+									return "yes"
+								},
+							},
+						},
+					},
+				},
+				"dict": {Validator: &schema.Dict{Values: schema.Field{Validator: &schema.String{}}}},
+				"list": {Validator: &schema.Array{Values: schema.Field{Validator: &schema.String{}}}},
+			},
+		}, s, resource.DefaultConf)
+		return &requestTestVars{
+			Index:   idx,
+			Storers: map[string]resource.Storer{"foo": s},
+		}
+	}
+	checkPayload := func(payload map[string]interface{}) requestCheckerFunc {
+		return func(t *testing.T, vars *requestTestVars) {
+			q := query.Query{Predicate: query.Predicate{&query.Equal{Field: "id", Value: "1"}}, Window: &query.Window{Limit: 1}}
+			items, err := vars.Storers["foo"].Find(context.Background(), &q)
+			if err != nil {
+				t.Errorf("s.Find failed: %s", err)
+				return
+			} else if len(items.Items) != 1 {
+				t.Errorf("item with ID 1 not found")
+				return
+			}
+			if !reflect.DeepEqual(payload, items.Items[0].Payload) {
+				t.Errorf("Unexpected stored payload:\nexpect: %#v\ngot: %#v", payload, items.Items[0].Payload)
+			}
+		}
+	}
+	patch := func(body string) func() (*http.Request, error) {
+		return func() (*http.Request, error) {
+			r, err := http.NewRequest("PATCH", "/foo/1", bytes.NewReader([]byte(body)))
+			if err != nil {
+				return nil, err
+			}
+			r.Header.Set("Content-Type", "application/merge-patch+json")
+			return r, nil
+		}
+	}
+
+	tests := map[string]requestTest{
+		`body:null`: {
+			Init:         sharedInit,
+			NewRequest:   patch(`{"foo": null}`),
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "1", "meta": {"a": "a", "b": "b", "ro": "ro", "updated": "yes"}, "dict": {"x": "1", "y": "2"}, "list": ["a", "b"]}`,
+			ExtraTest: checkPayload(map[string]interface{}{
+				"id":   "1",
+				"meta": map[string]interface{}{"a": "a", "b": "b", "ro": "ro", "updated": "yes"},
+				"dict": map[string]interface{}{"x": "1", "y": "2"},
+				"list": []interface{}{"a", "b"},
+			}),
+		},
+		`body:sub-schema`: {
+			Init:         sharedInit,
+			NewRequest:   patch(`{"meta": {"a": "A", "b": null}}`),
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "1", "foo": "odd", "meta": {"a": "A", "ro": "ro", "updated": "yes"}, "dict": {"x": "1", "y": "2"}, "list": ["a", "b"]}`,
+			ExtraTest: checkPayload(map[string]interface{}{
+				"id":   "1",
+				"foo":  "odd",
+				"meta": map[string]interface{}{"a": "A", "ro": "ro", "updated": "yes"},
+				"dict": map[string]interface{}{"x": "1", "y": "2"},
+				"list": []interface{}{"a", "b"},
+			}),
+		},
+		`body:sub-schema,read-only`: {
+			Init:         sharedInit,
+			NewRequest:   patch(`{"meta": {"ro": "changed"}}`),
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "Document contains error(s)",
+				"issues": {
+					"meta": [{"ro": ["read-only"]}]
+				}
+			}`,
+		},
+		`body:dict,array`: {
+			Init:         sharedInit,
+			NewRequest:   patch(`{"dict": {"x": null, "z": "3"}, "list": ["c"]}`),
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "1", "foo": "odd", "meta": {"a": "a", "b": "b", "ro": "ro", "updated": "yes"}, "dict": {"y": "2", "z": "3"}, "list": ["c"]}`,
+			ExtraTest: checkPayload(map[string]interface{}{
+				"id":   "1",
+				"foo":  "odd",
+				"meta": map[string]interface{}{"a": "a", "b": "b", "ro": "ro", "updated": "yes"},
+				"dict": map[string]interface{}{"y": "2", "z": "3"},
+				"list": []interface{}{"c"},
+			}),
+		},
+	}
+
+	for n, tc := range tests {
+		tc := tc // capture range variable
+		t.Run(n, tc.Test)
+	}
+}
+
 func TestJSONPatchItem(t *testing.T) {
 	now := time.Now()
 	yesterday := now.Add(-24 * time.Hour)
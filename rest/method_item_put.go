@@ -17,7 +17,7 @@ func itemPut(ctx context.Context, r *http.Request, route *RouteMatch) (status in
 	if e := decodePayload(r, &payload); e != nil {
 		return e.Code, nil, e
 	}
-	q, e := route.Query()
+	q, e := route.Query(ctx)
 	if e != nil {
 		return e.Code, nil, e
 	}
@@ -68,7 +68,7 @@ func itemPut(ctx context.Context, r *http.Request, route *RouteMatch) (status in
 			delete(changes, k)
 		}
 	}
-	doc, errs := rsrc.Validator().Validate(changes, base)
+	doc, errs := schema.ValidateContext(ctx, rsrc.Validator(), changes, base)
 	if len(errs) > 0 {
 		return 422, nil, &Error{422, "Document contains error(s)", errs}
 	}
@@ -77,6 +77,12 @@ func itemPut(ctx context.Context, r *http.Request, route *RouteMatch) (status in
 			return 422, nil, &Error{422, "Cannot change document ID", nil}
 		}
 	}
+	if errs, err := checkConstraints(ctx, rsrc, changes, doc); err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	} else if len(errs) > 0 {
+		return 422, nil, &Error{422, "Document contains error(s)", errs}
+	}
 	item, err := resource.NewItem(doc)
 	if err != nil {
 		e = NewError(err)
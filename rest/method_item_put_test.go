@@ -87,6 +87,52 @@ func TestPutItem(t *testing.T) {
 			ResponseCode: http.StatusNotImplemented,
 			ResponseBody: `{"code": 501, "message": "No Storage Defined"}`,
 		},
+		`ReplaceKeepsUniqueValue`: {
+			Init: func() *requestTestVars {
+				s := mem.NewHandler()
+				s.Insert(context.Background(), []*resource.Item{
+					{ID: "1", Payload: map[string]interface{}{"id": "1", "foo": "a"}},
+					{ID: "2", Payload: map[string]interface{}{"id": "2", "foo": "b"}},
+				})
+				index := resource.NewIndex()
+				index.Bind("foo", schema.Schema{Fields: schema.Fields{
+					"id":  {},
+					"foo": {Unique: true},
+				}}, s, resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				body := bytes.NewReader([]byte(`{"foo": "a"}`))
+				return http.NewRequest("PUT", "/foo/1", body)
+			},
+			ResponseCode: http.StatusOK,
+			ResponseBody: `{"id": "1", "foo": "a"}`,
+		},
+		`ReplaceUniqueConflict`: {
+			Init: func() *requestTestVars {
+				s := mem.NewHandler()
+				s.Insert(context.Background(), []*resource.Item{
+					{ID: "1", Payload: map[string]interface{}{"id": "1", "foo": "a"}},
+					{ID: "2", Payload: map[string]interface{}{"id": "2", "foo": "b"}},
+				})
+				index := resource.NewIndex()
+				index.Bind("foo", schema.Schema{Fields: schema.Fields{
+					"id":  {},
+					"foo": {Unique: true},
+				}}, s, resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				body := bytes.NewReader([]byte(`{"foo": "b"}`))
+				return http.NewRequest("PUT", "/foo/1", body)
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "Document contains error(s)",
+				"issues": {"foo": ["already used by another item"]}
+			}`,
+		},
 		`CreateModeNotAllowed`: {
 			Init: func() *requestTestVars {
 				s := mem.NewHandler()
@@ -11,7 +11,7 @@ import (
 
 // listPost handles POST resquests on a resource URL.
 func listPost(ctx context.Context, r *http.Request, route *RouteMatch) (status int, headers http.Header, body interface{}) {
-	q, e := route.Query()
+	q, e := route.Query(ctx)
 	if e != nil {
 		return e.Code, nil, e
 	}
@@ -26,10 +26,16 @@ func listPost(ctx context.Context, r *http.Request, route *RouteMatch) (status i
 	for k, v := range route.ResourcePath.Values() {
 		base[k] = v
 	}
-	doc, errs := rsrc.Validator().Validate(changes, base)
+	doc, errs := schema.ValidateContext(ctx, rsrc.Validator(), changes, base)
 	if len(errs) > 0 {
 		return 422, nil, &Error{422, "Document contains error(s)", errs}
 	}
+	if errs, err := checkConstraints(ctx, rsrc, changes, doc); err != nil {
+		e = NewError(err)
+		return e.Code, nil, e
+	} else if len(errs) > 0 {
+		return 422, nil, &Error{422, "Document contains error(s)", errs}
+	}
 	item, err := resource.NewItem(doc)
 	if err != nil {
 		e = NewError(err)
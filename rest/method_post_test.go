@@ -208,7 +208,7 @@ func TestHandlerPostList(t *testing.T) {
 				index.Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}}}, s, resource.DefaultConf)
 				index.Bind("bar", schema.Schema{Fields: schema.Fields{
 					"id":  {},
-					"foo": {Validator: &schema.Reference{Path: "foo"}},
+					"foo": {Validator: &schema.Reference{Path: "foo", CheckExistence: true}},
 				}}, s, resource.DefaultConf)
 				return &requestTestVars{Index: index}
 			},
@@ -222,6 +222,116 @@ func TestHandlerPostList(t *testing.T) {
 				"issues": {"foo": ["Not Found"]}
 			}`,
 		},
+		"WithSubSchemaReferenceNotFound": {
+			Init: func() *requestTestVars {
+				s := mem.NewHandler()
+				index := resource.NewIndex()
+				index.Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}}}, s, resource.DefaultConf)
+				index.Bind("bar", schema.Schema{Fields: schema.Fields{
+					"id": {},
+					"sub": {Schema: &schema.Schema{Fields: schema.Fields{
+						"foo": {Validator: &schema.Reference{Path: "foo", CheckExistence: true}},
+					}}},
+				}}, s, resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/bar", bytes.NewBufferString(`{"id": "1", "sub": {"foo": "nonexisting"}}`))
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "Document contains error(s)",
+				"issues": {"sub": [{"foo": ["Not Found"]}]}
+			}`,
+		},
+		"WithUniqueConflict": {
+			Init: func() *requestTestVars {
+				s := mem.NewHandler()
+				s.Insert(context.Background(), []*resource.Item{{ID: "1", Payload: map[string]interface{}{"id": "1", "name": "a"}}})
+				index := resource.NewIndex()
+				index.Bind("foo", schema.Schema{Fields: schema.Fields{
+					"id":   {},
+					"name": {Unique: true},
+				}}, s, resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo", bytes.NewBufferString(`{"id": "2", "name": "a"}`))
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "Document contains error(s)",
+				"issues": {"name": ["already used by another item"]}
+			}`,
+		},
+		"WithUnique": {
+			Init: func() *requestTestVars {
+				s := mem.NewHandler()
+				s.Insert(context.Background(), []*resource.Item{{ID: "1", Payload: map[string]interface{}{"id": "1", "name": "a"}}})
+				index := resource.NewIndex()
+				index.Bind("foo", schema.Schema{Fields: schema.Fields{
+					"id":   {},
+					"name": {Unique: true},
+				}}, s, resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo", bytes.NewBufferString(`{"id": "2", "name": "b"}`))
+			},
+			ResponseCode: http.StatusCreated,
+			ResponseBody: `{"id": "2", "name": "b"}`,
+		},
+		"WithUniqueOtherParent": {
+			Init: func() *requestTestVars {
+				s1 := mem.NewHandler()
+				s1.Insert(context.Background(), []*resource.Item{
+					{ID: "1", Payload: map[string]interface{}{"id": "1"}},
+					{ID: "2", Payload: map[string]interface{}{"id": "2"}},
+				})
+				s2 := mem.NewHandler()
+				s2.Insert(context.Background(), []*resource.Item{{ID: "1", Payload: map[string]interface{}{"id": "1", "foo": "1", "name": "a"}}})
+				index := resource.NewIndex()
+				foo := index.Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}}}, s1, resource.DefaultConf)
+				foo.Bind("sub", "foo", schema.Schema{Fields: schema.Fields{
+					"id":   {},
+					"foo":  {Validator: &schema.Reference{Path: "foo"}},
+					"name": {Unique: true},
+				}}, s2, resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo/2/sub", bytes.NewBufferString(`{"id": "2", "name": "a"}`))
+			},
+			ResponseCode: http.StatusCreated,
+			ResponseBody: `{"id": "2", "foo": "2", "name": "a"}`,
+		},
+		"WithUniqueConflictSameParent": {
+			Init: func() *requestTestVars {
+				s1 := mem.NewHandler()
+				s1.Insert(context.Background(), []*resource.Item{{ID: "1", Payload: map[string]interface{}{"id": "1"}}})
+				s2 := mem.NewHandler()
+				s2.Insert(context.Background(), []*resource.Item{{ID: "1", Payload: map[string]interface{}{"id": "1", "foo": "1", "name": "a"}}})
+				index := resource.NewIndex()
+				foo := index.Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}}}, s1, resource.DefaultConf)
+				foo.Bind("sub", "foo", schema.Schema{Fields: schema.Fields{
+					"id":   {},
+					"foo":  {Validator: &schema.Reference{Path: "foo"}},
+					"name": {Unique: true},
+				}}, s2, resource.DefaultConf)
+				return &requestTestVars{Index: index}
+			},
+			NewRequest: func() (*http.Request, error) {
+				return http.NewRequest("POST", "/foo/1/sub", bytes.NewBufferString(`{"id": "2", "name": "a"}`))
+			},
+			ResponseCode: http.StatusUnprocessableEntity,
+			ResponseBody: `{
+				"code": 422,
+				"message": "Document contains error(s)",
+				"issues": {"name": ["already used by another item"]}
+			}`,
+		},
 		"WithReferenceNoStorage": {
 			// FIXME: For NoStorage, it's probably better to error early (during Bind).
 			Init: func() *requestTestVars {
@@ -230,7 +340,7 @@ func TestHandlerPostList(t *testing.T) {
 				index.Bind("foo", schema.Schema{Fields: schema.Fields{"id": {}}}, nil, resource.DefaultConf)
 				index.Bind("bar", schema.Schema{Fields: schema.Fields{
 					"id":  {},
-					"foo": {Validator: &schema.Reference{Path: "foo"}},
+					"foo": {Validator: &schema.Reference{Path: "foo", CheckExistence: true}},
 				}}, s, resource.DefaultConf)
 				return &requestTestVars{Index: index}
 			},
@@ -313,7 +423,7 @@ func TestHandlerPostList(t *testing.T) {
 				index.Bind("bar", schema.Schema{Fields: schema.Fields{
 					"id": {},
 					"foos": {Validator: &schema.Array{
-						Values: schema.Field{Validator: &schema.Reference{Path: "foo"}},
+						Values: schema.Field{Validator: &schema.Reference{Path: "foo", CheckExistence: true}},
 					}},
 				}}, s, resource.DefaultConf)
 				return &requestTestVars{Index: index}
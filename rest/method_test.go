@@ -28,6 +28,8 @@ type requestCheckerFunc func(*testing.T, *requestTestVars)
 type requestTestVars struct {
 	Index   resource.Index             // required
 	Storers map[string]resource.Storer // optional: may be used by ExtraTest function
+
+	FilterParser rest.FilterParser // optional
 }
 
 // Test runs tt in parallel mode. It can be passed as a second parameter to
@@ -40,6 +42,7 @@ func (tt *requestTest) Test(t *testing.T) {
 		t.Errorf("rest.NewHandler failed: %s", err)
 		return
 	}
+	h.FilterParser = vars.FilterParser
 	r, err := tt.NewRequest()
 	if err != nil || r == nil {
 		t.Errorf("tt.NewRequest failed: %s", err)
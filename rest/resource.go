@@ -55,3 +55,8 @@ func (r restResource) SubResource(ctx context.Context, path string) (query.Resou
 	}
 	return restResource{rsc}, nil
 }
+
+// QueryLimits implements query.LimitedResource interface.
+func (r restResource) QueryLimits() query.Limits {
+	return r.Conf().QueryLimits
+}
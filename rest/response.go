@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/schema"
 )
 
 // ResponseFormatter defines an interface responsible for formatting a the
@@ -35,6 +36,14 @@ type ResponseSender interface {
 // default. This formatter can easily be extended or replaced by implementing
 // ResponseFormatter interface and setting it on Handler.ResponseFormatter.
 type DefaultResponseFormatter struct {
+	// MessageCatalog returns the catalog used to render the validation errors
+	// of the request (i.e.: chosen from a language stored in ctx by a
+	// middleware). The English messages are used when nil or when the catalog
+	// does not know an error code.
+	MessageCatalog func(ctx context.Context) schema.MessageCatalog
+	// StructuredIssues renders validation errors as objects holding their code,
+	// params and message instead of their message only.
+	StructuredIssues bool
 }
 
 // DefaultResponseSender provides a base response sender to be used by default.
@@ -138,7 +147,11 @@ func (f DefaultResponseFormatter) FormatError(ctx context.Context, headers http.
 		}
 		if e, ok := err.(*Error); ok {
 			if e.Issues != nil {
-				payload["issues"] = e.Issues
+				var c schema.MessageCatalog
+				if f.MessageCatalog != nil {
+					c = f.MessageCatalog(ctx)
+				}
+				payload["issues"] = f.formatIssues(e.Issues, c)
 			}
 		}
 		return ctx, payload
@@ -146,6 +159,36 @@ func (f DefaultResponseFormatter) FormatError(ctx context.Context, headers http.
 	return ctx, nil
 }
 
+// formatIssues renders the validation errors found in issues, including those
+// of sub-schemas, using c.
+func (f DefaultResponseFormatter) formatIssues(issues map[string][]interface{}, c schema.MessageCatalog) map[string][]interface{} {
+	formatted := make(map[string][]interface{}, len(issues))
+	for field, errs := range issues {
+		ferrs := make([]interface{}, 0, len(errs))
+		for _, err := range errs {
+			switch err := err.(type) {
+			case *schema.ValidationError:
+				if c != nil {
+					err = err.Localize(c)
+				}
+				if f.StructuredIssues {
+					ferrs = append(ferrs, err)
+				} else {
+					ferrs = append(ferrs, err.Message)
+				}
+			case map[string][]interface{}:
+				ferrs = append(ferrs, f.formatIssues(err, c))
+			case schema.ErrorMap:
+				ferrs = append(ferrs, f.formatIssues(err, c))
+			default:
+				ferrs = append(ferrs, err)
+			}
+		}
+		formatted[field] = ferrs
+	}
+	return formatted
+}
+
 // formatResponse routes the type of response on the right ResponseFormater method for
 // internally supported types.
 func formatResponse(ctx context.Context, f ResponseFormatter, w http.ResponseWriter, status int, headers http.Header, resp interface{}, skipBody bool) (context.Context, int, interface{}) {
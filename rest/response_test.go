@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/entropyinf/rest-layer/resource"
+	"github.com/entropyinf/rest-layer/schema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -152,3 +153,31 @@ func TestDefaultResponseFormatterFormatError(t *testing.T) {
 	assert.Equal(t, rctx, ctx)
 	assert.Equal(t, map[string]interface{}{"code": 123, "message": "test", "issues": map[string][]interface{}{"field": {"error"}}}, payload)
 }
+
+func TestDefaultResponseFormatterFormatErrorIssues(t *testing.T) {
+	ctx := context.Background()
+	issues := map[string][]interface{}{
+		"name": {schema.NewValidationError("too_short", map[string]interface{}{"min": 3})},
+		"sub":  {map[string][]interface{}{"foo": {schema.NewValidationError("required", nil), "custom"}}},
+	}
+	err := &Error{422, "Document contains error(s)", issues}
+
+	_, payload := DefaultResponseFormatter{}.FormatError(ctx, http.Header{}, err, false)
+	assert.Equal(t, map[string][]interface{}{
+		"name": {"is shorter than 3"},
+		"sub":  {map[string][]interface{}{"foo": {"required", "custom"}}},
+	}, payload.(map[string]interface{})["issues"])
+
+	fr := schema.Messages{"too_short": "doit contenir au moins {min} caractères"}
+	rf := DefaultResponseFormatter{
+		MessageCatalog: func(ctx context.Context) schema.MessageCatalog {
+			return fr
+		},
+		StructuredIssues: true,
+	}
+	_, payload = rf.FormatError(ctx, http.Header{}, err, false)
+	assert.Equal(t, map[string][]interface{}{
+		"name": {&schema.ValidationError{Code: "too_short", Params: map[string]interface{}{"min": 3}, Message: "doit contenir au moins 3 caractères"}},
+		"sub":  {map[string][]interface{}{"foo": {&schema.ValidationError{Code: "required", Message: "required"}, "custom"}}},
+	}, payload.(map[string]interface{})["issues"])
+}
@@ -24,6 +24,15 @@ type RouteMatch struct {
 	ResourcePath ResourcePath
 	// Params is the list of client provided parameters (thru query-string or alias).
 	Params url.Values
+	// Aggregate is true when the request targets the aggregation endpoint of
+	// a collection (/resource/_aggregate).
+	Aggregate bool
+	// FilterParser parses the filter from the Params. If nil, JSONFilter is
+	// used.
+	FilterParser FilterParser
+	// AliasFilters holds the filters of the alias matched by the route. They
+	// are always parsed using JSONFilter, whatever the FilterParser.
+	AliasFilters []string
 }
 
 type key int
@@ -43,6 +52,10 @@ var routePool = sync.Pool{
 
 var errResourceNotFound = &Error{http.StatusNotFound, "Resource Not Found", nil}
 
+// aggregatePath is the path component of the aggregation endpoint of a
+// collection.
+const aggregatePath = "_aggregate"
+
 func contextWithRoute(ctx context.Context, route *RouteMatch) context.Context {
 	return context.WithValue(ctx, routeKey, route)
 }
@@ -117,10 +130,18 @@ func findRoute(path string, index resource.Index, route *RouteMatch) error {
 				return nil
 			}
 
-			// Handle aliases (/resource/alias or /resource1/id1/resource2/alias).
-			if alias, found := rsrc.GetAlias(id); found {
-				// Apply aliases query to the request.
+			// Handle aggregations (/resource/_aggregate) and aliases
+			// (/resource/alias or /resource1/id1/resource2/alias).
+			if id == aggregatePath {
+				route.Aggregate = true
+			} else if alias, found := rsrc.GetAlias(id); found {
+				// Apply aliases query to the request. Alias filters are
+				// kept apart as they use the JSON syntax.
 				for key, values := range alias {
+					if key == "filter" {
+						route.AliasFilters = append(route.AliasFilters, values...)
+						continue
+					}
 					for _, value := range values {
 						route.Params.Add(key, value)
 					}
@@ -178,9 +199,10 @@ func (r *RouteMatch) ResourceID() interface{} {
 	return (r.ResourcePath)[l-1].Value
 }
 
-// Query builds a query object from the matched route
-func (r *RouteMatch) Query() (*query.Query, *Error) {
-	qp := queryParser{rsc: r.Resource()}
+// Query builds a query object from the matched route. The filter and sort
+// params may only use fields the caller identified by ctx can read.
+func (r *RouteMatch) Query(ctx context.Context) (*query.Query, *Error) {
+	qp := queryParser{ctx: ctx, rsc: r.Resource()}
 	if qp.rsc == nil {
 		return nil, &Error{500, "missing resource", nil}
 	}
@@ -195,11 +217,17 @@ func (r *RouteMatch) Query() (*query.Query, *Error) {
 	// Parse query string params.
 	switch r.Method {
 	case "DELETE":
-		qp.parsePredicate(r.Params)
+		qp.parseAliasFilters(r.AliasFilters)
+		qp.parsePredicate(r.Params, r.FilterParser, false)
 		qp.parseWindow(r.Params, false)
 		qp.parseSort(r.Params)
 	case "HEAD", "GET":
-		qp.parsePredicate(r.Params)
+		qp.parseAliasFilters(r.AliasFilters)
+		qp.parsePredicate(r.Params, r.FilterParser, false)
+		if r.Aggregate {
+			// Aggregations only use the predicate of the query.
+			break
+		}
 		qp.parseWindow(r.Params, true)
 		qp.parseSort(r.Params)
 		qp.parseProjection(r.Params)
@@ -212,10 +240,27 @@ func (r *RouteMatch) Query() (*query.Query, *Error) {
 	return qp.results()
 }
 
+// Aggregation builds an aggregation from the aggregate parameter of the matched
+// route.
+func (r *RouteMatch) Aggregation() (*query.Aggregation, *Error) {
+	qp := queryParser{rsc: r.Resource()}
+	if qp.rsc == nil {
+		return nil, &Error{500, "missing resource", nil}
+	}
+	a := qp.parseAggregation(r.Params)
+	if _, err := qp.results(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
 // Release releases the route so it can be reused.
 func (r *RouteMatch) Release() {
 	r.Params = nil
 	r.Method = ""
+	r.Aggregate = false
+	r.FilterParser = nil
+	r.AliasFilters = nil
 	r.ResourcePath.clear()
 	routePool.Put(r)
 }
@@ -223,6 +268,7 @@ func (r *RouteMatch) Release() {
 // queryParser is a small helper type that parses query parameters, while also
 // storing any potential query issues for a combined error result.
 type queryParser struct {
+	ctx    context.Context
 	q      query.Query
 	issues map[string][]interface{}
 	rsc    *resource.Resource
@@ -248,33 +294,64 @@ func (qp *queryParser) parseProjection(params url.Values) {
 			qp.addIssue("fields", err.Error())
 		} else if err := p.Validate(qp.rsc.Validator()); err != nil {
 			qp.addIssue("fields", err.Error())
+		} else if err := qp.rsc.Conf().QueryLimits.CheckProjection(p, qp.rsc.Validator()); err != nil {
+			qp.addIssue("fields", err.Error())
 		} else {
 			qp.q.Projection = p
 		}
 	}
 }
 
-func (qp *queryParser) parsePredicate(params url.Values) {
-	if filters, found := params["filter"]; found {
-		// If several filter parameters are present, merge them using $and
-		for _, filter := range filters {
-			if p, err := query.ParsePredicate(filter); err != nil {
-				qp.addIssue("filter", err.Error())
-			} else if err := p.Prepare(qp.rsc.Validator()); err != nil {
-				qp.addIssue("filter", err.Error())
-			} else {
-				qp.q.Predicate = append(qp.q.Predicate, p...)
-			}
-		}
+// parsePredicate parses the filter param. Unless trusted, the filter may only
+// use fields readable by the caller.
+func (qp *queryParser) parsePredicate(params url.Values, parser FilterParser, trusted bool) {
+	if parser == nil {
+		parser = JSONFilter
+	}
+	if p, err := parser.ParseFilter(params, qp.rsc.Validator()); err != nil {
+		qp.addIssue("filter", err.Error())
+	} else if err := p.Prepare(qp.rsc.Validator()); err != nil {
+		qp.addIssue("filter", err.Error())
+	} else if err := qp.rsc.Conf().QueryLimits.CheckPredicate(p); err != nil {
+		qp.addIssue("filter", err.Error())
+	} else if err := p.CheckReadPermission(qp.ctx, qp.rsc.Validator()); !trusted && err != nil {
+		qp.addIssue("filter", err.Error())
+	} else {
+		qp.q.Predicate = append(qp.q.Predicate, p...)
+	}
+}
+
+// parseAliasFilters parses the filters of a resource alias, using the JSON
+// syntax. As they are defined by the API, they are trusted.
+func (qp *queryParser) parseAliasFilters(filters []string) {
+	if len(filters) > 0 {
+		qp.parsePredicate(url.Values{"filter": filters}, JSONFilter, true)
 	}
 }
 
+func (qp *queryParser) parseAggregation(params url.Values) *query.Aggregation {
+	aggregate := params.Get("aggregate")
+	if aggregate == "" {
+		qp.addIssue("aggregate", "required")
+		return nil
+	}
+	a, err := query.ParseAggregation(aggregate)
+	if err != nil {
+		qp.addIssue("aggregate", err.Error())
+	} else if err := a.Prepare(qp.rsc.Validator()); err != nil {
+		qp.addIssue("aggregate", err.Error())
+	}
+	return a
+}
+
 func (qp *queryParser) parseSort(params url.Values) {
 	if sort := params.Get("sort"); sort != "" {
 		if s, err := query.ParseSort(sort); err != nil {
 			qp.addIssue("sort", err.Error())
 		} else if err := s.Validate(qp.rsc.Validator()); err != nil {
 			qp.addIssue("sort", err.Error())
+		} else if err := s.CheckReadPermission(qp.ctx, qp.rsc.Validator()); err != nil {
+			qp.addIssue("sort", err.Error())
 		} else {
 			qp.q.Sort = s
 		}
@@ -282,17 +359,18 @@ func (qp *queryParser) parseSort(params url.Values) {
 }
 
 func (qp *queryParser) parseWindow(params url.Values, allowDefaultLimit bool) {
+	conf := qp.rsc.Conf()
 	limit := -1
 	if l, found, err := getUintParam(params, "limit"); found {
 		if err != nil {
 			qp.addIssue("limit", err.Error())
+		} else if err := conf.QueryLimits.CheckWindow(&query.Window{Limit: l}); err != nil {
+			qp.addIssue("limit", err.Error())
 		} else {
 			limit = l
 		}
 	} else if allowDefaultLimit {
-		if l := qp.rsc.Conf().PaginationDefaultLimit; l > 0 {
-			limit = l
-		}
+		limit = conf.QueryLimits.DefaultLimit(conf.PaginationDefaultLimit)
 	}
 	skip := 0
 	if s, found, err := getUintParam(params, "skip"); found {
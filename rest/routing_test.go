@@ -262,7 +262,7 @@ func TestRouteQueryFilter(t *testing.T) {
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	q, rErr := route.Query()
+	q, rErr := route.Query(context.Background())
 	if rErr != nil {
 		t.Errorf("unexpected error: %v", rErr)
 	}
@@ -140,8 +140,11 @@ func compareEtag(etag, baseEtag string) bool {
 // decodePayload decodes the payload from the provided request.
 func decodePayload(r *http.Request, payload *map[string]interface{}) *Error {
 	// Check content-type, if not specified, assume it's JSON and fail later
-	if ct := r.Header.Get("Content-Type"); ct != "" && strings.TrimSpace(strings.SplitN(ct, ";", 2)[0]) != "application/json" {
-		return &Error{501, fmt.Sprintf("Invalid Content-Type header: `%s' not supported", ct), nil}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt := strings.TrimSpace(strings.SplitN(ct, ";", 2)[0])
+		if mt != "application/json" && (r.Method != "PATCH" || mt != "application/merge-patch+json") {
+			return &Error{501, fmt.Sprintf("Invalid Content-Type header: `%s' not supported", ct), nil}
+		}
 	}
 	if r.Body == nil {
 		return nil
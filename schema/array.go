@@ -1,8 +1,6 @@
 package schema
 
 import (
-	"errors"
-	"fmt"
 	"strconv"
 )
 
@@ -36,7 +34,7 @@ func (v Array) validateValues(values []interface{}, query bool) ([]interface{},
 	for i, val := range values {
 		val, err := vFunc(val)
 		if err != nil {
-			return nil, fmt.Errorf("invalid value at #%d: %s", i+1, err)
+			return nil, NewValidationError("invalid_item", map[string]interface{}{"index": i + 1, "error": err})
 		}
 		values[i] = val
 	}
@@ -65,14 +63,14 @@ func (v Array) ValidateQuery(value interface{}) (interface{}, error) {
 func (v Array) Validate(value interface{}) (interface{}, error) {
 	values, ok := value.([]interface{})
 	if !ok {
-		return nil, errors.New("not an array")
+		return nil, NewValidationError("not_array", nil)
 	}
 	l := len(values)
 	if l < v.MinLen {
-		return nil, fmt.Errorf("has fewer items than %d", v.MinLen)
+		return nil, NewValidationError("too_few_items", map[string]interface{}{"min": v.MinLen})
 	}
 	if v.MaxLen > 0 && l > v.MaxLen {
-		return nil, fmt.Errorf("has more items than %d", v.MaxLen)
+		return nil, NewValidationError("too_many_items", map[string]interface{}{"max": v.MaxLen})
 	}
 	arr, err := v.validateValues(values, false)
 	if err != nil {
@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var decimalRe = regexp.MustCompile(`^([+-]?)(\d*)(?:\.(\d*))?$`)
+
+// Decimal validates arbitrary precision decimal numbers. Values are accepted as
+// strings to preserve their precision (numbers are accepted too, but are
+// subject to float64 rounding) and are stored as normalized strings: no leading
+// plus sign or zeros and, when Scale is set, exactly Scale fractional digits.
+type Decimal struct {
+	// Precision defines the maximum number of significant digits (default no
+	// limit).
+	Precision int
+	// Scale defines the maximum number of digits after the decimal point
+	// (default no limit). Values are padded to Scale fractional digits.
+	Scale int
+}
+
+// ValidateQuery implements FieldQueryValidator interface.
+func (v Decimal) ValidateQuery(value interface{}) (interface{}, error) {
+	sign, intPart, frac, err := parseDecimal(value)
+	if err != nil {
+		return nil, err
+	}
+	return v.format(sign, intPart, frac), nil
+}
+
+// Validate implements FieldValidator interface.
+func (v Decimal) Validate(value interface{}) (interface{}, error) {
+	sign, intPart, frac, err := parseDecimal(value)
+	if err != nil {
+		return nil, err
+	}
+	if v.Scale > 0 && len(frac) > v.Scale {
+		return nil, NewValidationError("decimal_scale", map[string]interface{}{"scale": v.Scale})
+	}
+	// Digits are counted on the stored value, once padded to Scale. Leading
+	// zeros are not significant: 0.001 has a single significant digit.
+	stored := frac
+	if len(stored) < v.Scale {
+		stored += strings.Repeat("0", v.Scale-len(stored))
+	}
+	digits := len(intPart) + len(stored)
+	if intPart == "0" {
+		digits = len(strings.TrimLeft(stored, "0"))
+	}
+	if v.Precision > 0 && digits > v.Precision {
+		return nil, NewValidationError("decimal_precision", map[string]interface{}{"precision": v.Precision})
+	}
+	return v.format(sign, intPart, frac), nil
+}
+
+func (v Decimal) format(sign, intPart, frac string) string {
+	if v.Scale > 0 && len(frac) < v.Scale {
+		frac += strings.Repeat("0", v.Scale-len(frac))
+	}
+	if frac != "" {
+		return sign + intPart + "." + frac
+	}
+	return sign + intPart
+}
+
+// parseDecimal splits value into its sign, integer part without leading zeros
+// and fractional part without trailing zeros.
+func parseDecimal(value interface{}) (sign, intPart, frac string, err error) {
+	var s string
+	switch t := value.(type) {
+	case string:
+		s = t
+	case float64:
+		s = strconv.FormatFloat(t, 'f', -1, 64)
+	case int:
+		s = strconv.Itoa(t)
+	default:
+		return "", "", "", NewValidationError("invalid_decimal", nil)
+	}
+	m := decimalRe.FindStringSubmatch(s)
+	if m == nil || m[2] == "" && m[3] == "" {
+		return "", "", "", NewValidationError("invalid_decimal", nil)
+	}
+	intPart = strings.TrimLeft(m[2], "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	frac = strings.TrimRight(m[3], "0")
+	if m[1] == "-" && (intPart != "0" || frac != "") {
+		sign = "-"
+	}
+	return sign, intPart, frac, nil
+}
+
+// LessFunc implements the FieldComparator interface.
+func (v Decimal) LessFunc() LessFunc {
+	return v.less
+}
+
+func (v Decimal) less(value, other interface{}) bool {
+	a, ok1 := value.(string)
+	b, ok2 := other.(string)
+	if !ok1 || !ok2 {
+		return false
+	}
+	x, ok1 := new(big.Rat).SetString(a)
+	y, ok2 := new(big.Rat).SetString(b)
+	if !ok1 || !ok2 {
+		return false
+	}
+	return x.Cmp(y) < 0
+}
@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimalValidator(t *testing.T) {
+	cases := []struct {
+		validator Decimal
+		input     interface{}
+		expect    interface{}
+		err       string
+	}{
+		{Decimal{}, "12.50", "12.5", ""},
+		{Decimal{}, "+0012", "12", ""},
+		{Decimal{}, "-0.000", "0", ""},
+		{Decimal{}, ".5", "0.5", ""},
+		{Decimal{}, "123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789", ""},
+		{Decimal{}, 1.25, "1.25", ""},
+		{Decimal{}, 3, "3", ""},
+		{Decimal{}, "1e3", nil, "not a decimal"},
+		{Decimal{}, ".", nil, "not a decimal"},
+		{Decimal{}, true, nil, "not a decimal"},
+		{Decimal{Scale: 2}, "1.5", "1.50", ""},
+		{Decimal{Scale: 2}, "1.500", "1.50", ""},
+		{Decimal{Scale: 2}, "1.505", nil, "has more than 2 digits after the decimal point"},
+		{Decimal{Precision: 4}, "12.34", "12.34", ""},
+		{Decimal{Precision: 4}, "0.1234", "0.1234", ""},
+		{Decimal{Precision: 4}, "123.45", nil, "has more than 4 digits"},
+		{Decimal{Precision: 1}, "0.001", "0.001", ""},
+		{Decimal{Precision: 1}, "0.0012", nil, "has more than 1 digits"},
+		{Decimal{Precision: 3, Scale: 2}, "12", nil, "has more than 3 digits"},
+		{Decimal{Precision: 3, Scale: 2}, "1", "1.00", ""},
+		{Decimal{Precision: 2, Scale: 3}, "0.01", "0.010", ""},
+	}
+	for _, tc := range cases {
+		v, err := tc.validator.Validate(tc.input)
+		if tc.err != "" {
+			assert.EqualError(t, err, tc.err, "%v", tc.input)
+		} else {
+			assert.NoError(t, err, "%v", tc.input)
+		}
+		assert.Equal(t, tc.expect, v, "%v", tc.input)
+	}
+}
+
+func TestDecimalQueryValidator(t *testing.T) {
+	v, err := Decimal{Scale: 1}.ValidateQuery("1.25")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.25", v)
+}
+
+func TestDecimalLessFunc(t *testing.T) {
+	less := Decimal{}.LessFunc()
+	assert.True(t, less("2", "10"))
+	assert.True(t, less("-10.5", "-10.25"))
+	assert.True(t, less("0.1000000000000000000001", "0.1000000000000000000002"))
+	assert.False(t, less("10", "2"))
+	assert.False(t, less("1", 2))
+}
@@ -1,10 +1,5 @@
 package schema
 
-import (
-	"errors"
-	"fmt"
-)
-
 // Dict validates objects with variadic keys.
 type Dict struct {
 	// KeysValidator is the validator to apply on dict keys.
@@ -39,34 +34,34 @@ func (v *Dict) Compile(rc ReferenceChecker) (err error) {
 func (v Dict) Validate(value interface{}) (interface{}, error) {
 	dict, ok := value.(map[string]interface{})
 	if !ok {
-		return nil, errors.New("not a dict")
+		return nil, NewValidationError("not_dict", nil)
 	}
 	dest := map[string]interface{}{}
 	for key, val := range dict {
 		if v.KeysValidator != nil {
 			nkey, err := v.KeysValidator.Validate(key)
 			if err != nil {
-				return nil, fmt.Errorf("invalid key `%s': %s", key, err)
+				return nil, NewValidationError("invalid_key", map[string]interface{}{"key": key, "error": err})
 			}
 			if key, ok = nkey.(string); !ok {
-				return nil, errors.New("key validator does not return string")
+				return nil, NewValidationError("invalid_key_type", nil)
 			}
 		}
 		if v.Values.Validator != nil {
 			var err error
 			val, err = v.Values.Validator.Validate(val)
 			if err != nil {
-				return nil, fmt.Errorf("invalid value for key `%s': %s", key, err)
+				return nil, NewValidationError("invalid_key_value", map[string]interface{}{"key": key, "error": err})
 			}
 		}
 		dest[key] = val
 	}
 	l := len(dest)
 	if l < v.MinLen {
-		return nil, fmt.Errorf("has fewer properties than %d", v.MinLen)
+		return nil, NewValidationError("too_few_properties", map[string]interface{}{"min": v.MinLen})
 	}
 	if v.MaxLen > 0 && l > v.MaxLen {
-		return nil, fmt.Errorf("has more properties than %d", v.MaxLen)
+		return nil, NewValidationError("too_many_properties", map[string]interface{}{"max": v.MaxLen})
 	}
 	return dest, nil
 }
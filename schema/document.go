@@ -0,0 +1,54 @@
+package schema
+
+import "context"
+
+// DocumentValidator validates a document as a whole, once all its fields have
+// been individually validated. It is the place for rules involving several
+// fields, like "end must be after start".
+type DocumentValidator interface {
+	// ValidateDocument is called with the changes and base passed to
+	// Validate as well as the resulting doc, normalized by the field
+	// validators. Returned ErrorMap errors are merged into the validation
+	// errors, so they can be scoped to fields. Other errors are reported on
+	// the document itself (the empty field name).
+	ValidateDocument(ctx context.Context, changes, base, doc map[string]interface{}) error
+}
+
+// DocumentValidatorFunc is an adapter to allow the use of ordinary functions
+// as document validators.
+type DocumentValidatorFunc func(ctx context.Context, changes, base, doc map[string]interface{}) error
+
+// ValidateDocument calls f(ctx, changes, base, doc).
+func (f DocumentValidatorFunc) ValidateDocument(ctx context.Context, changes, base, doc map[string]interface{}) error {
+	return f(ctx, changes, base, doc)
+}
+
+// ContextValidator is an optional interface for Validator implementations
+// accepting a context on validation. The context is passed down to the
+// DocumentValidator instances of the schema.
+type ContextValidator interface {
+	ValidateContext(ctx context.Context, changes map[string]interface{}, base map[string]interface{}) (doc map[string]interface{}, errs map[string][]interface{})
+}
+
+// ValidateContext validates changes applied on base with v, passing ctx along
+// if v implements ContextValidator.
+func ValidateContext(ctx context.Context, v Validator, changes map[string]interface{}, base map[string]interface{}) (doc map[string]interface{}, errs map[string][]interface{}) {
+	if cv, ok := v.(ContextValidator); ok {
+		return cv.ValidateContext(ctx, changes, base)
+	}
+	return v.Validate(changes, base)
+}
+
+// validateDocument runs the schema's document validators on doc and adds the
+// reported errors to errs.
+func (s Schema) validateDocument(ctx context.Context, changes, base, doc map[string]interface{}, errs map[string][]interface{}) {
+	for _, v := range s.Validators {
+		switch err := v.ValidateDocument(ctx, changes, base, doc).(type) {
+		case nil:
+		case ErrorMap:
+			mergeFieldErrors(errs, err)
+		default:
+			addFieldError(errs, "", fieldError(err))
+		}
+	}
+}
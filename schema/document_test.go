@@ -0,0 +1,117 @@
+package schema_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey struct{}
+
+func TestSchemaDocumentValidators(t *testing.T) {
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"start": {Validator: &schema.Integer{}},
+			"end":   {Validator: &schema.Integer{}},
+			"plan":  {Validator: &schema.String{}},
+			"discount": {
+				Validator: &schema.Float{},
+			},
+			"sub": {
+				Schema: &schema.Schema{
+					Fields: schema.Fields{
+						"a": {Validator: &schema.Integer{}},
+					},
+					Validators: []schema.DocumentValidator{
+						schema.DocumentValidatorFunc(func(ctx context.Context, changes, base, doc map[string]interface{}) error {
+							if doc["a"] == 0 {
+								return errors.New("a must not be 0")
+							}
+							return nil
+						}),
+					},
+				},
+			},
+		},
+		Validators: []schema.DocumentValidator{
+			schema.DocumentValidatorFunc(func(ctx context.Context, changes, base, doc map[string]interface{}) error {
+				start, _ := doc["start"].(int)
+				end, _ := doc["end"].(int)
+				if end < start {
+					return schema.ErrorMap{"end": {"must be greater than start"}}
+				}
+				return nil
+			}),
+			schema.DocumentValidatorFunc(func(ctx context.Context, changes, base, doc map[string]interface{}) error {
+				if _, found := doc["discount"]; found && doc["plan"] != "pro" {
+					return schema.ErrorMap{"discount": {"only allowed with the pro plan"}}
+				}
+				return nil
+			}),
+			schema.DocumentValidatorFunc(func(ctx context.Context, changes, base, doc map[string]interface{}) error {
+				if v := ctx.Value(ctxKey{}); v != nil {
+					return errors.New(v.(string))
+				}
+				return nil
+			}),
+		},
+	}
+	assert.NoError(t, s.Compile(nil))
+
+	cases := []struct {
+		name    string
+		ctx     context.Context
+		changes map[string]interface{}
+		base    map[string]interface{}
+		errs    map[string][]interface{}
+	}{
+		{
+			name:    "Valid",
+			ctx:     context.Background(),
+			changes: map[string]interface{}{"start": 1, "end": 2, "plan": "pro", "discount": 0.1},
+			errs:    map[string][]interface{}{},
+		},
+		{
+			name:    "CrossField",
+			ctx:     context.Background(),
+			changes: map[string]interface{}{"start": 1.0, "end": 0.0, "discount": 0.1},
+			base:    map[string]interface{}{"plan": "free"},
+			errs: map[string][]interface{}{
+				"end":      {"must be greater than start"},
+				"discount": {"only allowed with the pro plan"},
+			},
+		},
+		{
+			name:    "Context",
+			ctx:     context.WithValue(context.Background(), ctxKey{}, "rejected"),
+			changes: map[string]interface{}{},
+			errs:    map[string][]interface{}{"": {"rejected"}},
+		},
+		{
+			name:    "SubSchema",
+			ctx:     context.Background(),
+			changes: map[string]interface{}{"sub": map[string]interface{}{"a": 0}},
+			errs:    map[string][]interface{}{"sub": {map[string][]interface{}{"": {"a must not be 0"}}}},
+		},
+		{
+			name:    "FieldErrorsFirst",
+			ctx:     context.Background(),
+			changes: map[string]interface{}{"start": "a", "end": 0},
+			errs:    map[string][]interface{}{"start": {schema.NewValidationError("not_integer", nil)}},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			base := tc.base
+			if base == nil {
+				base = map[string]interface{}{}
+			}
+			_, errs := s.ValidateContext(tc.ctx, tc.changes, base)
+			assert.Equal(t, tc.errs, errs)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var isoDurationRe = regexp.MustCompile(`^(-)?P(?:(\d+(?:\.\d+)?)W)?(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// Duration validates durations given either in the ISO 8601 format (i.e.:
+// "PT1H30M") or in the Go format (i.e.: "1h30m"). Years and months are not
+// accepted as their duration varies. The value is stored as a time.Duration and
+// serialized in the ISO 8601 format.
+type Duration struct {
+	// Min defines the minimum duration (default no limit).
+	Min time.Duration
+	// Max defines the maximum duration (default no limit).
+	Max time.Duration
+}
+
+// ValidateQuery implements FieldQueryValidator interface.
+func (v Duration) ValidateQuery(value interface{}) (interface{}, error) {
+	return v.parse(value)
+}
+
+// Validate implements FieldValidator interface.
+func (v Duration) Validate(value interface{}) (interface{}, error) {
+	d, err := v.parse(value)
+	if err != nil {
+		return nil, err
+	}
+	if v.Min != 0 && d < v.Min {
+		return nil, NewValidationError("duration_too_short", map[string]interface{}{"min": formatISODuration(v.Min)})
+	}
+	if v.Max != 0 && d > v.Max {
+		return nil, NewValidationError("duration_too_long", map[string]interface{}{"max": formatISODuration(v.Max)})
+	}
+	return d, nil
+}
+
+// Serialize implements FieldSerializer.
+func (v Duration) Serialize(value interface{}) (interface{}, error) {
+	d, ok := value.(time.Duration)
+	if !ok {
+		return nil, NewValidationError("invalid_type", nil)
+	}
+	return formatISODuration(d), nil
+}
+
+func (v Duration) parse(value interface{}) (time.Duration, error) {
+	switch t := value.(type) {
+	case time.Duration:
+		return t, nil
+	case string:
+		if d, ok := parseISODuration(t); ok {
+			return d, nil
+		}
+		if d, err := time.ParseDuration(t); err == nil {
+			return d, nil
+		}
+	}
+	return 0, NewValidationError("invalid_duration", nil)
+}
+
+// LessFunc implements the FieldComparator interface.
+func (v Duration) LessFunc() LessFunc {
+	return v.less
+}
+
+func (v Duration) less(value, other interface{}) bool {
+	d, ok1 := value.(time.Duration)
+	o, ok2 := other.(time.Duration)
+	if !ok1 || !ok2 {
+		return false
+	}
+	return d < o
+}
+
+func parseISODuration(s string) (time.Duration, bool) {
+	m := isoDurationRe.FindStringSubmatch(s)
+	if m == nil || s == "P" || s == "-P" || strings.HasSuffix(s, "T") {
+		return 0, false
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var total float64
+	empty := true
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		empty = false
+		f, err := strconv.ParseFloat(m[i+2], 64)
+		if err != nil {
+			return 0, false
+		}
+		total += f * float64(unit)
+	}
+	if empty || total > math.MaxInt64 {
+		return 0, false
+	}
+	if m[1] == "-" {
+		total = -total
+	}
+	return time.Duration(math.Round(total)), true
+}
+
+func formatISODuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteString("PT")
+	if h := d / time.Hour; h > 0 {
+		b.WriteString(strconv.FormatInt(int64(h), 10) + "H")
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		b.WriteString(strconv.FormatInt(int64(m), 10) + "M")
+		d -= m * time.Minute
+	}
+	if d > 0 {
+		b.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S")
+	}
+	return b.String()
+}
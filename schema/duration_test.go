@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationValidator(t *testing.T) {
+	cases := []struct {
+		validator Duration
+		input     interface{}
+		expect    interface{}
+		err       string
+	}{
+		{Duration{}, "PT1H30M", 90 * time.Minute, ""},
+		{Duration{}, "P1DT0.5S", 24*time.Hour + 500*time.Millisecond, ""},
+		{Duration{}, "P1W", 7 * 24 * time.Hour, ""},
+		{Duration{}, "-PT2M", -2 * time.Minute, ""},
+		{Duration{}, "1h30m", 90 * time.Minute, ""},
+		{Duration{}, time.Second, time.Second, ""},
+		{Duration{}, "P1Y", nil, "not a duration"},
+		{Duration{}, "P", nil, "not a duration"},
+		{Duration{}, "PT", nil, "not a duration"},
+		{Duration{}, 10.0, nil, "not a duration"},
+		{Duration{Min: time.Minute}, "PT30S", nil, "is shorter than PT1M"},
+		{Duration{Max: time.Hour}, "PT2H", nil, "is longer than PT1H"},
+		{Duration{Min: time.Minute, Max: time.Hour}, "PT30M", 30 * time.Minute, ""},
+	}
+	for _, tc := range cases {
+		v, err := tc.validator.Validate(tc.input)
+		if tc.err != "" {
+			assert.EqualError(t, err, tc.err, "%v", tc.input)
+		} else {
+			assert.NoError(t, err, "%v", tc.input)
+		}
+		assert.Equal(t, tc.expect, v, "%v", tc.input)
+	}
+}
+
+func TestDurationValidatorSerialize(t *testing.T) {
+	for d, expect := range map[time.Duration]string{
+		0:                                    "PT0S",
+		90 * time.Minute:                     "PT1H30M",
+		36*time.Hour + 1500*time.Millisecond: "PT36H1.5S",
+		-time.Second:                         "-PT1S",
+	} {
+		v, err := Duration{}.Serialize(d)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, v)
+	}
+	v, err := Duration{}.Serialize("PT1S")
+	assert.EqualError(t, err, "invalid type")
+	assert.Nil(t, v)
+}
+
+func TestDurationLessFunc(t *testing.T) {
+	less := Duration{}.LessFunc()
+	assert.True(t, less(time.Second, time.Minute))
+	assert.False(t, less(time.Minute, time.Second))
+	assert.False(t, less("1s", time.Minute))
+}
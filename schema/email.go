@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// Email validates e-mail addresses. The domain part of the address is
+// normalized to lower case.
+type Email struct {
+	// AllowedDomains restricts the addresses to the given domains if not
+	// empty.
+	AllowedDomains []string
+}
+
+// ValidateQuery implements FieldQueryValidator interface.
+func (v Email) ValidateQuery(value interface{}) (interface{}, error) {
+	return v.parse(value)
+}
+
+// Validate implements FieldValidator interface.
+func (v Email) Validate(value interface{}) (interface{}, error) {
+	addr, err := v.parse(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(v.AllowedDomains) > 0 {
+		domain := addr[strings.LastIndexByte(addr, '@')+1:]
+		found := false
+		for _, allowed := range v.AllowedDomains {
+			if strings.EqualFold(domain, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, NewValidationError("domain_not_allowed", map[string]interface{}{"allowed": v.AllowedDomains})
+		}
+	}
+	return addr, nil
+}
+
+func (v Email) parse(value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", NewValidationError("not_string", nil)
+	}
+	// Only accept bare addresses, without display name or comments.
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Name != "" || addr.Address != s {
+		return "", NewValidationError("invalid_email", nil)
+	}
+	at := strings.LastIndexByte(s, '@')
+	if at < 1 || at == len(s)-1 {
+		return "", NewValidationError("invalid_email", nil)
+	}
+	return s[:at+1] + strings.ToLower(s[at+1:]), nil
+}
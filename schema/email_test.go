@@ -0,0 +1,33 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailValidator(t *testing.T) {
+	v, err := Email{}.Validate("John.Doe@Example.COM")
+	assert.NoError(t, err)
+	assert.Equal(t, "John.Doe@example.com", v)
+	v, err = Email{}.Validate(1)
+	assert.EqualError(t, err, "not a string")
+	assert.Nil(t, v)
+	for _, invalid := range []string{"john", "john@", "@example.com", "John <john@example.com>", "john@example.com (John)"} {
+		v, err = Email{}.Validate(invalid)
+		assert.EqualError(t, err, "invalid e-mail address", invalid)
+		assert.Nil(t, v)
+	}
+	v, err = Email{AllowedDomains: []string{"example.com"}}.Validate("john@EXAMPLE.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "john@example.com", v)
+	v, err = Email{AllowedDomains: []string{"example.com", "example.org"}}.Validate("john@example.net")
+	assert.EqualError(t, err, "domain not one of [example.com, example.org]")
+	assert.Nil(t, v)
+}
+
+func TestEmailQueryValidator(t *testing.T) {
+	v, err := Email{AllowedDomains: []string{"example.com"}}.ValidateQuery("john@Example.net")
+	assert.NoError(t, err)
+	assert.Equal(t, "john@example.net", v)
+}
@@ -3,6 +3,7 @@
 package jsonschema_test
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -66,6 +67,29 @@ func TestEncoder(t *testing.T) {
 				}
 			}`,
 		},
+		{
+			name: "Compute!=nil",
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"name": {
+						Validator: &schema.String{},
+						Compute: func(ctx context.Context, doc map[string]interface{}) (interface{}, error) {
+							return "name", nil
+						},
+					},
+				},
+			},
+			expect: `{
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {
+					"name": {
+						"type": "string",
+						"readOnly": true
+					}
+				}
+			}`,
+		},
 		{
 			name: `Validator=String,type(Default)==string`,
 			schema: schema.Schema{
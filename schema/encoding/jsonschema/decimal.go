@@ -0,0 +1,12 @@
+package jsonschema
+
+import "github.com/entropyinf/rest-layer/schema"
+
+type decimalBuilder schema.Decimal
+
+func (v decimalBuilder) BuildJSONSchema() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"type":   "string",
+		"format": "decimal",
+	}, nil
+}
@@ -0,0 +1,664 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+var (
+	// ErrUnsupportedKeyword is returned by the Decoder when a JSON Schema
+	// keyword has no REST Layer equivalent.
+	ErrUnsupportedKeyword = errors.New("unsupported keyword")
+	// ErrInvalidKeyword is returned by the Decoder when a JSON Schema keyword
+	// value is not of the expected type.
+	ErrInvalidKeyword = errors.New("invalid keyword")
+)
+
+// annotations lists keywords having no effect on validation, ignored by the
+// Decoder.
+var annotations = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"$defs":       true,
+	"definitions": true,
+	"title":       true,
+	"examples":    true,
+	"deprecated":  true,
+	"writeOnly":   true,
+}
+
+// typeKeywords lists the validation keywords supported by each type.
+var typeKeywords = map[string]map[string]bool{
+	"string":  {"format": true, "pattern": true, "enum": true, "minLength": true, "maxLength": true},
+	"integer": {"enum": true, "minimum": true, "maximum": true},
+	"number":  {"enum": true, "minimum": true, "maximum": true},
+	"array":   {"items": true, "minItems": true, "maxItems": true},
+	"object": {
+		"properties": true, "required": true, "additionalProperties": true,
+		"patternProperties": true, "minProperties": true, "maxProperties": true,
+	},
+}
+
+// Decoder reads a JSON Schema (draft 2020-12) document from an input stream and
+// converts it into a schema.Schema. Only the keywords having a REST Layer
+// equivalent are supported; other keywords result in an ErrUnsupportedKeyword
+// error. Local references to definitions ($ref to #/$defs/... or
+// #/definitions/...) are inlined.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder returns a new JSON Schema Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads the next JSON Schema document from the stream and stores its
+// schema.Schema representation in s.
+func (d *Decoder) Decode(s *schema.Schema) error {
+	var m map[string]interface{}
+	if err := json.NewDecoder(d.r).Decode(&m); err != nil {
+		return err
+	}
+	dec := &decoder{root: m, visiting: map[string]bool{"#": true}}
+	m, err := dec.deref("", m)
+	if err != nil {
+		return err
+	}
+	sub, err := dec.decodeSchema("", m)
+	if err != nil {
+		return err
+	}
+	*s = *sub
+	return nil
+}
+
+// decoder holds the state of a decoding operation.
+type decoder struct {
+	root     map[string]interface{}
+	visiting map[string]bool
+}
+
+// keywords returns the sorted keys of m so errors are deterministic.
+func keywords(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func unsupported(path, keyword string) error {
+	return fmt.Errorf("%s/%s: %w", path, keyword, ErrUnsupportedKeyword)
+}
+
+func invalid(path, keyword string) error {
+	return fmt.Errorf("%s/%s: %w", path, keyword, ErrInvalidKeyword)
+}
+
+// deref returns the definition referenced by m's $ref keyword merged with m's
+// other keywords, or m if it has no $ref.
+func (d *decoder) deref(path string, m map[string]interface{}) (map[string]interface{}, error) {
+	ref, found := m["$ref"]
+	if !found {
+		return m, nil
+	}
+	r, ok := ref.(string)
+	if !ok {
+		return nil, invalid(path, "$ref")
+	}
+	var def interface{} = d.root
+	if r != "#" {
+		if !strings.HasPrefix(r, "#/") {
+			return nil, fmt.Errorf("%s/$ref: non local reference %s: %w", path, r, ErrUnsupportedKeyword)
+		}
+		for _, tok := range strings.Split(r[2:], "/") {
+			tok = strings.NewReplacer("~1", "/", "~0", "~").Replace(tok)
+			parent, ok := def.(map[string]interface{})
+			if !ok {
+				def = nil
+				break
+			}
+			def = parent[tok]
+		}
+	}
+	target, ok := def.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s/$ref: %s not found: %w", path, r, ErrInvalidKeyword)
+	}
+	if _, chained := target["$ref"]; chained {
+		return nil, fmt.Errorf("%s/$ref: chained reference %s: %w", path, r, ErrUnsupportedKeyword)
+	}
+	merged := make(map[string]interface{}, len(target)+len(m))
+	for k, v := range target {
+		merged[k] = v
+	}
+	for k, v := range m {
+		if k != "$ref" {
+			merged[k] = v
+		}
+	}
+	return merged, nil
+}
+
+// decodeSchema decodes an object JSON Schema into a schema.Schema.
+func (d *decoder) decodeSchema(path string, m map[string]interface{}) (*schema.Schema, error) {
+	s := &schema.Schema{Fields: schema.Fields{}}
+	required := map[string]bool{}
+	for _, kw := range keywords(m) {
+		v := m[kw]
+		var ok bool
+		switch kw {
+		case "type":
+			ok = v == "object"
+		case "description":
+			s.Description, ok = v.(string)
+		case "additionalProperties":
+			// Schemas never accept additional properties.
+			ok = v == false
+		case "minProperties":
+			s.MinLen, ok = toInt(v)
+		case "maxProperties":
+			s.MaxLen, ok = toInt(v)
+		case "required":
+			var names []interface{}
+			names, ok = v.([]interface{})
+			for _, n := range names {
+				name, isString := n.(string)
+				if !isString {
+					ok = false
+				}
+				required[name] = true
+			}
+		case "properties":
+			var props map[string]interface{}
+			if props, ok = v.(map[string]interface{}); !ok {
+				break
+			}
+			for _, name := range keywords(props) {
+				pm, isMap := props[name].(map[string]interface{})
+				if !isMap {
+					return nil, invalid(path+"/properties", name)
+				}
+				f, err := d.decodeField(path+"/properties/"+name, pm)
+				if err != nil {
+					return nil, err
+				}
+				s.Fields[name] = f
+			}
+		default:
+			if annotations[kw] {
+				continue
+			}
+			return nil, unsupported(path, kw)
+		}
+		if !ok {
+			return nil, invalid(path, kw)
+		}
+	}
+	for name := range required {
+		f, found := s.Fields[name]
+		if !found {
+			return nil, fmt.Errorf("%s/required: unknown property %s: %w", path, name, ErrInvalidKeyword)
+		}
+		f.Required = true
+		s.Fields[name] = f
+	}
+	return s, nil
+}
+
+// decodeField decodes a property JSON Schema into a schema.Field.
+func (d *decoder) decodeField(path string, m map[string]interface{}) (schema.Field, error) {
+	if r, ok := m["$ref"].(string); ok {
+		// Schemas are decoded into a tree, recursive references can thus not
+		// be represented.
+		if d.visiting[r] {
+			return schema.Field{}, fmt.Errorf("%s/$ref: recursive reference %s: %w", path, r, ErrUnsupportedKeyword)
+		}
+		d.visiting[r] = true
+		defer func() { d.visiting[r] = false }()
+	}
+	m, err := d.deref(path, m)
+	if err != nil {
+		return schema.Field{}, err
+	}
+	f := schema.Field{}
+	rest := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		var ok bool
+		switch k {
+		case "description":
+			f.Description, ok = v.(string)
+		case "readOnly":
+			f.ReadOnly, ok = v.(bool)
+		case "default":
+			f.Default, ok = v, true
+		default:
+			rest[k] = v
+			continue
+		}
+		if !ok {
+			return f, invalid(path, k)
+		}
+	}
+	f.Validator, err = d.decodeValidator(path, rest)
+	if err != nil {
+		return f, err
+	}
+	if f.Default != nil {
+		// JSON numbers are decoded as float64, restore integer defaults.
+		if _, isInt := f.Validator.(*schema.Integer); isInt {
+			if i, ok := toInt(f.Default); ok {
+				f.Default = i
+			}
+		}
+	}
+	return f, nil
+}
+
+// decodeValidator decodes a JSON Schema into a field validator. A nil validator
+// is returned for schemas accepting any value.
+func (d *decoder) decodeValidator(path string, m map[string]interface{}) (schema.FieldValidator, error) {
+	for _, kw := range []string{"oneOf", "anyOf", "allOf"} {
+		if _, found := m[kw]; !found {
+			continue
+		}
+		if isIP(m) {
+			return &schema.IP{}, nil
+		}
+		vs, err := d.decodeValidators(path, kw, m)
+		if err != nil {
+			return nil, err
+		}
+		// Members accepting any value (nil validators) are dropped from an
+		// allOf, and make an anyOf accept any value.
+		nonNil := make([]schema.FieldValidator, 0, len(vs))
+		for _, v := range vs {
+			if v != nil {
+				nonNil = append(nonNil, v)
+			} else if kw != "allOf" {
+				return nil, nil
+			}
+		}
+		if len(nonNil) == 0 {
+			return nil, nil
+		}
+		if kw == "allOf" {
+			v := schema.AllOf(nonNil)
+			return &v, nil
+		}
+		v := schema.AnyOf(nonNil)
+		return &v, nil
+	}
+	t, found := m["type"]
+	if !found {
+		if _, found := m["properties"]; found {
+			t, found = "object", true
+		}
+	}
+	if !found {
+		for _, kw := range keywords(m) {
+			if !annotations[kw] {
+				return nil, fmt.Errorf("%s: %w: missing type", path, ErrUnsupportedKeyword)
+			}
+		}
+		return nil, nil
+	}
+	if types, ok := t.([]interface{}); ok {
+		// A list of types is equivalent to an anyOf of each type, each one
+		// only getting the keywords applying to it.
+		vs := make(schema.AnyOf, 0, len(types))
+		used := map[string]bool{}
+		for _, typ := range types {
+			name, _ := typ.(string)
+			sub := map[string]interface{}{"type": typ}
+			for k, v := range m {
+				if annotations[k] || typeKeywords[name][k] {
+					sub[k] = v
+					used[k] = true
+				}
+			}
+			v, err := d.decodeValidator(path, sub)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		}
+		for _, kw := range keywords(m) {
+			if kw != "type" && !used[kw] {
+				return nil, unsupported(path, kw)
+			}
+		}
+		return &vs, nil
+	}
+	switch t {
+	case "string":
+		return d.decodeString(path, m)
+	case "integer":
+		return d.decodeInteger(path, m)
+	case "number":
+		return d.decodeNumber(path, m)
+	case "boolean":
+		return &schema.Bool{}, checkKeywords(path, m)
+	case "null":
+		return &schema.Null{}, checkKeywords(path, m)
+	case "array":
+		return d.decodeArray(path, m)
+	case "object":
+		if _, found := m["properties"]; found || m["additionalProperties"] == false && m["patternProperties"] == nil {
+			s, err := d.decodeSchema(path, m)
+			if err != nil {
+				return nil, err
+			}
+			return &schema.Object{Schema: s}, nil
+		}
+		return d.decodeDict(path, m)
+	default:
+		return nil, invalid(path, "type")
+	}
+}
+
+func (d *decoder) decodeValidators(path, kw string, m map[string]interface{}) ([]schema.FieldValidator, error) {
+	list, ok := m[kw].([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, invalid(path, kw)
+	}
+	for k := range m {
+		if k != kw && !annotations[k] {
+			return nil, fmt.Errorf("%s/%s: cannot be combined with %s: %w", path, k, kw, ErrUnsupportedKeyword)
+		}
+	}
+	vs := make([]schema.FieldValidator, 0, len(list))
+	for i, item := range list {
+		im, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, invalid(path, fmt.Sprintf("%s/%d", kw, i))
+		}
+		f, err := d.decodeField(fmt.Sprintf("%s/%s/%d", path, kw, i), im)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, f.Validator)
+	}
+	return vs, nil
+}
+
+// isIP returns true if m is the representation of a schema.IP validator.
+func isIP(m map[string]interface{}) bool {
+	if m["type"] != "string" || len(m) != 2 {
+		return false
+	}
+	list, ok := m["oneOf"].([]interface{})
+	if !ok || len(list) != 2 {
+		return false
+	}
+	formats := map[interface{}]bool{}
+	for _, item := range list {
+		if im, ok := item.(map[string]interface{}); ok && len(im) == 1 {
+			formats[im["format"]] = true
+		}
+	}
+	return formats["ipv4"] && formats["ipv6"]
+}
+
+// checkKeywords returns an error if m has keywords other than type and
+// annotations.
+func checkKeywords(path string, m map[string]interface{}, allowed ...string) error {
+	for _, kw := range keywords(m) {
+		if kw == "type" || annotations[kw] {
+			continue
+		}
+		found := false
+		for _, a := range allowed {
+			if kw == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return unsupported(path, kw)
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decodeString(path string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if format, found := m["format"]; found {
+		switch format {
+		case "date-time":
+			return &schema.Time{}, checkKeywords(path, m, "format")
+		case "uri":
+			return &schema.URL{}, checkKeywords(path, m, "format")
+		case "ipv4", "ipv6":
+			return &schema.IP{}, checkKeywords(path, m, "format")
+		case "email":
+			return &schema.Email{}, checkKeywords(path, m, "format")
+		case "uuid":
+			return &schema.UUID{}, checkKeywords(path, m, "format")
+		case "decimal":
+			return &schema.Decimal{}, checkKeywords(path, m, "format")
+		case "duration":
+			return &schema.Duration{}, checkKeywords(path, m, "format")
+		case "password":
+			v := &schema.Password{}
+			err := decodeLengths(path, m, &v.MinLen, &v.MaxLen)
+			if err == nil {
+				err = checkKeywords(path, m, "format", "minLength", "maxLength")
+			}
+			return v, err
+		default:
+			return nil, fmt.Errorf("%s/format: %w: %v", path, ErrUnsupportedKeyword, format)
+		}
+	}
+	if err := checkKeywords(path, m, "pattern", "enum", "minLength", "maxLength"); err != nil {
+		return nil, err
+	}
+	v := &schema.String{}
+	if p, found := m["pattern"]; found {
+		var ok bool
+		if v.Regexp, ok = p.(string); !ok {
+			return nil, invalid(path, "pattern")
+		}
+		if _, err := regexp.Compile(v.Regexp); err != nil {
+			return nil, fmt.Errorf("%s/pattern: %w: %v", path, ErrInvalidKeyword, err)
+		}
+	}
+	if e, found := m["enum"]; found {
+		list, ok := e.([]interface{})
+		if !ok {
+			return nil, invalid(path, "enum")
+		}
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, invalid(path, "enum")
+			}
+			v.Allowed = append(v.Allowed, s)
+		}
+	}
+	return v, decodeLengths(path, m, &v.MinLen, &v.MaxLen)
+}
+
+// decodeLengths decodes the minLength and maxLength keywords of m.
+func decodeLengths(path string, m map[string]interface{}, min, max *int) error {
+	var ok bool
+	if v, found := m["minLength"]; found {
+		if *min, ok = toInt(v); !ok {
+			return invalid(path, "minLength")
+		}
+	}
+	if v, found := m["maxLength"]; found {
+		if *max, ok = toInt(v); !ok {
+			return invalid(path, "maxLength")
+		}
+	}
+	return nil
+}
+
+// decodeBoundaries decodes the minimum and maximum keywords of m.
+func decodeBoundaries(path string, m map[string]interface{}) (*schema.Boundaries, error) {
+	min, hasMin := m["minimum"]
+	max, hasMax := m["maximum"]
+	if !hasMin && !hasMax {
+		return nil, nil
+	}
+	b := &schema.Boundaries{Min: math.Inf(-1), Max: math.Inf(1)}
+	var ok bool
+	if hasMin {
+		if b.Min, ok = min.(float64); !ok {
+			return nil, invalid(path, "minimum")
+		}
+	}
+	if hasMax {
+		if b.Max, ok = max.(float64); !ok {
+			return nil, invalid(path, "maximum")
+		}
+	}
+	return b, nil
+}
+
+func (d *decoder) decodeInteger(path string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if err := checkKeywords(path, m, "enum", "minimum", "maximum"); err != nil {
+		return nil, err
+	}
+	v := &schema.Integer{}
+	if e, found := m["enum"]; found {
+		list, ok := e.([]interface{})
+		if !ok {
+			return nil, invalid(path, "enum")
+		}
+		for _, item := range list {
+			i, ok := toInt(item)
+			if !ok {
+				return nil, invalid(path, "enum")
+			}
+			v.Allowed = append(v.Allowed, i)
+		}
+	}
+	var err error
+	v.Boundaries, err = decodeBoundaries(path, m)
+	return v, err
+}
+
+func (d *decoder) decodeNumber(path string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if err := checkKeywords(path, m, "enum", "minimum", "maximum"); err != nil {
+		return nil, err
+	}
+	v := &schema.Float{}
+	if e, found := m["enum"]; found {
+		list, ok := e.([]interface{})
+		if !ok {
+			return nil, invalid(path, "enum")
+		}
+		for _, item := range list {
+			f, ok := item.(float64)
+			if !ok {
+				return nil, invalid(path, "enum")
+			}
+			v.Allowed = append(v.Allowed, f)
+		}
+	}
+	var err error
+	v.Boundaries, err = decodeBoundaries(path, m)
+	return v, err
+}
+
+func (d *decoder) decodeArray(path string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if err := checkKeywords(path, m, "items", "minItems", "maxItems"); err != nil {
+		return nil, err
+	}
+	v := &schema.Array{}
+	var ok bool
+	if i, found := m["minItems"]; found {
+		if v.MinLen, ok = toInt(i); !ok {
+			return nil, invalid(path, "minItems")
+		}
+	}
+	if i, found := m["maxItems"]; found {
+		if v.MaxLen, ok = toInt(i); !ok {
+			return nil, invalid(path, "maxItems")
+		}
+	}
+	if items, found := m["items"]; found {
+		im, ok := items.(map[string]interface{})
+		if !ok {
+			return nil, invalid(path, "items")
+		}
+		var err error
+		if v.Values, err = d.decodeField(path+"/items", im); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (d *decoder) decodeDict(path string, m map[string]interface{}) (schema.FieldValidator, error) {
+	if err := checkKeywords(path, m, "additionalProperties", "patternProperties", "minProperties", "maxProperties"); err != nil {
+		return nil, err
+	}
+	v := &schema.Dict{}
+	var ok bool
+	if i, found := m["minProperties"]; found {
+		if v.MinLen, ok = toInt(i); !ok {
+			return nil, invalid(path, "minProperties")
+		}
+	}
+	if i, found := m["maxProperties"]; found {
+		if v.MaxLen, ok = toInt(i); !ok {
+			return nil, invalid(path, "maxProperties")
+		}
+	}
+	var values map[string]interface{}
+	if pp, found := m["patternProperties"]; found {
+		patterns, ok := pp.(map[string]interface{})
+		if !ok || len(patterns) != 1 || m["additionalProperties"] != false {
+			return nil, fmt.Errorf("%s/patternProperties: %w: only a single pattern without additional properties is supported", path, ErrUnsupportedKeyword)
+		}
+		for pattern, vm := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("%s/patternProperties: %w: %v", path, ErrInvalidKeyword, err)
+			}
+			v.KeysValidator = &schema.String{Regexp: pattern}
+			if values, ok = vm.(map[string]interface{}); !ok {
+				return nil, invalid(path, "patternProperties")
+			}
+		}
+	} else if ap, found := m["additionalProperties"]; found {
+		switch ap := ap.(type) {
+		case bool:
+			if !ap {
+				return nil, invalid(path, "additionalProperties")
+			}
+		case map[string]interface{}:
+			values = ap
+		default:
+			return nil, invalid(path, "additionalProperties")
+		}
+	}
+	if values != nil {
+		var err error
+		if v.Values, err = d.decodeField(path+"/additionalProperties", values); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// toInt converts a JSON number into an int if it has no fractional part.
+func toInt(v interface{}) (int, bool) {
+	f, ok := v.(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int(f), true
+}
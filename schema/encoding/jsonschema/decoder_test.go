@@ -0,0 +1,176 @@
+package jsonschema_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/entropyinf/rest-layer/schema/encoding/jsonschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name   string
+		schema schema.Schema
+	}{
+		{"String", schema.Schema{Fields: schema.Fields{
+			"s": {Required: true, Description: "a string", Default: "x", Validator: &schema.String{Regexp: "^x", MinLen: 1, MaxLen: 5}},
+			"e": {Validator: &schema.String{Allowed: []string{"a", "b"}}},
+		}}},
+		{"Numbers", schema.Schema{Fields: schema.Fields{
+			"i": {ReadOnly: true, Default: 2, Validator: &schema.Integer{Allowed: []int{1, 2}, Boundaries: &schema.Boundaries{Min: 0, Max: 10}}},
+			"f": {Validator: &schema.Float{Allowed: []float64{1.5}, Boundaries: &schema.Boundaries{Min: math.Inf(-1), Max: 10}}},
+		}}},
+		{"Formats", schema.Schema{Fields: schema.Fields{
+			"t":  {Validator: &schema.Time{}},
+			"u":  {Validator: &schema.URL{}},
+			"ip": {Validator: &schema.IP{}},
+			"p":  {Validator: &schema.Password{MinLen: 8}},
+			"b":  {Validator: &schema.Bool{}},
+			"n":  {Validator: &schema.Null{}},
+			"em": {Validator: &schema.Email{}},
+			"id": {Validator: &schema.UUID{}},
+			"dc": {Validator: &schema.Decimal{}},
+			"du": {Validator: &schema.Duration{}},
+		}}},
+		{"Containers", schema.Schema{MinLen: 1, MaxLen: 10, Fields: schema.Fields{
+			"a": {Validator: &schema.Array{MinLen: 1, MaxLen: 3, Values: schema.Field{Validator: &schema.String{}}}},
+			"d": {Validator: &schema.Dict{KeysValidator: &schema.String{Regexp: "^[a-z]+$"}, Values: schema.Field{Validator: &schema.Integer{}}}},
+			"x": {Validator: &schema.Dict{}},
+			"o": {Validator: &schema.Object{Schema: &schema.Schema{Fields: schema.Fields{
+				"sub": {Required: true, Validator: &schema.Bool{}},
+			}}}},
+		}}},
+		{"Combinators", schema.Schema{Fields: schema.Fields{
+			"any": {Validator: &schema.AnyOf{&schema.Bool{}, &schema.String{}}},
+			"all": {Validator: &schema.AllOf{&schema.String{MinLen: 1}, &schema.String{MaxLen: 3}}},
+		}}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if !assert.NoError(t, jsonschema.NewEncoder(b).Encode(&tc.schema)) {
+				return
+			}
+			encoded := b.String()
+			var s schema.Schema
+			if !assert.NoError(t, jsonschema.NewDecoder(strings.NewReader(encoded)).Decode(&s)) {
+				return
+			}
+			assert.Equal(t, tc.schema, s)
+			b.Reset()
+			assert.NoError(t, jsonschema.NewEncoder(b).Encode(&s))
+			assert.JSONEq(t, encoded, b.String())
+		})
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	testCases := []struct {
+		name, input string
+		expect      schema.Schema
+	}{
+		{
+			name: "Refs",
+			input: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$defs": {"name": {"type": "string", "maxLength": 10}},
+				"type": "object",
+				"properties": {
+					"first": {"$ref": "#/$defs/name"},
+					"last": {"$ref": "#/$defs/name", "description": "Last name"}
+				}
+			}`,
+			expect: schema.Schema{Fields: schema.Fields{
+				"first": {Validator: &schema.String{MaxLen: 10}},
+				"last":  {Description: "Last name", Validator: &schema.String{MaxLen: 10}},
+			}},
+		},
+		{
+			name:  "TypeList",
+			input: `{"properties": {"n": {"type": ["string", "null"]}}}`,
+			expect: schema.Schema{Fields: schema.Fields{
+				"n": {Validator: &schema.AnyOf{&schema.String{}, &schema.Null{}}},
+			}},
+		},
+		{
+			name:  "TypeListKeywords",
+			input: `{"properties": {"n": {"type": ["string", "null"], "maxLength": 3}}}`,
+			expect: schema.Schema{Fields: schema.Fields{
+				"n": {Validator: &schema.AnyOf{&schema.String{MaxLen: 3}, &schema.Null{}}},
+			}},
+		},
+		{
+			name:  "AnyOfAnyValue",
+			input: `{"properties": {"v": {"anyOf": [{"type": "integer"}, {"title": "anything"}]}}}`,
+			expect: schema.Schema{Fields: schema.Fields{
+				"v": {},
+			}},
+		},
+		{
+			name:  "AllOfAnyValue",
+			input: `{"properties": {"v": {"allOf": [{}, {"type": "integer"}]}}}`,
+			expect: schema.Schema{Fields: schema.Fields{
+				"v": {Validator: &schema.AllOf{&schema.Integer{}}},
+			}},
+		},
+		{
+			name:  "Any",
+			input: `{"properties": {"v": {"title": "anything"}}}`,
+			expect: schema.Schema{Fields: schema.Fields{
+				"v": {},
+			}},
+		},
+		{
+			name:  "OneOf",
+			input: `{"properties": {"v": {"oneOf": [{"type": "integer"}, {"type": "boolean"}]}}}`,
+			expect: schema.Schema{Fields: schema.Fields{
+				"v": {Validator: &schema.AnyOf{&schema.Integer{}, &schema.Bool{}}},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var s schema.Schema
+			if assert.NoError(t, jsonschema.NewDecoder(strings.NewReader(tc.input)).Decode(&s)) {
+				assert.Equal(t, tc.expect, s)
+			}
+		})
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	testCases := []struct {
+		name, input, expectError string
+		is                       error
+	}{
+		{"Syntax", `{`, "unexpected EOF", nil},
+		{"ChainedRef", `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"type": "string"}}, "properties": {"a": {"$ref": "#/$defs/a"}}}`, "/properties/a/$ref: chained reference #/$defs/a: unsupported keyword", jsonschema.ErrUnsupportedKeyword},
+		{"UnsupportedKeyword", `{"properties": {"a": {"type": "string", "not": {}}}}`, "/properties/a/not: unsupported keyword", jsonschema.ErrUnsupportedKeyword},
+		{"UnsupportedFormat", `{"properties": {"a": {"type": "string", "format": "hostname"}}}`, "/properties/a/format: unsupported keyword: hostname", jsonschema.ErrUnsupportedKeyword},
+		{"TypeListUnsupportedKeyword", `{"properties": {"a": {"type": ["string", "null"], "minItems": 1}}}`, "/properties/a/minItems: unsupported keyword", jsonschema.ErrUnsupportedKeyword},
+		{"OpenSchema", `{"additionalProperties": true}`, "/additionalProperties: invalid keyword", jsonschema.ErrInvalidKeyword},
+		{"InvalidType", `{"properties": {"a": {"type": "text"}}}`, "/properties/a/type: invalid keyword", jsonschema.ErrInvalidKeyword},
+		{"InvalidPattern", `{"properties": {"a": {"type": "string", "pattern": "("}}}`, "/properties/a/pattern: invalid keyword: error parsing regexp: missing closing ): `(`", jsonschema.ErrInvalidKeyword},
+		{"UnknownRequired", `{"required": ["a"]}`, "/required: unknown property a: invalid keyword", jsonschema.ErrInvalidKeyword},
+		{"RecursiveRef", `{"properties": {"a": {"$ref": "#"}}}`, "/properties/a/$ref: recursive reference #: unsupported keyword", jsonschema.ErrUnsupportedKeyword},
+		{"RemoteRef", `{"properties": {"a": {"$ref": "http://example.com/schema"}}}`, "/properties/a/$ref: non local reference http://example.com/schema: unsupported keyword", jsonschema.ErrUnsupportedKeyword},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var s schema.Schema
+			err := jsonschema.NewDecoder(strings.NewReader(tc.input)).Decode(&s)
+			assert.EqualError(t, err, tc.expectError)
+			if tc.is != nil {
+				assert.True(t, errors.Is(err, tc.is))
+			}
+		})
+	}
+}
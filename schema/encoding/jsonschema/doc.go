@@ -2,4 +2,8 @@
 // schema.Schema. Note that the current implementation is incomplete, and not all
 // FieldValidator types are yet supported. Custom validators are also not
 // supported at the moment.
+//
+// The Decoder goes the other way around and builds a schema.Schema from a JSON
+// Schema (draft 2020-12) document, as long as it only uses keywords with a
+// schema package equivalent.
 package jsonschema
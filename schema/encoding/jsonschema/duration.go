@@ -0,0 +1,12 @@
+package jsonschema
+
+import "github.com/entropyinf/rest-layer/schema"
+
+type durationBuilder schema.Duration
+
+func (v durationBuilder) BuildJSONSchema() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"type":   "string",
+		"format": "duration",
+	}, nil
+}
@@ -0,0 +1,14 @@
+package jsonschema
+
+import "github.com/entropyinf/rest-layer/schema"
+
+type encryptedBuilder schema.Encrypted
+
+// BuildJSONSchema describes the decrypted value, as seen by clients.
+func (v encryptedBuilder) BuildJSONSchema() (map[string]interface{}, error) {
+	builder, err := ValidatorBuilder(v.Validator)
+	if err != nil {
+		return nil, err
+	}
+	return builder.BuildJSONSchema()
+}
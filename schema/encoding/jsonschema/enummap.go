@@ -0,0 +1,12 @@
+package jsonschema
+
+import "github.com/entropyinf/rest-layer/schema"
+
+type enumMapBuilder schema.EnumMap
+
+func (v enumMapBuilder) BuildJSONSchema() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"type": "string",
+		"enum": schema.EnumMap(v).Keys(),
+	}, nil
+}
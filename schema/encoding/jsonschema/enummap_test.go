@@ -0,0 +1,25 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+func TestEnumMapValidatorEncode(t *testing.T) {
+	testCase := encoderTestCase{
+		name: ``,
+		schema: schema.Schema{
+			Fields: schema.Fields{
+				"status": {
+					Validator: &schema.EnumMap{Values: map[string]interface{}{"published": 1, "draft": 0}},
+				},
+			},
+		},
+		customValidate: fieldValidator("status", `{
+			"type": "string",
+			"enum": ["draft", "published"]
+		}`),
+	}
+	testCase.Run(t)
+}
@@ -0,0 +1,34 @@
+package jsonschema_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+func TestFormatValidatorsEncode(t *testing.T) {
+	testCases := []struct {
+		name      string
+		validator schema.FieldValidator
+		expect    string
+	}{
+		{"Email", &schema.Email{AllowedDomains: []string{"example.com"}}, `{"type": "string", "format": "email"}`},
+		{"UUID", &schema.UUID{Version: 4}, `{"type": "string", "format": "uuid"}`},
+		{"Decimal", &schema.Decimal{Precision: 10, Scale: 2}, `{"type": "string", "format": "decimal"}`},
+		{"Duration", &schema.Duration{Max: time.Hour}, `{"type": "string", "format": "duration"}`},
+		{"Encrypted", &schema.Encrypted{Validator: &schema.Email{}}, `{"type": "string", "format": "email"}`},
+	}
+	for _, tc := range testCases {
+		testCase := encoderTestCase{
+			name: tc.name,
+			schema: schema.Schema{
+				Fields: schema.Fields{
+					"f": {Validator: tc.validator},
+				},
+			},
+			customValidate: fieldValidator("f", tc.expect),
+		}
+		testCase.Run(t)
+	}
+}
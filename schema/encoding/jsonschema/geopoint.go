@@ -0,0 +1,32 @@
+package jsonschema
+
+import "github.com/entropyinf/rest-layer/schema"
+
+type geoPointBuilder schema.GeoPoint
+
+// BuildJSONSchema describes the GeoJSON point the value is stored as.
+func (v geoPointBuilder) BuildJSONSchema() (map[string]interface{}, error) {
+	lng := map[string]interface{}{"type": "number", "minimum": -180, "maximum": 180}
+	lat := map[string]interface{}{"type": "number", "minimum": -90, "maximum": 90}
+	if v.Bounds != nil {
+		lng["minimum"], lng["maximum"] = v.Bounds.MinLng, v.Bounds.MaxLng
+		lat["minimum"], lat["maximum"] = v.Bounds.MinLat, v.Bounds.MaxLat
+	}
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"type", "coordinates"},
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{
+				"type": "string",
+				"enum": []string{"Point"},
+			},
+			"coordinates": map[string]interface{}{
+				"type":     "array",
+				"items":    []interface{}{lng, lat},
+				"minItems": 2,
+				"maxItems": 2,
+			},
+		},
+		"additionalProperties": false,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+func TestGeoPointValidatorEncode(t *testing.T) {
+	testCase := encoderTestCase{
+		name: ``,
+		schema: schema.Schema{
+			Fields: schema.Fields{
+				"location": {
+					Validator: &schema.GeoPoint{Bounds: &schema.GeoBounds{MinLat: 41, MaxLat: 51, MinLng: -5, MaxLng: 10}},
+				},
+			},
+		},
+		customValidate: fieldValidator("location", `{
+			"type": "object",
+			"required": ["type", "coordinates"],
+			"properties": {
+				"type": {"type": "string", "enum": ["Point"]},
+				"coordinates": {
+					"type": "array",
+					"items": [
+						{"type": "number", "minimum": -5, "maximum": 10},
+						{"type": "number", "minimum": 41, "maximum": 51}
+					],
+					"minItems": 2,
+					"maxItems": 2
+				}
+			},
+			"additionalProperties": false
+		}`),
+	}
+	testCase.Run(t)
+}
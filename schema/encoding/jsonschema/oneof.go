@@ -0,0 +1,26 @@
+package jsonschema
+
+import "github.com/entropyinf/rest-layer/schema"
+
+type oneOfBuilder schema.OneOfSchema
+
+func (v oneOfBuilder) BuildJSONSchema() (map[string]interface{}, error) {
+	oneOf := schema.OneOfSchema(v)
+	variants := make([]interface{}, 0, len(v.Mapping))
+	for _, key := range oneOf.Keys() {
+		m := map[string]interface{}{}
+		if err := addSchemaProperties(m, v.Mapping[key]); err != nil {
+			return nil, err
+		}
+		// Restrict the discriminator of each variant to its value.
+		if props, ok := m["properties"].(map[string]interface{}); ok {
+			if p, ok := props[v.Discriminator].(map[string]interface{}); ok {
+				p["enum"] = []interface{}{key}
+			}
+		}
+		variants = append(variants, m)
+	}
+	return map[string]interface{}{
+		"oneOf": variants,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+func TestOneOfSchemaEncode(t *testing.T) {
+	testCase := encoderTestCase{
+		name: "OneOfSchema",
+		schema: schema.Schema{
+			Fields: schema.Fields{
+				"payment": {
+					Validator: &schema.OneOfSchema{
+						Discriminator: "type",
+						Mapping: map[string]*schema.Schema{
+							"card": {Fields: schema.Fields{"type": {Validator: &schema.String{}}}},
+							"bank": {Fields: schema.Fields{"type": {Validator: &schema.String{}}}},
+						},
+					},
+				},
+			},
+		},
+		customValidate: fieldValidator("payment", `{
+			"oneOf": [
+				{
+					"type": "object",
+					"additionalProperties": false,
+					"properties": {"type": {"type": "string", "enum": ["bank"]}}
+				},
+				{
+					"type": "object",
+					"additionalProperties": false,
+					"properties": {"type": {"type": "string", "enum": ["card"]}}
+				}
+			]
+		}`),
+	}
+	testCase.Run(t)
+}
@@ -67,8 +67,8 @@ func addFieldProperties(m map[string]interface{}, field schema.Field) {
 	if field.Description != "" {
 		m["description"] = field.Description
 	}
-	if field.ReadOnly {
-		m["readOnly"] = field.ReadOnly
+	if field.ReadOnly || field.Compute != nil {
+		m["readOnly"] = true
 	}
 	if field.Default != nil {
 		m["default"] = field.Default
@@ -98,6 +98,22 @@ func ValidatorBuilder(v schema.FieldValidator) (Builder, error) {
 		return (*urlBuilder)(t), nil
 	case *schema.Time:
 		return (*timeBuilder)(t), nil
+	case *schema.Email:
+		return (*emailBuilder)(t), nil
+	case *schema.UUID:
+		return (*uuidBuilder)(t), nil
+	case *schema.Decimal:
+		return (*decimalBuilder)(t), nil
+	case *schema.Duration:
+		return (*durationBuilder)(t), nil
+	case *schema.GeoPoint:
+		return (*geoPointBuilder)(t), nil
+	case *schema.Encrypted:
+		return (*encryptedBuilder)(t), nil
+	case *schema.OneOfSchema:
+		return (*oneOfBuilder)(t), nil
+	case *schema.EnumMap:
+		return (*enumMapBuilder)(t), nil
 	case *schema.Integer:
 		return (*integerBuilder)(t), nil
 	case *schema.Float:
@@ -0,0 +1,211 @@
+package schema
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// encryptedPrefix prefixes the stored form of encrypted values.
+const encryptedPrefix = "enc:"
+
+// KeyProvider provides the keys used by Encrypted fields. Keys must be 16, 24
+// or 32 bytes long to select AES-128, AES-192 or AES-256.
+type KeyProvider interface {
+	// Key returns the key identified by id.
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider serving keys from memory.
+type StaticKeys map[string][]byte
+
+// Key implements the KeyProvider interface.
+func (k StaticKeys) Key(id string) ([]byte, error) {
+	key, found := k[id]
+	if !found {
+		return nil, fmt.Errorf("unknown key `%s'", id)
+	}
+	return key, nil
+}
+
+// Encrypted encrypts the value of a field with AES-GCM before it is stored and
+// decrypts it when it is serialized. The value is validated by Validator
+// before encryption.
+//
+// Values are stored as strings embedding the id of the key used to encrypt
+// them, so keys can be rotated by changing KeyID while keeping previous keys
+// available from the KeyProvider to decrypt existing values. Ciphertexts are
+// bound to the key id and to Context, so values copied from another field are
+// rejected.
+//
+// Encrypted fields can't be filtered unless Deterministic is set, in which case
+// only equality filters are accepted.
+type Encrypted struct {
+	// Validator validates the value before it is encrypted.
+	Validator FieldValidator
+	// KeyID is the id of the key used to encrypt new values.
+	KeyID string
+	// Keys provides the encryption keys.
+	Keys KeyProvider
+	// Deterministic derives the nonce from the value so equal values give
+	// equal ciphertexts, allowing equality filters. Equality only matches
+	// values encrypted with the current key. It leaks which items share the
+	// same value.
+	Deterministic bool
+	// Context identifies the field the values belong to. When empty, it is
+	// set to the name of the field when the schema is compiled. Set it to a
+	// value unique across resources (i.e.: "users.ssn") when the same keys
+	// encrypt the fields of several resources.
+	Context string
+}
+
+// encryptedNonceInfo derives the key used to compute deterministic nonces from
+// the encryption key.
+const encryptedNonceInfo = "rest-layer/schema.Encrypted nonce"
+
+// Compile implements the Compiler interface.
+func (v *Encrypted) Compile(rc ReferenceChecker) error {
+	if v.Keys == nil {
+		return errors.New("no key provider defined")
+	}
+	if _, err := v.aead(v.KeyID); err != nil {
+		return err
+	}
+	if c, ok := v.Validator.(Compiler); ok {
+		return c.Compile(rc)
+	}
+	return nil
+}
+
+// ValidateQuery implements FieldQueryValidator interface. Query values are
+// only accepted in deterministic mode, and encrypted so they can be compared
+// with stored values.
+func (v Encrypted) ValidateQuery(value interface{}) (interface{}, error) {
+	if !v.Deterministic {
+		return nil, NewValidationError("not_filterable", nil)
+	}
+	return v.Validate(value)
+}
+
+// Validate implements FieldValidator interface.
+func (v Encrypted) Validate(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok && strings.HasPrefix(s, encryptedPrefix) {
+		// Maybe it's an already encrypted value (i.e.: when a JSON Patch is
+		// applied on the stored document). Values encrypted for another
+		// context don't decrypt.
+		if _, err := v.decrypt(s); err == nil {
+			return s, nil
+		}
+	}
+	if v.Validator != nil {
+		var err error
+		if value, err = v.Validator.Validate(value); err != nil {
+			return nil, err
+		}
+	}
+	plain, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := v.aead(v.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if v.Deterministic {
+		// The nonce is computed with a key derived from the encryption key,
+		// never with the encryption key itself.
+		key, _ := v.Keys.Key(v.KeyID)
+		derive := hmac.New(sha256.New, key)
+		derive.Write([]byte(encryptedNonceInfo))
+		mac := hmac.New(sha256.New, derive.Sum(nil))
+		mac.Write([]byte(v.Context))
+		mac.Write([]byte{0})
+		mac.Write(plain)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, plain, v.additionalData(v.KeyID))
+	return encryptedPrefix + v.KeyID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Serialize implements FieldSerializer interface. The stored value is
+// decrypted, then validated and serialized by Validator to restore its
+// original form.
+func (v Encrypted) Serialize(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, NewValidationError("not_encrypted", nil)
+	}
+	value, err := v.decrypt(s)
+	if err != nil {
+		return nil, err
+	}
+	if v.Validator != nil {
+		if value, err = v.Validator.Validate(value); err != nil {
+			return nil, err
+		}
+		if s, ok := v.Validator.(FieldSerializer); ok {
+			return s.Serialize(value)
+		}
+	}
+	return value, nil
+}
+
+// decrypt decrypts a stored value and returns the decoded JSON value.
+func (v Encrypted) decrypt(s string) (interface{}, error) {
+	if !strings.HasPrefix(s, encryptedPrefix) {
+		return nil, NewValidationError("not_encrypted", nil)
+	}
+	s = s[len(encryptedPrefix):]
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return nil, NewValidationError("not_encrypted", nil)
+	}
+	keyID := s[:i]
+	sealed, err := base64.RawStdEncoding.DecodeString(s[i+1:])
+	if err != nil {
+		return nil, NewValidationError("not_encrypted", nil)
+	}
+	aead, err := v.aead(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, NewValidationError("not_encrypted", nil)
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], v.additionalData(keyID))
+	if err != nil {
+		return nil, NewValidationError("not_encrypted", nil)
+	}
+	var value interface{}
+	err = json.Unmarshal(plain, &value)
+	return value, err
+}
+
+// additionalData returns the data authenticated along with the values
+// encrypted with keyID.
+func (v Encrypted) additionalData(keyID string) []byte {
+	return []byte(keyID + "\x00" + v.Context)
+}
+
+func (v Encrypted) aead(keyID string) (cipher.AEAD, error) {
+	key, err := v.Keys.Key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
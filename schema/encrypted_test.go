@@ -0,0 +1,118 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedCompile(t *testing.T) {
+	keys := schema.StaticKeys{"k1": []byte("0123456789abcdef"), "short": []byte("short")}
+	assert.NoError(t, (&schema.Encrypted{Keys: keys, KeyID: "k1", Validator: &schema.String{}}).Compile(nil))
+	assert.EqualError(t, (&schema.Encrypted{KeyID: "k1"}).Compile(nil), "no key provider defined")
+	assert.EqualError(t, (&schema.Encrypted{Keys: keys, KeyID: "k2"}).Compile(nil), "unknown key `k2'")
+	assert.EqualError(t, (&schema.Encrypted{Keys: keys, KeyID: "short"}).Compile(nil), "crypto/aes: invalid key size 5")
+}
+
+func TestEncryptedValidator(t *testing.T) {
+	keys := schema.StaticKeys{
+		"k1": []byte("0123456789abcdef"),
+		"k2": []byte("0123456789abcdef0123456789abcdef"),
+	}
+	v := schema.Encrypted{Keys: keys, KeyID: "k1", Validator: &schema.String{MaxLen: 10}}
+
+	stored, err := v.Validate("secret")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored.(string), "enc:k1:"))
+	assert.NotContains(t, stored, "secret")
+	value, err := v.Serialize(stored)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	// Random nonces give different ciphertexts for the same value.
+	other, _ := v.Validate("secret")
+	assert.NotEqual(t, stored, other)
+
+	// Already encrypted values are kept as is.
+	again, err := v.Validate(stored)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, again)
+
+	// The inner validator is applied before encryption.
+	_, err = v.Validate("too long secret")
+	assert.Equal(t, schema.NewValidationError("too_long", map[string]interface{}{"max": 10}), err)
+
+	// Values encrypted with a previous key can still be decrypted.
+	rotated := v
+	rotated.KeyID = "k2"
+	value, err = rotated.Serialize(stored)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", value)
+	stored2, err := rotated.Validate("secret")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored2.(string), "enc:k2:"))
+
+	// Tampered values are rejected.
+	tampered := stored.(string)
+	tampered = tampered[:len(tampered)-2] + "AA"
+	_, err = v.Serialize(tampered)
+	assert.Error(t, err)
+	_, err = v.Serialize("secret")
+	assert.Equal(t, schema.NewValidationError("not_encrypted", nil), err)
+
+	// Non deterministic fields can't be filtered.
+	_, err = v.ValidateQuery("secret")
+	assert.Equal(t, schema.NewValidationError("not_filterable", nil), err)
+}
+
+func TestEncryptedDeterministic(t *testing.T) {
+	v := schema.Encrypted{
+		Keys:          schema.StaticKeys{"k1": []byte("0123456789abcdef")},
+		KeyID:         "k1",
+		Validator:     &schema.Integer{},
+		Deterministic: true,
+	}
+	stored, err := v.Validate(42)
+	assert.NoError(t, err)
+	q, err := v.ValidateQuery(42)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, q)
+	other, _ := v.Validate(43)
+	assert.NotEqual(t, stored, other)
+
+	// Decrypted values are restored to the form of the inner validator.
+	value, err := v.Serialize(stored)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+}
+
+func TestEncryptedContext(t *testing.T) {
+	keys := schema.StaticKeys{"k1": []byte("0123456789abcdef")}
+	s := schema.Schema{Fields: schema.Fields{
+		"pin":  {Validator: &schema.Encrypted{Keys: keys, KeyID: "k1", Validator: &schema.String{MaxLen: 4}}},
+		"note": {Validator: &schema.Encrypted{Keys: keys, KeyID: "k1", Validator: &schema.String{}}},
+	}}
+	assert.NoError(t, s.Compile(nil))
+	pin := s.Fields["pin"].Validator.(*schema.Encrypted)
+	note := s.Fields["note"].Validator.(*schema.Encrypted)
+	assert.Equal(t, "pin", pin.Context)
+
+	// A ciphertext copied from another field is not accepted as is, so it
+	// can't bypass the inner validator.
+	stored, err := note.Validate("too long for a pin")
+	assert.NoError(t, err)
+	_, err = pin.Serialize(stored)
+	assert.Equal(t, schema.NewValidationError("not_encrypted", nil), err)
+	_, err = pin.Validate(stored)
+	assert.Equal(t, schema.NewValidationError("too_long", map[string]interface{}{"max": 4}), err)
+
+	// Deterministic ciphertexts differ between contexts.
+	a := schema.Encrypted{Keys: keys, KeyID: "k1", Deterministic: true, Context: "a"}
+	b := a
+	b.Context = "b"
+	ca, _ := a.Validate("x")
+	cb, _ := b.Validate("x")
+	assert.NotEqual(t, ca, cb)
+}
@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// EnumMap validates that the value is one of the keys of Values and stores the
+// matching value instead (i.e.: a label stored as a number). The stored value is
+// serialized back to its key.
+type EnumMap struct {
+	Values map[string]interface{}
+}
+
+// Compile implements Compiler interface. Values must be distinct so stored
+// values can be serialized back to a single key.
+func (v EnumMap) Compile(rc ReferenceChecker) error {
+	keys := v.Keys()
+	for i, key := range keys {
+		for _, other := range keys[:i] {
+			if reflect.DeepEqual(v.Values[key], v.Values[other]) {
+				return fmt.Errorf("keys `%s' and `%s' have the same value", other, key)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateQuery implements FieldQueryValidator interface.
+func (v EnumMap) ValidateQuery(value interface{}) (interface{}, error) {
+	return v.Validate(value)
+}
+
+// Validate implements FieldValidator interface.
+func (v EnumMap) Validate(value interface{}) (interface{}, error) {
+	key, ok := value.(string)
+	if !ok {
+		return nil, NewValidationError("not_string", nil)
+	}
+	stored, found := v.Values[key]
+	if !found {
+		return nil, NewValidationError("not_allowed", map[string]interface{}{"allowed": v.Keys()})
+	}
+	return stored, nil
+}
+
+// Serialize implements FieldSerializer.
+func (v EnumMap) Serialize(value interface{}) (interface{}, error) {
+	for key, stored := range v.Values {
+		if reflect.DeepEqual(stored, value) {
+			return key, nil
+		}
+	}
+	return nil, NewValidationError("invalid_type", nil)
+}
+
+// Keys returns the sorted list of accepted keys.
+func (v EnumMap) Keys() []string {
+	keys := make([]string, 0, len(v.Values))
+	for key := range v.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
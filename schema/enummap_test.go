@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumMapValidatorCompile(t *testing.T) {
+	assert.NoError(t, EnumMap{Values: map[string]interface{}{"draft": 0, "published": 1}}.Compile(nil))
+	err := EnumMap{Values: map[string]interface{}{"draft": 0, "new": 0, "published": 1}}.Compile(nil)
+	assert.EqualError(t, err, "keys `draft' and `new' have the same value")
+}
+
+func TestEnumMapValidator(t *testing.T) {
+	v := EnumMap{Values: map[string]interface{}{"draft": 0, "published": 1}}
+	val, err := v.Validate("published")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, val)
+	val, err = v.Validate("deleted")
+	assert.EqualError(t, err, "not one of [draft, published]")
+	assert.Nil(t, val)
+	val, err = v.Validate(1)
+	assert.EqualError(t, err, "not a string")
+	assert.Nil(t, val)
+	val, err = v.ValidateQuery("draft")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, val)
+}
+
+func TestEnumMapValidatorSerialize(t *testing.T) {
+	v := EnumMap{Values: map[string]interface{}{"draft": 0, "published": 1}}
+	s, err := v.Serialize(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", s)
+	s, err = v.Serialize(2)
+	assert.EqualError(t, err, "invalid type")
+	assert.Nil(t, s)
+}
@@ -25,6 +25,16 @@ type Field struct {
 	// this field is enabled, PUTing the document without the field would not
 	// remove the field but use the previous document's value if any.
 	Hidden bool
+	// ReadPermission, when set, is called with the caller's context and the
+	// document holding the field to decide whether the field may be returned
+	// to the caller. The field is stripped from the response when it returns
+	// false.
+	ReadPermission FieldPermission
+	// WritePermission, when set, is called with the caller's context and the
+	// document holding the field (before changes are applied) to decide
+	// whether the caller may change the field. Changes are rejected with a
+	// validation error when it returns false.
+	WritePermission FieldPermission
 	// Default defines the value be stored on the field when when item is
 	// created and this field is not provided by the client.
 	Default interface{}
@@ -59,14 +69,31 @@ type Field struct {
 	// When this property is set to `true`, you may want to ensure the backend
 	// database has this field indexed.
 	Sortable bool
+	// Unique defines that no other item of the resource may have the same
+	// value for this field. The constraint is checked against the storage
+	// before the item is stored; you may want to ensure the backend database
+	// enforces it as well with a unique index.
+	Unique bool
 	// Schema can be set to a sub-schema to allow multi-level schema.
 	Schema *Schema
+	// Compute marks the field as virtual: its value is derived from the
+	// document holding the field when it is read and is never stored. Clients
+	// can't set computed fields. The Validator, if any, is only used to
+	// describe and serialize the computed value.
+	Compute func(ctx context.Context, doc map[string]interface{}) (interface{}, error)
+	// ComputeByDefault evaluates a computed field when the client does not
+	// explicitly select fields. Otherwise, computed fields are only evaluated
+	// when listed in the projection.
+	ComputeByDefault bool
 }
 
 // Compile implements the ReferenceCompiler interface and recursively compile sub schemas
 // and validators when they implement Compiler interface.
 func (f Field) Compile(rc ReferenceChecker) error {
 	// TODO check field name format (alpha num + _ and -).
+	if f.Compute != nil && (f.Filterable || f.Sortable || f.Unique) {
+		return errors.New(": computed field can't be filterable, sortable or unique")
+	}
 	if f.Schema != nil {
 		// Recursively compile sub schema if any.
 		if err := f.Schema.Compile(rc); err != nil {
@@ -86,6 +113,31 @@ func (f Field) Compile(rc ReferenceChecker) error {
 	return nil
 }
 
+// CanRead returns true if the caller identified by ctx may read the field on
+// doc.
+func (f Field) CanRead(ctx context.Context, doc map[string]interface{}) bool {
+	return f.ReadPermission == nil || f.ReadPermission(ctx, doc)
+}
+
+// CanWrite returns true if the caller identified by ctx may change the field
+// on doc.
+func (f Field) CanWrite(ctx context.Context, doc map[string]interface{}) bool {
+	return f.WritePermission == nil || f.WritePermission(ctx, doc)
+}
+
+// FieldPermission decides if the caller identified by ctx (i.e.: using the
+// user stored in the context by an authentication middleware) may access a
+// field of doc.
+type FieldPermission func(ctx context.Context, doc map[string]interface{}) bool
+
+// ComputedFieldLister is implemented by FieldGetters able to list the computed
+// fields to evaluate when the client does not explicitly select fields.
+type ComputedFieldLister interface {
+	// DefaultComputedFields returns the sorted names of the computed fields
+	// with ComputeByDefault set.
+	DefaultComputedFields() []string
+}
+
 // FieldHandler is the piece of logic modifying the field value based on passed
 // parameters
 type FieldHandler func(ctx context.Context, value interface{}, params map[string]interface{}) (interface{}, error)
@@ -97,7 +149,7 @@ type FieldValidator interface {
 	Validate(value interface{}) (interface{}, error)
 }
 
-//FieldValidatorFunc is an adapter to allow the use of ordinary functions as
+// FieldValidatorFunc is an adapter to allow the use of ordinary functions as
 // field validators. If f is a function with the appropriate signature,
 // FieldValidatorFunc(f) is a FieldValidator that calls f.
 type FieldValidatorFunc func(value interface{}) (interface{}, error)
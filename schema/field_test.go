@@ -0,0 +1,99 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+type roleKey struct{}
+
+func isAdmin(ctx context.Context, doc map[string]interface{}) bool {
+	return ctx.Value(roleKey{}) == "admin"
+}
+
+func isOwner(ctx context.Context, doc map[string]interface{}) bool {
+	user := ctx.Value(roleKey{})
+	return user != nil && doc["owner"] == user
+}
+
+func TestFieldPermissions(t *testing.T) {
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"owner":  {Validator: &schema.String{}},
+			"name":   {Validator: &schema.String{}},
+			"salary": {Validator: &schema.Integer{}, ReadPermission: isAdmin, WritePermission: isAdmin},
+			"status": {Validator: &schema.String{}, WritePermission: isOwner},
+		},
+	}
+	admin := context.WithValue(context.Background(), roleKey{}, "admin")
+	john := context.WithValue(context.Background(), roleKey{}, "john")
+	original := map[string]interface{}{"owner": "john", "name": "a", "salary": 10, "status": "draft"}
+
+	t.Run("CanRead", func(t *testing.T) {
+		assert.True(t, s.Fields["name"].CanRead(john, original))
+		assert.True(t, s.Fields["salary"].CanRead(admin, original))
+		assert.False(t, s.Fields["salary"].CanRead(john, original))
+	})
+	t.Run("Patch allowed", func(t *testing.T) {
+		changes, base := s.Prepare(john, map[string]interface{}{"status": "published"}, &original, false)
+		doc, errs := s.ValidateContext(john, changes, base)
+		assert.Len(t, errs, 0)
+		assert.Equal(t, "published", doc["status"])
+	})
+	t.Run("Patch denied", func(t *testing.T) {
+		changes, base := s.Prepare(admin, map[string]interface{}{"status": "published"}, &original, false)
+		_, errs := s.ValidateContext(admin, changes, base)
+		assert.Equal(t, map[string][]interface{}{"status": {schema.NewValidationError("permission_denied", nil)}}, errs)
+	})
+	t.Run("Patch unchanged value", func(t *testing.T) {
+		changes, base := s.Prepare(john, map[string]interface{}{"salary": 10}, &original, false)
+		_, errs := s.ValidateContext(john, changes, base)
+		assert.Len(t, errs, 0)
+	})
+	t.Run("Replace keeps unreadable fields", func(t *testing.T) {
+		changes, base := s.Prepare(john, map[string]interface{}{"owner": "john", "name": "b", "status": "draft"}, &original, true)
+		doc, errs := s.ValidateContext(john, changes, base)
+		assert.Len(t, errs, 0)
+		assert.Equal(t, map[string]interface{}{"owner": "john", "name": "b", "salary": 10, "status": "draft"}, doc)
+	})
+	t.Run("Replace keeps unwritable fields", func(t *testing.T) {
+		changes, base := s.Prepare(admin, map[string]interface{}{"owner": "john", "name": "b", "salary": 20}, &original, true)
+		doc, errs := s.ValidateContext(admin, changes, base)
+		assert.Len(t, errs, 0)
+		assert.Equal(t, map[string]interface{}{"owner": "john", "name": "b", "salary": 20, "status": "draft"}, doc)
+	})
+	t.Run("Create denied", func(t *testing.T) {
+		changes, base := s.Prepare(john, map[string]interface{}{"owner": "john", "salary": 20}, nil, false)
+		_, errs := s.ValidateContext(john, changes, base)
+		assert.Equal(t, map[string][]interface{}{"salary": {schema.NewValidationError("permission_denied", nil)}}, errs)
+	})
+}
+
+func TestComputedField(t *testing.T) {
+	fullName := func(ctx context.Context, doc map[string]interface{}) (interface{}, error) {
+		return doc["first"].(string) + " " + doc["last"].(string), nil
+	}
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"first":    {Validator: &schema.String{}},
+			"last":     {Validator: &schema.String{}},
+			"fullName": {Validator: &schema.String{}, Compute: fullName},
+		},
+	}
+	assert.NoError(t, s.Compile(nil))
+
+	changes, base := s.Prepare(context.Background(), map[string]interface{}{"first": "John", "last": "Doe"}, nil, false)
+	doc, errs := s.Validate(changes, base)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]interface{}{"first": "John", "last": "Doe"}, doc)
+
+	changes, base = s.Prepare(context.Background(), map[string]interface{}{"first": "John", "last": "Doe", "fullName": "John Doe"}, nil, false)
+	_, errs = s.Validate(changes, base)
+	assert.Equal(t, map[string][]interface{}{"fullName": {schema.NewValidationError("read_only", nil)}}, errs)
+
+	s.Fields["fullName"] = schema.Field{Compute: fullName, Sortable: true}
+	assert.EqualError(t, s.Compile(nil), "fullName: computed field can't be filterable, sortable or unique")
+}
@@ -1,10 +1,5 @@
 package schema
 
-import (
-	"errors"
-	"fmt"
-)
-
 // Boundaries defines min/max for an integer.
 type Boundaries struct {
 	Min float64
@@ -25,7 +20,7 @@ func (v Float) ValidateQuery(value interface{}) (interface{}, error) {
 func (v Float) get(value interface{}) (float64, error) {
 	f, ok := value.(float64)
 	if !ok {
-		return 0, errors.New("not a float")
+		return 0, NewValidationError("not_float", nil)
 	}
 	return f, nil
 }
@@ -38,10 +33,10 @@ func (v Float) Validate(value interface{}) (interface{}, error) {
 	}
 	if v.Boundaries != nil {
 		if f < v.Boundaries.Min {
-			return nil, fmt.Errorf("is lower than %.2f", v.Boundaries.Min)
+			return nil, NewValidationError("float_too_small", map[string]interface{}{"min": v.Boundaries.Min})
 		}
 		if f > v.Boundaries.Max {
-			return nil, fmt.Errorf("is greater than %.2f", v.Boundaries.Max)
+			return nil, NewValidationError("float_too_large", map[string]interface{}{"max": v.Boundaries.Max})
 		}
 	}
 	if len(v.Allowed) > 0 {
@@ -54,7 +49,7 @@ func (v Float) Validate(value interface{}) (interface{}, error) {
 		}
 		if !found {
 			// TODO: build the list of allowed values.
-			return nil, fmt.Errorf("not one of the allowed values")
+			return nil, NewValidationError("not_allowed_value", map[string]interface{}{"allowed": v.Allowed})
 		}
 	}
 	return f, nil
@@ -63,7 +58,7 @@ func (v Float) Validate(value interface{}) (interface{}, error) {
 func (v Float) parse(value interface{}) (interface{}, error) {
 	f, ok := value.(float64)
 	if !ok {
-		return nil, errors.New("not a float")
+		return nil, NewValidationError("not_float", nil)
 	}
 	return f, nil
 }
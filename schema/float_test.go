@@ -1,7 +1,6 @@
 package schema_test
 
 import (
-	"errors"
 	"math"
 	"reflect"
 	"testing"
@@ -19,8 +18,8 @@ func TestFloatQueryValidator(t *testing.T) {
 		err           error
 	}{
 		{`Float.ValidateQuery(float64)`, schema.Float{}, 1.2, 1.2, nil},
-		{`Float.ValidateQuery(int)`, schema.Float{}, 1, nil, errors.New("not a float")},
-		{`Float.ValidateQuery(string)`, schema.Float{}, "1.2", nil, errors.New("not a float")},
+		{`Float.ValidateQuery(int)`, schema.Float{}, 1, nil, schema.NewValidationError("not_float", nil)},
+		{`Float.ValidateQuery(string)`, schema.Float{}, "1.2", nil, schema.NewValidationError("not_float", nil)},
 		{"Float.ValidateQuery(float64)-out of range above", schema.Float{Boundaries: &schema.Boundaries{Min: 0, Max: 2}}, 3.1, 3.1, nil},
 		{"Float.ValidateQuery(float64)-in range", schema.Float{Boundaries: &schema.Boundaries{Min: 0, Max: 2}}, 1.1, 1.1, nil},
 		{"Float.ValidateQuery(float64)-out of range below", schema.Float{Boundaries: &schema.Boundaries{Min: 2, Max: 10}}, 1.1, 1.1, nil},
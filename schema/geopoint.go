@@ -0,0 +1,110 @@
+package schema
+
+// GeoPoint validates geographic coordinates. The following representations are
+// accepted as input:
+//
+//	{"lat": 48.85, "lng": 2.35} // "lon" is accepted too
+//	[2.35, 48.85]               // [longitude, latitude]
+//	{"type": "Point", "coordinates": [2.35, 48.85]}
+//
+// The value is stored as a GeoJSON point, the representation expected by
+// geospatial queries ($near) in most databases.
+type GeoPoint struct {
+	// Bounds restricts the accepted points to the given area if set.
+	Bounds *GeoBounds
+}
+
+// GeoBounds defines a rectangular geographic area.
+type GeoBounds struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+// Contains returns true if the point is within the bounds.
+func (b GeoBounds) Contains(lng, lat float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lng >= b.MinLng && lng <= b.MaxLng
+}
+
+// ValidateQuery implements FieldQueryValidator interface.
+func (v GeoPoint) ValidateQuery(value interface{}) (interface{}, error) {
+	lng, lat, err := parseGeoPoint(value)
+	if err != nil {
+		return nil, err
+	}
+	return NewGeoJSONPoint(lng, lat), nil
+}
+
+// Validate implements FieldValidator interface.
+func (v GeoPoint) Validate(value interface{}) (interface{}, error) {
+	lng, lat, err := parseGeoPoint(value)
+	if err != nil {
+		return nil, err
+	}
+	if v.Bounds != nil && !v.Bounds.Contains(lng, lat) {
+		return nil, NewValidationError("geo_point_out_of_bounds", nil)
+	}
+	return NewGeoJSONPoint(lng, lat), nil
+}
+
+// NewGeoJSONPoint returns the GeoJSON representation of a point as stored by
+// the GeoPoint validator.
+func NewGeoJSONPoint(lng, lat float64) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{lng, lat},
+	}
+}
+
+// GeoPointCoordinates returns the longitude and latitude of a point using one of
+// the representations accepted by the GeoPoint validator.
+func GeoPointCoordinates(value interface{}) (lng, lat float64, ok bool) {
+	lng, lat, err := parseGeoPoint(value)
+	return lng, lat, err == nil
+}
+
+func parseGeoPoint(value interface{}) (lng, lat float64, err error) {
+	var coords []interface{}
+	switch t := value.(type) {
+	case []interface{}:
+		coords = t
+	case map[string]interface{}:
+		if typ, found := t["type"]; found {
+			if typ != "Point" {
+				return 0, 0, NewValidationError("invalid_geo_point", nil)
+			}
+			coords, _ = t["coordinates"].([]interface{})
+			break
+		}
+		lngv, found := t["lng"]
+		if !found {
+			lngv = t["lon"]
+		}
+		coords = []interface{}{lngv, t["lat"]}
+	}
+	if len(coords) != 2 {
+		return 0, 0, NewValidationError("invalid_geo_point", nil)
+	}
+	var ok1, ok2 bool
+	lng, ok1 = toFloat(coords[0])
+	lat, ok2 = toFloat(coords[1])
+	if !ok1 || !ok2 {
+		return 0, 0, NewValidationError("invalid_geo_point", nil)
+	}
+	if lat < -90 || lat > 90 {
+		return 0, 0, NewValidationError("invalid_latitude", nil)
+	}
+	if lng < -180 || lng > 180 {
+		return 0, 0, NewValidationError("invalid_longitude", nil)
+	}
+	return lng, lat, nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	}
+	return 0, false
+}
@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoPointValidator(t *testing.T) {
+	paris := map[string]interface{}{"type": "Point", "coordinates": []interface{}{2.35, 48.85}}
+	for _, input := range []interface{}{
+		map[string]interface{}{"lat": 48.85, "lng": 2.35},
+		map[string]interface{}{"lat": 48.85, "lon": 2.35},
+		[]interface{}{2.35, 48.85},
+		map[string]interface{}{"type": "Point", "coordinates": []interface{}{2.35, 48.85}},
+	} {
+		v, err := GeoPoint{}.Validate(input)
+		assert.NoError(t, err, "%v", input)
+		assert.Equal(t, paris, v, "%v", input)
+	}
+	for input, msg := range map[string]interface{}{
+		"string":  "not a geo point",
+		"lat":     "not a geo point",
+		"polygon": "not a geo point",
+		"bad lat": "latitude out of range",
+		"bad lng": "longitude out of range",
+	} {
+		var value interface{}
+		switch input {
+		case "string":
+			value = "48.85,2.35"
+		case "lat":
+			value = map[string]interface{}{"lat": 48.85}
+		case "polygon":
+			value = map[string]interface{}{"type": "Polygon", "coordinates": []interface{}{}}
+		case "bad lat":
+			value = []interface{}{2.35, 91.0}
+		case "bad lng":
+			value = []interface{}{181, 48.85}
+		}
+		v, err := GeoPoint{}.Validate(value)
+		assert.EqualError(t, err, msg.(string), input)
+		assert.Nil(t, v, input)
+	}
+	france := &GeoBounds{MinLat: 41, MaxLat: 51, MinLng: -5, MaxLng: 10}
+	_, err := GeoPoint{Bounds: france}.Validate([]interface{}{2.35, 48.85})
+	assert.NoError(t, err)
+	v, err := GeoPoint{Bounds: france}.Validate([]interface{}{-74.0, 40.7})
+	assert.EqualError(t, err, "is out of bounds")
+	assert.Nil(t, v)
+	v, err = GeoPoint{Bounds: france}.ValidateQuery([]interface{}{-74.0, 40.7})
+	assert.NoError(t, err)
+	assert.Equal(t, NewGeoJSONPoint(-74, 40.7), v)
+}
+
+func TestGeoPointCoordinates(t *testing.T) {
+	lng, lat, ok := GeoPointCoordinates(NewGeoJSONPoint(2.35, 48.85))
+	assert.True(t, ok)
+	assert.Equal(t, 2.35, lng)
+	assert.Equal(t, 48.85, lat)
+	_, _, ok = GeoPointCoordinates("foo")
+	assert.False(t, ok)
+}
@@ -1,8 +1,6 @@
 package schema
 
 import (
-	"errors"
-	"fmt"
 	"math"
 )
 
@@ -20,7 +18,7 @@ func (v Integer) ValidateQuery(value interface{}) (interface{}, error) {
 func (v Integer) get(value interface{}) (int, error) {
 	i, ok := value.(int)
 	if !ok {
-		return 0, errors.New("not an integer")
+		return 0, NewValidationError("not_integer", nil)
 	}
 	return i, nil
 }
@@ -37,10 +35,10 @@ func (v Integer) Validate(value interface{}) (interface{}, error) {
 	}
 	if v.Boundaries != nil {
 		if float64(i) < v.Boundaries.Min {
-			return nil, fmt.Errorf("is lower than %.0f", v.Boundaries.Min)
+			return nil, NewValidationError("integer_too_small", map[string]interface{}{"min": v.Boundaries.Min})
 		}
 		if float64(i) > v.Boundaries.Max {
-			return nil, fmt.Errorf("is greater than %.0f", v.Boundaries.Max)
+			return nil, NewValidationError("integer_too_large", map[string]interface{}{"max": v.Boundaries.Max})
 		}
 	}
 	if len(v.Allowed) > 0 {
@@ -53,7 +51,7 @@ func (v Integer) Validate(value interface{}) (interface{}, error) {
 		}
 		if !found {
 			// TODO: build the list of allowed values.
-			return nil, fmt.Errorf("not one of the allowed values")
+			return nil, NewValidationError("not_allowed_value", map[string]interface{}{"allowed": v.Allowed})
 		}
 	}
 	return i, nil
@@ -71,7 +69,7 @@ func (v Integer) parse(value interface{}) (interface{}, error) {
 	}
 	i, ok := value.(int)
 	if !ok {
-		return nil, errors.New("not an integer")
+		return nil, NewValidationError("not_integer", nil)
 	}
 	return i, nil
 }
@@ -1,7 +1,6 @@
 package schema_test
 
 import (
-	"errors"
 	"math"
 	"reflect"
 	"testing"
@@ -18,8 +17,8 @@ func TestIntegerQueryValidator(t *testing.T) {
 		err           error
 	}{
 		{`Integer.ValidateQuery(int)`, schema.Integer{}, 1, 1, nil},
-		{`Integer.ValidateQuery(float64)`, schema.Integer{}, 1.1, nil, errors.New("not an integer")},
-		{`Integer.ValidateQuery(string)`, schema.Integer{}, "1", nil, errors.New("not an integer")},
+		{`Integer.ValidateQuery(float64)`, schema.Integer{}, 1.1, nil, schema.NewValidationError("not_integer", nil)},
+		{`Integer.ValidateQuery(string)`, schema.Integer{}, "1", nil, schema.NewValidationError("not_integer", nil)},
 		{"Integer.ValidateQuery(int)-out of range above", schema.Integer{Boundaries: &schema.Boundaries{Min: 0, Max: 2}}, 3, 3, nil},
 		{"Integer.ValidateQuery(int)-in range", schema.Integer{Boundaries: &schema.Boundaries{Min: 0, Max: 2}}, 1, 1, nil},
 		{"Integer.ValidateQuery(int)-out of range below", schema.Integer{Boundaries: &schema.Boundaries{Min: 0, Max: 2}}, -1, -1, nil},
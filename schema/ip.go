@@ -1,7 +1,6 @@
 package schema
 
 import (
-	"errors"
 	"net"
 )
 
@@ -16,11 +15,11 @@ type IP struct {
 func (v IP) Validate(value interface{}) (interface{}, error) {
 	s, ok := value.(string)
 	if !ok {
-		return nil, errors.New("invalid type")
+		return nil, NewValidationError("invalid_type", nil)
 	}
 	ip := net.ParseIP(s)
 	if ip == nil {
-		return nil, errors.New("invalid IP format")
+		return nil, NewValidationError("invalid_ip", nil)
 	}
 	if v.StoreBinary {
 		// If IP is a v4, store it's 4 bytes representation to save space.
@@ -39,10 +38,10 @@ func (v IP) Serialize(value interface{}) (interface{}, error) {
 	}
 	b, ok := value.([]byte)
 	if !ok {
-		return nil, errors.New("invalid type")
+		return nil, NewValidationError("invalid_type", nil)
 	}
 	if len(b) != 4 && len(b) != 16 {
-		return nil, errors.New("invalid size")
+		return nil, NewValidationError("invalid_size", nil)
 	}
 	return net.IP(b).String(), nil
 }
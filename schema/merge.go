@@ -0,0 +1,41 @@
+package schema
+
+// MergePatch applies patch on target following the JSON Merge Patch semantics
+// defined by RFC 7396: objects are merged key by key, null values remove the
+// matching keys and any other value, including arrays, replaces the target
+// value. The target is not modified.
+func MergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	res := make(map[string]interface{}, len(t)+len(p))
+	for k, v := range t {
+		res[k] = v
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(res, k)
+		} else {
+			res[k] = MergePatch(res[k], v)
+		}
+	}
+	return res
+}
+
+// mergeFieldValue merges value with the original value of a field when the
+// field holds a Dict. Other values are returned unchanged. Sub-schemas,
+// Object validators included, are merged field by field by Prepare instead.
+func mergeFieldValue(def Field, original, value interface{}) interface{} {
+	switch def.Validator.(type) {
+	case *Dict, Dict:
+		if _, ok := original.(map[string]interface{}); ok {
+			return MergePatch(original, value)
+		}
+	}
+	return value
+}
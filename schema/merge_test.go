@@ -0,0 +1,125 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Test cases from RFC 7396 appendix A.
+	cases := []struct {
+		target, patch, expect interface{}
+	}{
+		{map[string]interface{}{"a": "b"}, map[string]interface{}{"a": "c"}, map[string]interface{}{"a": "c"}},
+		{map[string]interface{}{"a": "b"}, map[string]interface{}{"b": "c"}, map[string]interface{}{"a": "b", "b": "c"}},
+		{map[string]interface{}{"a": "b"}, map[string]interface{}{"a": nil}, map[string]interface{}{}},
+		{map[string]interface{}{"a": "b", "b": "c"}, map[string]interface{}{"a": nil}, map[string]interface{}{"b": "c"}},
+		{map[string]interface{}{"a": []interface{}{"b"}}, map[string]interface{}{"a": "c"}, map[string]interface{}{"a": "c"}},
+		{map[string]interface{}{"a": "c"}, map[string]interface{}{"a": []interface{}{"b"}}, map[string]interface{}{"a": []interface{}{"b"}}},
+		{
+			map[string]interface{}{"a": map[string]interface{}{"b": "c"}},
+			map[string]interface{}{"a": map[string]interface{}{"b": "d", "c": nil}},
+			map[string]interface{}{"a": map[string]interface{}{"b": "d"}},
+		},
+		{map[string]interface{}{"a": []interface{}{map[string]interface{}{"b": "c"}}}, map[string]interface{}{"a": []interface{}{1}}, map[string]interface{}{"a": []interface{}{1}}},
+		{[]interface{}{"a", "b"}, []interface{}{"c", "d"}, []interface{}{"c", "d"}},
+		{map[string]interface{}{"a": "b"}, []interface{}{"c"}, []interface{}{"c"}},
+		{map[string]interface{}{"e": nil}, map[string]interface{}{"a": 1}, map[string]interface{}{"e": nil, "a": 1}},
+		{[]interface{}{1, 2}, map[string]interface{}{"a": "b", "c": nil}, map[string]interface{}{"a": "b"}},
+		{
+			map[string]interface{}{},
+			map[string]interface{}{"a": map[string]interface{}{"bb": map[string]interface{}{"ccc": nil}}},
+			map[string]interface{}{"a": map[string]interface{}{"bb": map[string]interface{}{}}},
+		},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, schema.MergePatch(c.target, c.patch))
+	}
+}
+
+func TestSchemaPrepareMergePatch(t *testing.T) {
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"name": {Validator: &schema.String{}},
+			"sub": {
+				Schema: &schema.Schema{
+					Fields: schema.Fields{
+						"a": {Validator: &schema.String{}},
+						"b": {Validator: &schema.String{}},
+					},
+				},
+			},
+			"obj": {
+				Validator: &schema.Object{
+					Schema: &schema.Schema{
+						Fields: schema.Fields{
+							"a": {Validator: &schema.String{}},
+							"b": {Validator: &schema.String{}},
+						},
+					},
+				},
+			},
+		},
+	}
+	original := map[string]interface{}{
+		"name": "foo",
+		"sub":  map[string]interface{}{"a": "a", "b": "b"},
+		"obj":  map[string]interface{}{"a": "a", "b": "b"},
+	}
+	changes, base := s.Prepare(context.Background(), map[string]interface{}{
+		"name": nil,
+		"sub":  map[string]interface{}{"a": "A"},
+		"obj":  map[string]interface{}{"b": nil},
+	}, &original, false)
+	doc, errs := s.Validate(changes, base)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]interface{}{
+		"sub": map[string]interface{}{"a": "A", "b": "b"},
+		"obj": map[string]interface{}{"a": "a"},
+	}, doc)
+
+	// Unchanged sub-documents are not reported as changes.
+	changes, _ = s.Prepare(context.Background(), map[string]interface{}{"sub": map[string]interface{}{"a": "a"}}, &original, false)
+	assert.Equal(t, map[string]interface{}{}, changes)
+}
+
+func TestSchemaPrepareMergePatchObjectHooks(t *testing.T) {
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"o": {
+				Validator: &schema.Object{
+					Schema: &schema.Schema{
+						Fields: schema.Fields{
+							"ro": {ReadOnly: true, Validator: &schema.String{}},
+							"rw": {Validator: &schema.String{}},
+							"updated": {OnUpdate: func(ctx context.Context, value interface{}) interface{} {
+								return "now"
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+	original := map[string]interface{}{
+		"o": map[string]interface{}{"ro": "a", "rw": "b"},
+	}
+
+	changes, base := s.Prepare(context.Background(), map[string]interface{}{
+		"o": map[string]interface{}{"ro": "A"},
+	}, &original, false)
+	_, errs := s.Validate(changes, base)
+	assert.Equal(t, map[string][]interface{}{"o": {"ro is [read-only]"}}, errs)
+
+	changes, base = s.Prepare(context.Background(), map[string]interface{}{
+		"o": map[string]interface{}{"rw": "B"},
+	}, &original, false)
+	doc, errs := s.Validate(changes, base)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]interface{}{
+		"o": map[string]interface{}{"ro": "a", "rw": "B", "updated": "now"},
+	}, doc)
+}
@@ -0,0 +1,212 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// ValidationError is a machine-readable validation error. All the built-in
+// validators return their errors as *ValidationError so clients can identify
+// them by Code and render their own message using the Params.
+type ValidationError struct {
+	// Code identifies the kind of error (i.e.: "too_short").
+	Code string `json:"code"`
+	// Params holds the values the message is built from (i.e.: "min").
+	Params map[string]interface{} `json:"params,omitempty"`
+	// Message is the rendered message of the error.
+	Message string `json:"message"`
+}
+
+// NewValidationError creates a validation error with the given code and params,
+// its message rendered using DefaultMessages.
+func NewValidationError(code string, params map[string]interface{}) *ValidationError {
+	err := &ValidationError{Code: code, Params: params}
+	err.Message = err.render(DefaultMessages)
+	return err
+}
+
+// Error implements the built-in error interface.
+func (err *ValidationError) Error() string {
+	return err.Message
+}
+
+// MarshalJSON implements the json.Marshaler interface. Errors found in params
+// are marshaled using their message unless they are validation errors.
+func (err *ValidationError) MarshalJSON() ([]byte, error) {
+	type validationError ValidationError
+	v := validationError(*err)
+	if len(err.Params) > 0 {
+		v.Params = make(map[string]interface{}, len(err.Params))
+		for k, p := range err.Params {
+			if e, ok := p.(error); ok {
+				if _, ok := e.(*ValidationError); !ok {
+					p = e.Error()
+				}
+			}
+			v.Params[k] = p
+		}
+	}
+	return json.Marshal(v)
+}
+
+// Localize returns a copy of err with its message, and the message of the
+// validation errors found in its params, rendered using c. The message is left
+// unchanged if c does not know the code.
+func (err *ValidationError) Localize(c MessageCatalog) *ValidationError {
+	l := &ValidationError{Code: err.Code, Params: err.Params, Message: err.Message}
+	if len(err.Params) > 0 {
+		l.Params = make(map[string]interface{}, len(err.Params))
+		for k, v := range err.Params {
+			if verr, ok := v.(*ValidationError); ok {
+				v = verr.Localize(c)
+			}
+			l.Params[k] = v
+		}
+	}
+	l.Message = l.render(c)
+	return l
+}
+
+func (err *ValidationError) render(c MessageCatalog) string {
+	if c != nil {
+		if msg, found := c.Message(err.Code, err.Params); found {
+			return msg
+		}
+	}
+	if err.Message != "" {
+		return err.Message
+	}
+	return err.Code
+}
+
+// MessageCatalog renders the message of a validation error from its code and
+// params. It must return false if the code is unknown.
+type MessageCatalog interface {
+	Message(code string, params map[string]interface{}) (string, bool)
+}
+
+// MessageCatalogFunc is an adapter to allow the use of ordinary functions as
+// message catalogs.
+type MessageCatalogFunc func(code string, params map[string]interface{}) (string, bool)
+
+// Message calls f(code, params).
+func (f MessageCatalogFunc) Message(code string, params map[string]interface{}) (string, bool) {
+	return f(code, params)
+}
+
+// Messages is a MessageCatalog holding a message template per code. Templates
+// reference params as {name}, or {name:%verb} to use a specific fmt verb. List
+// params are rendered as comma separated values.
+type Messages map[string]string
+
+// DefaultMessages holds the English messages of the built-in validators.
+var DefaultMessages = Messages{
+	"required":                "required",
+	"read_only":               "read-only",
+	"permission_denied":       "permission denied",
+	"invalid_field":           "invalid field",
+	"dependency_mismatch":     "does not match dependency: {dependency}",
+	"too_few_properties":      "has fewer properties than {min}",
+	"too_many_properties":     "has more properties than {max}",
+	"not_null":                "not null",
+	"not_boolean":             "not a Boolean",
+	"not_string":              "not a string",
+	"not_integer":             "not an integer",
+	"not_float":               "not a float",
+	"not_array":               "not an array",
+	"not_dict":                "not a dict",
+	"not_object":              "not an object",
+	"not_time":                "not a time",
+	"invalid_type":            "invalid type",
+	"too_short":               "is shorter than {min}",
+	"too_long":                "is longer than {max}",
+	"not_allowed":             "not one of [{allowed}]",
+	"pattern_mismatch":        "does not match {pattern}",
+	"integer_too_small":       "is lower than {min:%.0f}",
+	"integer_too_large":       "is greater than {max:%.0f}",
+	"float_too_small":         "is lower than {min:%.2f}",
+	"float_too_large":         "is greater than {max:%.2f}",
+	"not_allowed_value":       "not one of the allowed values",
+	"too_few_items":           "has fewer items than {min}",
+	"too_many_items":          "has more items than {max}",
+	"invalid_item":            "invalid value at #{index}: {error}",
+	"invalid_key":             "invalid key `{key}': {error}",
+	"invalid_key_type":        "key validator does not return string",
+	"invalid_key_value":       "invalid value for key `{key}': {error}",
+	"invalid_ip":              "invalid IP format",
+	"invalid_size":            "invalid size",
+	"invalid_url":             "invalid URL: {error}",
+	"relative_url":            "is relative URL",
+	"invalid_domain":          "invalid domain",
+	"invalid_scheme":          "invalid scheme",
+	"invalid_email":           "invalid e-mail address",
+	"domain_not_allowed":      "domain not one of [{allowed}]",
+	"invalid_uuid":            "invalid UUID",
+	"invalid_uuid_version":    "not a version {version} UUID",
+	"invalid_decimal":         "not a decimal",
+	"decimal_scale":           "has more than {scale} digits after the decimal point",
+	"decimal_precision":       "has more than {precision} digits",
+	"invalid_duration":        "not a duration",
+	"duration_too_short":      "is shorter than {min}",
+	"duration_too_long":       "is longer than {max}",
+	"invalid_geo_point":       "not a geo point",
+	"invalid_latitude":        "latitude out of range",
+	"invalid_longitude":       "longitude out of range",
+	"geo_point_out_of_bounds": "is out of bounds",
+	"not_unique":              "already used by another item",
+	"not_filterable":          "can't be filtered",
+	"not_encrypted":           "not an encrypted value",
+	"not_found":               "Not Found",
+	"no_storage":              "No Storage Defined",
+}
+
+var messageParam = regexp.MustCompile(`\{(\w+)(?::(%[^}]+))?\}`)
+
+// Message implements the MessageCatalog interface.
+func (m Messages) Message(code string, params map[string]interface{}) (string, bool) {
+	tpl, found := m[code]
+	if !found {
+		return "", false
+	}
+	return messageParam.ReplaceAllStringFunc(tpl, func(p string) string {
+		sub := messageParam.FindStringSubmatch(p)
+		v, found := params[sub[1]]
+		if !found {
+			return p
+		}
+		if sub[2] != "" {
+			return fmt.Sprintf(sub[2], v)
+		}
+		return formatParam(v)
+	}), true
+}
+
+func formatParam(v interface{}) string {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case []byte:
+		return string(v)
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		s := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			s = append(s, formatParam(rv.Index(i).Interface()))
+		}
+		return strings.Join(s, ", ")
+	}
+	return fmt.Sprint(v)
+}
+
+// fieldError returns the value to report in an error map for err. Validation
+// errors are kept as is so their code can be inspected, other errors are
+// reported using their message.
+func fieldError(err error) interface{} {
+	if verr, ok := err.(*ValidationError); ok {
+		return verr
+	}
+	return err.Error()
+}
@@ -0,0 +1,63 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationError(t *testing.T) {
+	_, err := schema.String{MinLen: 3}.Validate("a")
+	verr, ok := err.(*schema.ValidationError)
+	if assert.True(t, ok, "not a *schema.ValidationError") {
+		assert.Equal(t, "too_short", verr.Code)
+		assert.Equal(t, map[string]interface{}{"min": 3}, verr.Params)
+		assert.EqualError(t, verr, "is shorter than 3")
+	}
+
+	_, err = schema.Array{Values: schema.Field{Validator: &schema.Integer{}}}.Validate([]interface{}{1, "a"})
+	assert.EqualError(t, err, "invalid value at #2: not an integer")
+	verr = err.(*schema.ValidationError)
+	assert.Equal(t, "invalid_item", verr.Code)
+
+	_, err = schema.Float{Boundaries: &schema.Boundaries{Min: 1, Max: 2}}.Validate(3.0)
+	assert.EqualError(t, err, "is greater than 2.00")
+}
+
+func TestValidationErrorLocalize(t *testing.T) {
+	fr := schema.Messages{
+		"not_integer":  "n'est pas un entier",
+		"invalid_item": "valeur invalide en position {index} : {error}",
+		"not_allowed":  "n'est pas parmi {allowed}",
+	}
+	_, err := schema.Array{Values: schema.Field{Validator: &schema.Integer{}}}.Validate([]interface{}{1, "a"})
+	l := err.(*schema.ValidationError).Localize(fr)
+	assert.Equal(t, "valeur invalide en position 2 : n'est pas un entier", l.Message)
+	assert.Equal(t, "invalid value at #2: not an integer", err.Error(), "original must not be modified")
+
+	_, err = schema.String{Allowed: []string{"a", "b"}}.Validate("c")
+	assert.Equal(t, "n'est pas parmi a, b", err.(*schema.ValidationError).Localize(fr).Message)
+
+	// Unknown codes keep their message.
+	_, err = schema.String{}.Validate(1)
+	assert.Equal(t, "not a string", err.(*schema.ValidationError).Localize(fr).Message)
+
+	c := schema.MessageCatalogFunc(func(code string, params map[string]interface{}) (string, bool) {
+		return "ERR_" + code, true
+	})
+	assert.Equal(t, "ERR_not_string", err.(*schema.ValidationError).Localize(c).Message)
+}
+
+func TestValidationErrorMarshalJSON(t *testing.T) {
+	verr := schema.NewValidationError("invalid_url", map[string]interface{}{"error": errors.New("bad")})
+	b, err := json.Marshal(verr)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"code":"invalid_url","params":{"error":"bad"},"message":"invalid URL: bad"}`, string(b))
+
+	b, err = json.Marshal(schema.NewValidationError("required", nil))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"code":"required","message":"required"}`, string(b))
+}
@@ -24,7 +24,7 @@ func (v *Object) Compile(rc ReferenceChecker) error {
 func (v Object) Validate(value interface{}) (interface{}, error) {
 	obj, ok := value.(map[string]interface{})
 	if !ok {
-		return nil, errors.New("not an object")
+		return nil, NewValidationError("not_object", nil)
 	}
 	dest, errs := v.Schema.Validate(nil, obj)
 	if len(errs) > 0 {
@@ -39,3 +39,8 @@ func (v Object) Validate(value interface{}) (interface{}, error) {
 func (v Object) GetField(name string) *Field {
 	return v.Schema.GetField(name)
 }
+
+// DefaultComputedFields implements the ComputedFieldLister interface.
+func (v Object) DefaultComputedFields() []string {
+	return v.Schema.DefaultComputedFields()
+}
@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// OneOfSchema validates polymorphic objects: the schema used to validate an
+// object is selected by the value of its discriminator field (i.e.: a payment
+// object of type "card", "bank" or "wallet").
+//
+// When used as the validator of a field, the selected schema is handled as a
+// sub-schema by Schema.Prepare and Schema.Validate so defaults, hooks and
+// read-only fields apply to the object's fields.
+type OneOfSchema struct {
+	// Discriminator is the name of the field holding the type of the object.
+	// The field must be defined by all the schemas of Mapping.
+	Discriminator string
+	// Mapping maps the discriminator values to the schema of the objects.
+	Mapping map[string]*Schema
+}
+
+// Compile implements the ReferenceCompiler interface.
+func (v *OneOfSchema) Compile(rc ReferenceChecker) error {
+	if v.Discriminator == "" {
+		return errors.New("no discriminator defined")
+	}
+	if len(v.Mapping) == 0 {
+		return errors.New("no mapping defined")
+	}
+	for _, key := range v.Keys() {
+		s := v.Mapping[key]
+		if s == nil {
+			return fmt.Errorf("%s: no schema defined", key)
+		}
+		if _, found := s.Fields[v.Discriminator]; !found {
+			return fmt.Errorf("%s: discriminator field `%s' not defined", key, v.Discriminator)
+		}
+		if err := s.Compile(rc); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// Keys returns the sorted list of discriminator values.
+func (v OneOfSchema) Keys() []string {
+	keys := make([]string, 0, len(v.Mapping))
+	for key := range v.Mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Resolve returns the schema matching the discriminator value of doc.
+func (v OneOfSchema) Resolve(doc map[string]interface{}) (*Schema, error) {
+	value, found := doc[v.Discriminator]
+	if !found || value == nil {
+		return nil, NewValidationError("required", nil)
+	}
+	if key, ok := value.(string); ok {
+		if s, found := v.Mapping[key]; found && s != nil {
+			return s, nil
+		}
+	}
+	return nil, NewValidationError("not_allowed", map[string]interface{}{"allowed": v.Keys()})
+}
+
+// Validate implements FieldValidator interface.
+func (v OneOfSchema) Validate(value interface{}) (interface{}, error) {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, NewValidationError("not_object", nil)
+	}
+	s, err := v.Resolve(obj)
+	if err != nil {
+		return nil, ErrorMap{v.Discriminator: {fieldError(err)}}
+	}
+	dest, errs := s.Validate(nil, obj)
+	if len(errs) > 0 {
+		return nil, ErrorMap(errs)
+	}
+	return dest, nil
+}
+
+// GetField implements the FieldGetter interface. As the type of the object is
+// not known, the field is looked up in the schemas in the order of their
+// discriminator value.
+func (v OneOfSchema) GetField(name string) *Field {
+	for _, key := range v.Keys() {
+		if f := v.Mapping[key].GetField(name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPaymentValidator() *schema.OneOfSchema {
+	return &schema.OneOfSchema{
+		Discriminator: "type",
+		Mapping: map[string]*schema.Schema{
+			"card": {
+				Fields: schema.Fields{
+					"type":   {Required: true, Validator: &schema.String{}},
+					"number": {Required: true, Validator: &schema.String{MinLen: 4}},
+					"brand":  {ReadOnly: true, Default: "visa", Validator: &schema.String{}},
+				},
+			},
+			"bank": {
+				Fields: schema.Fields{
+					"type": {Required: true, Validator: &schema.String{}},
+					"iban": {Required: true, Validator: &schema.String{}},
+				},
+			},
+		},
+	}
+}
+
+func TestOneOfSchemaCompile(t *testing.T) {
+	assert.NoError(t, newPaymentValidator().Compile(nil))
+	assert.EqualError(t, (&schema.OneOfSchema{}).Compile(nil), "no discriminator defined")
+	assert.EqualError(t, (&schema.OneOfSchema{Discriminator: "type"}).Compile(nil), "no mapping defined")
+	v := newPaymentValidator()
+	v.Mapping["wallet"] = &schema.Schema{Fields: schema.Fields{"id": {}}}
+	assert.EqualError(t, v.Compile(nil), "wallet: discriminator field `type' not defined")
+}
+
+func TestOneOfSchemaValidator(t *testing.T) {
+	v := newPaymentValidator()
+	doc, err := v.Validate(map[string]interface{}{"type": "bank", "iban": "FR76"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"type": "bank", "iban": "FR76"}, doc)
+
+	_, err = v.Validate(map[string]interface{}{"type": "bank", "number": "1234"})
+	assert.Equal(t, schema.ErrorMap{
+		"iban":   {schema.NewValidationError("required", nil)},
+		"number": {schema.NewValidationError("invalid_field", nil)},
+	}, err)
+
+	_, err = v.Validate(map[string]interface{}{"type": "wallet"})
+	assert.Equal(t, schema.ErrorMap{"type": {schema.NewValidationError("not_allowed", map[string]interface{}{"allowed": []string{"bank", "card"}})}}, err)
+
+	_, err = v.Validate(map[string]interface{}{"iban": "FR76"})
+	assert.Equal(t, schema.ErrorMap{"type": {schema.NewValidationError("required", nil)}}, err)
+
+	_, err = v.Validate("card")
+	assert.Equal(t, schema.NewValidationError("not_object", nil), err)
+
+	assert.NotNil(t, v.GetField("iban"))
+	assert.NotNil(t, v.GetField("number"))
+	assert.Nil(t, v.GetField("unknown"))
+}
+
+func TestOneOfSchemaField(t *testing.T) {
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"payment": {Validator: newPaymentValidator()},
+		},
+	}
+	assert.NoError(t, s.Compile(nil))
+	ctx := context.Background()
+
+	// The selected schema applies defaults.
+	changes, base := s.Prepare(ctx, map[string]interface{}{"payment": map[string]interface{}{"type": "card", "number": "4242"}}, nil, false)
+	doc, errs := s.Validate(changes, base)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]interface{}{"payment": map[string]interface{}{"type": "card", "number": "4242", "brand": "visa"}}, doc)
+
+	// Fields are merged on update and read-only fields are enforced.
+	original := doc
+	changes, base = s.Prepare(ctx, map[string]interface{}{"payment": map[string]interface{}{"number": "1234"}}, &original, false)
+	doc, errs = s.Validate(changes, base)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]interface{}{"payment": map[string]interface{}{"type": "card", "number": "1234", "brand": "visa"}}, doc)
+	changes, base = s.Prepare(ctx, map[string]interface{}{"payment": map[string]interface{}{"brand": "amex"}}, &original, false)
+	_, errs = s.Validate(changes, base)
+	assert.Equal(t, map[string][]interface{}{"payment": {map[string][]interface{}{"brand": {schema.NewValidationError("read_only", nil)}}}}, errs)
+
+	// Changing the type selects the other schema.
+	changes, base = s.Prepare(ctx, map[string]interface{}{"payment": map[string]interface{}{"type": "bank", "iban": "FR76"}}, &original, true)
+	doc, errs = s.Validate(changes, base)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]interface{}{"payment": map[string]interface{}{"type": "bank", "iban": "FR76"}}, doc)
+
+	// Unknown types are reported on the discriminator.
+	changes, base = s.Prepare(ctx, map[string]interface{}{"payment": map[string]interface{}{"type": "wallet"}}, nil, false)
+	_, errs = s.Validate(changes, base)
+	assert.Equal(t, map[string][]interface{}{"payment": {map[string][]interface{}{"type": {schema.NewValidationError("not_allowed", map[string]interface{}{"allowed": []string{"bank", "card"}})}}}}, errs)
+
+	// Sub-fields can be looked up for filtering.
+	assert.NotNil(t, s.GetField("payment.iban"))
+}
@@ -1,9 +1,6 @@
 package schema
 
 import (
-	"errors"
-	"fmt"
-
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -39,14 +36,14 @@ func (v Password) Validate(value interface{}) (interface{}, error) {
 				return b, nil
 			}
 		}
-		return nil, errors.New("not a string")
+		return nil, NewValidationError("not_string", nil)
 	}
 	l := len(s)
 	if l < v.MinLen {
-		return nil, fmt.Errorf("is shorter than %d", v.MinLen)
+		return nil, NewValidationError("too_short", map[string]interface{}{"min": v.MinLen})
 	}
 	if v.MaxLen > 0 && l > v.MaxLen {
-		return nil, fmt.Errorf("is longer than %d", v.MaxLen)
+		return nil, NewValidationError("too_long", map[string]interface{}{"max": v.MaxLen})
 	}
 	b, err := bcrypt.GenerateFromPassword([]byte(s), v.Cost)
 	if err != nil {
@@ -0,0 +1,365 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+// AccumulatorOp defines the operation computed by an Accumulator over the items
+// of a group.
+type AccumulatorOp string
+
+const (
+	// AccumulatorCount counts the items of the group.
+	AccumulatorCount AccumulatorOp = "count"
+	// AccumulatorSum sums the values of a numeric field.
+	AccumulatorSum AccumulatorOp = "sum"
+	// AccumulatorAvg averages the values of a numeric field.
+	AccumulatorAvg AccumulatorOp = "avg"
+	// AccumulatorMin returns the lowest value of a comparable field.
+	AccumulatorMin AccumulatorOp = "min"
+	// AccumulatorMax returns the highest value of a comparable field.
+	AccumulatorMax AccumulatorOp = "max"
+)
+
+// Accumulator defines a value computed over the items of a group.
+type Accumulator struct {
+	// Name is the name of the computed value in the resulting groups.
+	Name string
+	// Op is the operation to compute.
+	Op AccumulatorOp
+	// Field is the field the operation is computed on. Field is not used by
+	// the count operation.
+	Field string
+
+	less schema.LessFunc
+}
+
+// Aggregation groups the items matching a query by the value of some fields
+// and computes accumulators over each group.
+type Aggregation struct {
+	// GroupBy is the list of fields to group items on. When empty, all the
+	// items are aggregated in a single group.
+	GroupBy []string
+	// Accumulators is the list of values to compute on each group.
+	Accumulators []Accumulator
+
+	less []schema.LessFunc
+}
+
+// MustParseAggregation parses an aggregation expression and panics in case of
+// error.
+func MustParseAggregation(aggregation string) *Aggregation {
+	a, err := ParseAggregation(aggregation)
+	if err != nil {
+		panic(fmt.Sprintf("query: ParseAggregation(%q): %v", aggregation, err))
+	}
+	return a
+}
+
+// ParseAggregation parses an aggregation expression. An aggregation expression
+// is a list of group-by fields and accumulators separated by comas.
+// Accumulators are expressed as function calls on a field (i.e.: sum(price))
+// and may be given a name using the same notation as projection aliases (i.e.:
+// total:sum(price)). The count accumulator takes no field (i.e.: count()).
+//
+// Example:
+//
+//   country,count(),total:sum(price),max(age)
+//
+// Groups items by country and computes the number of items, the sum of prices
+// and the highest age of each group. When not named, accumulators are named
+// after their operation, followed by the field for operations on a field (i.e.:
+// count, total and max_age).
+func ParseAggregation(aggregation string) (*Aggregation, error) {
+	if strings.Trim(aggregation, " ") == "" {
+		return nil, errors.New("empty aggregation")
+	}
+	a := &Aggregation{}
+	for _, exp := range strings.Split(aggregation, ",") {
+		exp = strings.Trim(exp, " ")
+		name := ""
+		if i := strings.IndexByte(exp, ':'); i != -1 {
+			name = strings.Trim(exp[:i], " ")
+			exp = strings.Trim(exp[i+1:], " ")
+			if name == "" {
+				return nil, fmt.Errorf("%s: empty accumulator name", exp)
+			}
+		}
+		if exp == "" {
+			return nil, errors.New("empty aggregation field")
+		}
+		i := strings.IndexByte(exp, '(')
+		if i == -1 {
+			if name != "" {
+				return nil, fmt.Errorf("%s: group-by fields can't be named", exp)
+			}
+			a.GroupBy = append(a.GroupBy, exp)
+			continue
+		}
+		if exp[len(exp)-1] != ')' {
+			return nil, fmt.Errorf("%s: missing closing parenthesis", exp)
+		}
+		acc := Accumulator{
+			Op:    AccumulatorOp(strings.Trim(exp[:i], " ")),
+			Field: strings.Trim(exp[i+1:len(exp)-1], " "),
+		}
+		switch acc.Op {
+		case AccumulatorCount:
+			if acc.Field != "" {
+				return nil, fmt.Errorf("%s: count takes no field", exp)
+			}
+		case AccumulatorSum, AccumulatorAvg, AccumulatorMin, AccumulatorMax:
+			if acc.Field == "" {
+				return nil, fmt.Errorf("%s: missing field", exp)
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown accumulator", exp)
+		}
+		if acc.Name = name; acc.Name == "" {
+			acc.Name = string(acc.Op)
+			if acc.Field != "" {
+				acc.Name += "_" + acc.Field
+			}
+		}
+		a.Accumulators = append(a.Accumulators, acc)
+	}
+	return a, nil
+}
+
+// Prepare validates the aggregation against the provided validator and
+// prepares it for evaluation. Fields used by an aggregation must be
+// filterable and not hidden. Sum and average require numeric fields and min
+// and max require comparable fields.
+func (a *Aggregation) Prepare(validator schema.Validator) error {
+	for _, field := range a.Fields() {
+		for _, def := range fieldPath(field, validator) {
+			if def.Hidden {
+				return fmt.Errorf("%s: hidden field", field)
+			}
+		}
+	}
+	names := map[string]bool{}
+	a.less = make([]schema.LessFunc, len(a.GroupBy))
+	for i, field := range a.GroupBy {
+		f, err := getValidatorField(field, validator)
+		if err != nil {
+			return err
+		}
+		if names[field] {
+			return fmt.Errorf("%s: duplicated aggregation name", field)
+		}
+		names[field] = true
+		if fc, ok := f.Validator.(schema.FieldComparator); ok {
+			a.less[i] = fc.LessFunc()
+		}
+	}
+	for i := range a.Accumulators {
+		acc := &a.Accumulators[i]
+		if names[acc.Name] {
+			return fmt.Errorf("%s: duplicated aggregation name", acc.Name)
+		}
+		names[acc.Name] = true
+		switch acc.Op {
+		case AccumulatorCount:
+		case AccumulatorSum, AccumulatorAvg:
+			f, err := getValidatorField(acc.Field, validator)
+			if err != nil {
+				return err
+			}
+			switch f.Validator.(type) {
+			case *schema.Integer, *schema.Float:
+			default:
+				return fmt.Errorf("%s: not a numeric field", acc.Field)
+			}
+		case AccumulatorMin, AccumulatorMax:
+			less, err := getLessFunc(acc.Field, validator)
+			if err != nil {
+				return err
+			}
+			acc.less = less
+		default:
+			return fmt.Errorf("%s: unknown accumulator", acc.Op)
+		}
+	}
+	return nil
+}
+
+// Fields returns the fields used by the aggregation, group-by fields first.
+func (a *Aggregation) Fields() []string {
+	fields := make([]string, 0, len(a.GroupBy)+len(a.Accumulators))
+	fields = append(fields, a.GroupBy...)
+	for _, acc := range a.Accumulators {
+		if acc.Field != "" {
+			fields = append(fields, acc.Field)
+		}
+	}
+	return fields
+}
+
+// CheckReadPermission returns an error if the caller identified by ctx may not
+// read one of the fields used by the aggregation, or one of their parents. As
+// the aggregated values come from many items, read permissions are called with
+// a nil document: fields guarded by a permission depending on the document
+// can't be aggregated.
+func (a *Aggregation) CheckReadPermission(ctx context.Context, validator schema.Validator) error {
+	for _, field := range a.Fields() {
+		if err := checkFieldReadPermission(ctx, field, validator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFieldReadPermission returns an error if the caller identified by ctx
+// may not read field or one of its parents.
+func checkFieldReadPermission(ctx context.Context, field string, validator schema.Validator) error {
+	for _, def := range fieldPath(field, validator) {
+		if !def.CanRead(ctx, nil) {
+			return fmt.Errorf("%s: permission denied", field)
+		}
+	}
+	return nil
+}
+
+// fieldPath returns the definitions of a field and of its parents, from the
+// top level field.
+func fieldPath(field string, validator schema.Validator) []*schema.Field {
+	var defs []*schema.Field
+	for i := 0; i <= len(field); i++ {
+		if i < len(field) && field[i] != '.' {
+			continue
+		}
+		if def := validator.GetField(field[:i]); def != nil {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// aggregationGroup holds the state of a group during the evaluation of an
+// aggregation.
+type aggregationGroup struct {
+	keys   []interface{}
+	count  int
+	sums   []float64
+	counts []int
+	values []interface{}
+}
+
+// Eval aggregates the provided payloads. Groups are sorted by their group-by
+// values. The count of a group is returned as an int, sums and averages as
+// float64, and min and max as the field value. Averages, min and max are nil
+// when the field has no value in the group.
+func (a *Aggregation) Eval(payloads []map[string]interface{}) []map[string]interface{} {
+	groups := map[string]*aggregationGroup{}
+	order := []*aggregationGroup{}
+	for _, payload := range payloads {
+		keys := make([]interface{}, len(a.GroupBy))
+		ids := make([]string, len(a.GroupBy))
+		for i, field := range a.GroupBy {
+			keys[i] = getField(payload, field)
+			ids[i] = valueString(keys[i])
+		}
+		id := strings.Join(ids, "\x00")
+		g, found := groups[id]
+		if !found {
+			g = &aggregationGroup{
+				keys:   keys,
+				sums:   make([]float64, len(a.Accumulators)),
+				counts: make([]int, len(a.Accumulators)),
+				values: make([]interface{}, len(a.Accumulators)),
+			}
+			groups[id] = g
+			order = append(order, g)
+		}
+		g.count++
+		for i, acc := range a.Accumulators {
+			if acc.Op == AccumulatorCount {
+				continue
+			}
+			v := getField(payload, acc.Field)
+			if v == nil || (acc.less == nil && (acc.Op == AccumulatorMin || acc.Op == AccumulatorMax)) {
+				// Min and max are only computed on prepared aggregations.
+				continue
+			}
+			switch acc.Op {
+			case AccumulatorSum, AccumulatorAvg:
+				if n, ok := isNumber(v); ok {
+					g.sums[i] += n
+					g.counts[i]++
+				}
+			case AccumulatorMin:
+				if g.values[i] == nil || acc.less(v, g.values[i]) {
+					g.values[i] = v
+				}
+			case AccumulatorMax:
+				if g.values[i] == nil || acc.less(g.values[i], v) {
+					g.values[i] = v
+				}
+			}
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return a.lessKeys(order[i].keys, order[j].keys)
+	})
+	results := make([]map[string]interface{}, 0, len(order))
+	for _, g := range order {
+		r := make(map[string]interface{}, len(a.GroupBy)+len(a.Accumulators))
+		for i, field := range a.GroupBy {
+			r[field] = g.keys[i]
+		}
+		for i, acc := range a.Accumulators {
+			switch acc.Op {
+			case AccumulatorCount:
+				r[acc.Name] = g.count
+			case AccumulatorSum:
+				r[acc.Name] = g.sums[i]
+			case AccumulatorAvg:
+				if g.counts[i] > 0 {
+					r[acc.Name] = g.sums[i] / float64(g.counts[i])
+				} else {
+					r[acc.Name] = nil
+				}
+			case AccumulatorMin, AccumulatorMax:
+				r[acc.Name] = g.values[i]
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// lessKeys compares the group-by values of two groups. Nil values come first,
+// values of non comparable fields are compared by their string representation.
+func (a *Aggregation) lessKeys(k1, k2 []interface{}) bool {
+	for i := range k1 {
+		v1, v2 := k1[i], k2[i]
+		switch {
+		case v1 == nil && v2 == nil:
+			continue
+		case v1 == nil:
+			return true
+		case v2 == nil:
+			return false
+		}
+		if i < len(a.less) && a.less[i] != nil {
+			if a.less[i](v1, v2) {
+				return true
+			}
+			if a.less[i](v2, v1) {
+				return false
+			}
+			continue
+		}
+		if s1, s2 := valueString(v1), valueString(v2); s1 != s2 {
+			return s1 < s2
+		}
+	}
+	return false
+}
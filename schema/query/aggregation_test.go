@@ -0,0 +1,150 @@
+package query
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+func TestParseAggregation(t *testing.T) {
+	tests := []struct {
+		aggregation string
+		want        *Aggregation
+		err         string
+	}{
+		{"country", &Aggregation{GroupBy: []string{"country"}}, ""},
+		{"country, city,count()", &Aggregation{
+			GroupBy:      []string{"country", "city"},
+			Accumulators: []Accumulator{{Name: "count", Op: AccumulatorCount}},
+		}, ""},
+		{"total:sum(price),avg( age ),max(meta.score)", &Aggregation{
+			Accumulators: []Accumulator{
+				{Name: "total", Op: AccumulatorSum, Field: "price"},
+				{Name: "avg_age", Op: AccumulatorAvg, Field: "age"},
+				{Name: "max_meta.score", Op: AccumulatorMax, Field: "meta.score"},
+			},
+		}, ""},
+		{"", nil, "empty aggregation"},
+		{"country,", nil, "empty aggregation field"},
+		{"c:country", nil, "country: group-by fields can't be named"},
+		{":count()", nil, "count(): empty accumulator name"},
+		{"count(age)", nil, "count(age): count takes no field"},
+		{"sum()", nil, "sum(): missing field"},
+		{"sum(age", nil, "sum(age: missing closing parenthesis"},
+		{"median(age)", nil, "median(age): unknown accumulator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.aggregation, func(t *testing.T) {
+			got, err := ParseAggregation(tt.aggregation)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("ParseAggregation() error = %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAggregation() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAggregation() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregationPrepare(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"country": {Filterable: true},
+		"name":    {},
+		"age":     {Filterable: true, Validator: &schema.Integer{}},
+		"tag":     {Filterable: true, Validator: &schema.String{}},
+		"secret":  {Filterable: true, Hidden: true, Validator: &schema.Integer{}},
+		"meta": {Hidden: true, Schema: &schema.Schema{Fields: schema.Fields{
+			"x": {Filterable: true, Validator: &schema.Integer{}},
+		}}},
+	}}
+	tests := map[string]string{
+		"country,count(),sum(age),min(age)": "",
+		"name,count()":                      "name: field is not filterable",
+		"unknown":                           "unknown: unknown query field",
+		"sum(tag)":                          "tag: not a numeric field",
+		"max(tag)":                          "tag: not-comparable",
+		"country,country:count()":           "country: duplicated aggregation name",
+		"max(secret)":                       "secret: hidden field",
+		"meta.x,count()":                    "meta.x: hidden field",
+	}
+	for aggregation, want := range tests {
+		t.Run(aggregation, func(t *testing.T) {
+			err := MustParseAggregation(aggregation).Prepare(s)
+			if (err == nil && want != "") || (err != nil && err.Error() != want) {
+				t.Errorf("Prepare() error = %v, want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestAggregationEval(t *testing.T) {
+	now := time.Now()
+	s := schema.Schema{Fields: schema.Fields{
+		"country": {Filterable: true},
+		"age":     {Filterable: true, Validator: &schema.Integer{}},
+		"created": {Filterable: true, Validator: &schema.Time{}},
+	}}
+	payloads := []map[string]interface{}{
+		{"country": "US", "age": 20, "created": now},
+		{"country": "FR", "age": 30, "created": now.Add(time.Hour)},
+		{"country": "FR", "age": 40, "created": now.Add(-time.Hour)},
+		{"created": now},
+	}
+	tests := []struct {
+		aggregation string
+		want        []map[string]interface{}
+	}{
+		{"country,count(),avg(age),first:min(created),max(age)", []map[string]interface{}{
+			{"country": nil, "count": 1, "avg_age": nil, "first": now, "max_age": nil},
+			{"country": "FR", "count": 2, "avg_age": 35.0, "first": now.Add(-time.Hour), "max_age": 40},
+			{"country": "US", "count": 1, "avg_age": 20.0, "first": now, "max_age": 20},
+		}},
+		{"count(),sum(age)", []map[string]interface{}{
+			{"count": 4, "sum_age": 90.0},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.aggregation, func(t *testing.T) {
+			a := MustParseAggregation(tt.aggregation)
+			if err := a.Prepare(s); err != nil {
+				t.Fatal(err)
+			}
+			if got := a.Eval(payloads); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eval() =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregationCheckReadPermission(t *testing.T) {
+	deny := func(ctx context.Context, doc map[string]interface{}) bool { return false }
+	s := schema.Schema{Fields: schema.Fields{
+		"country": {Filterable: true},
+		"salary":  {Filterable: true, ReadPermission: deny, Validator: &schema.Integer{}},
+		"meta": {ReadPermission: deny, Schema: &schema.Schema{Fields: schema.Fields{
+			"x": {Filterable: true, Validator: &schema.Integer{}},
+		}}},
+	}}
+	tests := map[string]string{
+		"country,count()": "",
+		"avg(salary)":     "salary: permission denied",
+		"meta.x,count()":  "meta.x: permission denied",
+	}
+	for aggregation, want := range tests {
+		t.Run(aggregation, func(t *testing.T) {
+			err := MustParseAggregation(aggregation).CheckReadPermission(context.Background(), s)
+			if (err == nil && want != "") || (err != nil && err.Error() != want) {
+				t.Errorf("CheckReadPermission() error = %v, want %q", err, want)
+			}
+		})
+	}
+}
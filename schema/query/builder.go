@@ -0,0 +1,271 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+// Builder is a composable predicate expression built from Go code. Builders
+// are created using Field and combined using their And, Or, Nor and Not
+// methods:
+//
+//   query.Field("age").Gte(18).And(query.Field("tags").In("a", "b"))
+//
+// Values are stored as given and never formatted into a query string, so
+// they don't need any escaping.
+type Builder struct {
+	exp Expression
+	err error
+}
+
+// FieldBuilder creates expressions on a field.
+type FieldBuilder struct {
+	name string
+}
+
+// Field returns a FieldBuilder creating expressions on the named field. Sub
+// fields are addressed using the dot notation (i.e.: foo.bar).
+func Field(name string) FieldBuilder {
+	return FieldBuilder{name: name}
+}
+
+// Eq matches values equal to v.
+func (f FieldBuilder) Eq(v Value) Builder {
+	return Builder{exp: &Equal{Field: f.name, Value: v}}
+}
+
+// EqFold matches strings equal to s without regard to case.
+func (f FieldBuilder) EqFold(s string) Builder {
+	return Builder{exp: &Equal{Field: f.name, Value: s, CaseInsensitive: true}}
+}
+
+// Ne matches values not equal to v.
+func (f FieldBuilder) Ne(v Value) Builder {
+	return Builder{exp: &NotEqual{Field: f.name, Value: v}}
+}
+
+// Gt matches values greater than v.
+func (f FieldBuilder) Gt(v Value) Builder {
+	return Builder{exp: &GreaterThan{Field: f.name, Value: v}}
+}
+
+// Gte matches values greater than or equal to v.
+func (f FieldBuilder) Gte(v Value) Builder {
+	return Builder{exp: &GreaterOrEqual{Field: f.name, Value: v}}
+}
+
+// Lt matches values lower than v.
+func (f FieldBuilder) Lt(v Value) Builder {
+	return Builder{exp: &LowerThan{Field: f.name, Value: v}}
+}
+
+// Lte matches values lower than or equal to v.
+func (f FieldBuilder) Lte(v Value) Builder {
+	return Builder{exp: &LowerOrEqual{Field: f.name, Value: v}}
+}
+
+// In matches any of the values.
+func (f FieldBuilder) In(values ...Value) Builder {
+	return Builder{exp: &In{Field: f.name, Values: values}}
+}
+
+// Nin matches none of the values.
+func (f FieldBuilder) Nin(values ...Value) Builder {
+	return Builder{exp: &NotIn{Field: f.name, Values: values}}
+}
+
+// All matches arrays containing all the values.
+func (f FieldBuilder) All(values ...Value) Builder {
+	return Builder{exp: &All{Field: f.name, Values: values}}
+}
+
+// Size matches arrays of n items.
+func (f FieldBuilder) Size(n int) Builder {
+	if n < 0 {
+		return Builder{err: fmt.Errorf("%s: %s: must be positive", f.name, opSize)}
+	}
+	return Builder{exp: &Size{Field: f.name, Value: n}}
+}
+
+// Type matches values of type t (i.e.: TypeString).
+func (f FieldBuilder) Type(t string) Builder {
+	if !isType(t) {
+		return Builder{err: fmt.Errorf("%s: %s: unknown type %q", f.name, opType, t)}
+	}
+	return Builder{exp: &Type{Field: f.name, Type: t}}
+}
+
+// Mod matches numbers for which the remainder of the division by divisor is
+// remainder.
+func (f FieldBuilder) Mod(divisor, remainder int64) Builder {
+	if divisor == 0 {
+		return Builder{err: fmt.Errorf("%s: %s: divisor can't be 0", f.name, opMod)}
+	}
+	return Builder{exp: &Mod{Field: f.name, Divisor: divisor, Remainder: remainder}}
+}
+
+// Exists matches documents containing the field, even if nil.
+func (f FieldBuilder) Exists() Builder {
+	return Builder{exp: &Exist{Field: f.name}}
+}
+
+// NotExists matches documents not containing the field.
+func (f FieldBuilder) NotExists() Builder {
+	return Builder{exp: &NotExist{Field: f.name}}
+}
+
+// Regex matches values matching the regular expression pattern. An invalid
+// pattern is reported when the predicate is built.
+func (f FieldBuilder) Regex(pattern string) Builder {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Builder{err: fmt.Errorf("%s: %s: invalid regex: %v", f.name, opRegex, err)}
+	}
+	return Builder{exp: &Regex{Field: f.name, Value: re}}
+}
+
+// Near matches geo points within maxDistance meters of point. There is no
+// distance limit if maxDistance is zero.
+func (f FieldBuilder) Near(point GeoCoordinates, maxDistance float64) Builder {
+	if maxDistance < 0 {
+		return Builder{err: fmt.Errorf("%s: %s: %s: must be positive", f.name, opNear, opMaxDistance)}
+	}
+	return Builder{exp: &Near{Field: f.name, Point: point, MaxDistance: maxDistance}}
+}
+
+// GeoWithin matches geo points within shape (i.e.: GeoCircle).
+func (f FieldBuilder) GeoWithin(shape GeoShape) Builder {
+	if shape == nil {
+		return Builder{err: fmt.Errorf("%s: %s: shape required", f.name, opGeoWithin)}
+	}
+	return Builder{exp: &GeoWithin{Field: f.name, Shape: shape}}
+}
+
+// Search matches strings containing all the terms of a full-text search
+// query (see Search).
+func (f FieldBuilder) Search(query string) Builder {
+	if len(SearchTerms(query)) == 0 {
+		return Builder{err: fmt.Errorf("%s: %s: no search terms", f.name, opSearch)}
+	}
+	return Builder{exp: &Search{Field: f.name, Value: query}}
+}
+
+// ElemMatch matches arrays with at least one item matching all the builders.
+// Field names of the builders are relative to the array items.
+func (f FieldBuilder) ElemMatch(builders ...Builder) Builder {
+	exps, err := builderExpressions(builders)
+	if err != nil {
+		return Builder{err: err}
+	}
+	return Builder{exp: &ElemMatch{Field: f.name, Exps: exps}}
+}
+
+// And matches documents matching b and all the others.
+func (b Builder) And(others ...Builder) Builder {
+	exps, err := builderExpressions(append([]Builder{b}, others...))
+	if err != nil {
+		return Builder{err: err}
+	}
+	and := And{}
+	for _, exp := range exps {
+		// Flatten chained calls to And.
+		if sub, ok := exp.(*And); ok {
+			and = append(and, *sub...)
+			continue
+		}
+		and = append(and, exp)
+	}
+	return Builder{exp: &and}
+}
+
+// Or matches documents matching b or any of the others.
+func (b Builder) Or(others ...Builder) Builder {
+	exps, err := builderExpressions(append([]Builder{b}, others...))
+	if err != nil {
+		return Builder{err: err}
+	}
+	or := Or{}
+	for _, exp := range exps {
+		// Flatten chained calls to Or.
+		if sub, ok := exp.(*Or); ok {
+			or = append(or, *sub...)
+			continue
+		}
+		or = append(or, exp)
+	}
+	return Builder{exp: &or}
+}
+
+// Nor matches documents matching neither b nor any of the others.
+func (b Builder) Nor(others ...Builder) Builder {
+	exps, err := builderExpressions(append([]Builder{b}, others...))
+	if err != nil {
+		return Builder{err: err}
+	}
+	nor := Nor(exps)
+	return Builder{exp: &nor}
+}
+
+// Not matches documents not matching b.
+func (b Builder) Not() Builder {
+	if b.err != nil || b.exp == nil {
+		return b
+	}
+	return Builder{exp: &Not{b.exp}}
+}
+
+// Predicate returns the predicate built by b without validating it. An error
+// is returned if one of the combined expressions is invalid.
+func (b Builder) Predicate() (Predicate, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.exp == nil {
+		return Predicate{}, nil
+	}
+	// A top level And is expressed as a list of expressions.
+	if and, ok := b.exp.(*And); ok {
+		return Predicate(*and), nil
+	}
+	return Predicate{b.exp}, nil
+}
+
+// Build returns the predicate built by b, validated and prepared against the
+// provided validator. Unknown or non filterable fields as well as invalid
+// values are reported as errors.
+func (b Builder) Build(validator schema.Validator) (Predicate, error) {
+	p, err := b.Predicate()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Prepare(validator); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// MustBuild is like Build but panics in case of error.
+func (b Builder) MustBuild(validator schema.Validator) Predicate {
+	p, err := b.Build(validator)
+	if err != nil {
+		panic(fmt.Sprintf("query: Build: %v", err))
+	}
+	return p
+}
+
+// builderExpressions returns the expressions of builders, or the first error
+// of the builders.
+func builderExpressions(builders []Builder) ([]Expression, error) {
+	exps := make([]Expression, 0, len(builders))
+	for _, b := range builders {
+		if b.err != nil {
+			return nil, b.err
+		}
+		if b.exp != nil {
+			exps = append(exps, b.exp)
+		}
+	}
+	return exps, nil
+}
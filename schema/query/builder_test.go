@@ -0,0 +1,155 @@
+package query
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Builder
+		want Predicate
+		str  string
+	}{
+		{
+			"and",
+			Field("age").Gte(18).And(Field("tags").In("a", "b")),
+			Predicate{&GreaterOrEqual{Field: "age", Value: 18}, &In{Field: "tags", Values: []Value{"a", "b"}}},
+			`{age: {$gte: 18}, tags: {$in: ["a", "b"]}}`,
+		},
+		{
+			"chained and",
+			Field("a").Eq(1).And(Field("b").Eq(2)).And(Field("c").Eq(3)),
+			Predicate{&Equal{Field: "a", Value: 1}, &Equal{Field: "b", Value: 2}, &Equal{Field: "c", Value: 3}},
+			`{a: 1, b: 2, c: 3}`,
+		},
+		{
+			"or",
+			Field("a").Eq("x").Or(Field("b").Ne("y").And(Field("c").Exists())),
+			Predicate{&Or{&Equal{Field: "a", Value: "x"}, &And{&NotEqual{Field: "b", Value: "y"}, &Exist{Field: "c"}}}},
+			`{$or: [{a: "x"}, {$and: [{b: {$ne: "y"}}, {c: {$exists: true}}]}]}`,
+		},
+		{
+			"not",
+			Field("a").Gt(1).Not(),
+			Predicate{&Not{&GreaterThan{Field: "a", Value: 1}}},
+			`{$not: {a: {$gt: 1}}}`,
+		},
+		{
+			"nor",
+			Field("a").Lt(1).Nor(Field("b").Lte(2)),
+			Predicate{&Nor{&LowerThan{Field: "a", Value: 1}, &LowerOrEqual{Field: "b", Value: 2}}},
+			`{$nor: [{a: {$lt: 1}}, {b: {$lte: 2}}]}`,
+		},
+		{
+			"escaping",
+			Field("a").Eq(`"}, {$or: [{b: 1}]}`),
+			Predicate{&Equal{Field: "a", Value: `"}, {$or: [{b: 1}]}`}},
+			`{a: "\"}, {$or: [{b: 1}]}"}`,
+		},
+		{
+			"array",
+			Field("a").All("x").And(Field("b").Size(2), Field("c").Nin(1), Field("d").ElemMatch(Field("e").Eq(1))),
+			Predicate{
+				&All{Field: "a", Values: []Value{"x"}},
+				&Size{Field: "b", Value: 2},
+				&NotIn{Field: "c", Values: []Value{1}},
+				&ElemMatch{Field: "d", Exps: []Expression{&Equal{Field: "e", Value: 1}}},
+			},
+			`{a: {$all: ["x"]}, b: {$size: 2}, c: {$nin: [1]}, d: {$elemMatch: {e: 1}}}`,
+		},
+		{
+			"misc",
+			Field("a").Type(TypeString).And(Field("b").Mod(2, 1), Field("c").NotExists(), Field("d").EqFold("x"), Field("e").Regex("^f")),
+			Predicate{
+				&Type{Field: "a", Type: "string"},
+				&Mod{Field: "b", Divisor: 2, Remainder: 1},
+				&NotExist{Field: "c"},
+				&Equal{Field: "d", Value: "x", CaseInsensitive: true},
+				&Regex{Field: "e", Value: regexp.MustCompile("^f")},
+			},
+			`{a: {$type: "string"}, b: {$mod: [2, 1]}, c: {$exists: false}, d: {$eq: "x", $options: "i"}, e: {$regex: "^f"}}`,
+		},
+		{
+			"geo",
+			Field("a").Near(GeoCoordinates{Lng: 2.35, Lat: 48.85}, 1000).And(Field("b").GeoWithin(GeoCircle{Center: GeoCoordinates{Lng: 1, Lat: 2}, Radius: 10})),
+			Predicate{
+				&Near{Field: "a", Point: GeoCoordinates{Lng: 2.35, Lat: 48.85}, MaxDistance: 1000},
+				&GeoWithin{Field: "b", Shape: GeoCircle{Center: GeoCoordinates{Lng: 1, Lat: 2}, Radius: 10}},
+			},
+			`{a: {$near: [2.35, 48.85], $maxDistance: 1000}, b: {$geoWithin: {$circle: [[1, 2], 10]}}}`,
+		},
+		{
+			"search",
+			Field("a").Search("quick br*"),
+			Predicate{&Search{Field: "a", Value: "quick br*"}},
+			`{a: {$search: "quick br*"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.b.Predicate()
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.str, got.String())
+			// The string representation must parse back to the same predicate.
+			parsed, err := ParsePredicate(got.String())
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.str, parsed.String())
+			}
+		})
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Builder
+		want error
+	}{
+		{"regex", Field("a").Regex("[").And(Field("b").Eq(1)), errors.New("a: $regex: invalid regex: error parsing regexp: missing closing ]: `[`")},
+		{"size", Field("a").Eq(1).Or(Field("b").Size(-1)), errors.New("b: $size: must be positive")},
+		{"type", Field("a").Type("foo").Not(), errors.New(`a: $type: unknown type "foo"`)},
+		{"mod", Field("a").ElemMatch(Field("b").Mod(0, 1)), errors.New("b: $mod: divisor can't be 0")},
+		{"near", Field("a").Near(GeoCoordinates{}, -1), errors.New("a: $near: $maxDistance: must be positive")},
+		{"geoWithin", Field("a").GeoWithin(nil), errors.New("a: $geoWithin: shape required")},
+		{"search", Field("a").Search("-"), errors.New("a: $search: no search terms")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.b.Predicate()
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func TestBuilderBuild(t *testing.T) {
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"age":  {Filterable: true, Validator: &schema.Integer{}},
+			"name": {Filterable: true, Validator: &schema.String{}},
+			"bio":  {Validator: &schema.String{}},
+		},
+	}
+	p, err := Field("age").Gte(18).And(Field("name").EqFold("bob")).Build(s)
+	if assert.NoError(t, err) {
+		assert.True(t, p.Match(map[string]interface{}{"age": 20, "name": "Bob"}))
+		assert.False(t, p.Match(map[string]interface{}{"age": 17, "name": "Bob"}))
+	}
+	_, err = Field("unknown").Eq(1).Build(s)
+	assert.Equal(t, errors.New("unknown: unknown query field"), err)
+	_, err = Field("bio").Eq("x").Build(s)
+	assert.Equal(t, errors.New("bio: field is not filterable"), err)
+	_, err = Field("age").Eq("x").Build(s)
+	assert.EqualError(t, err, "age: invalid query expression: not an integer")
+	assert.Panics(t, func() {
+		Field("unknown").Eq(1).MustBuild(s)
+	})
+}
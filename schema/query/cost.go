@@ -0,0 +1,328 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+// Limits bounds the complexity of the queries accepted by a resource. A zero
+// value disables the corresponding limit.
+type Limits struct {
+	// MaxPredicateDepth is the maximum nesting level of the predicate. A list
+	// of comparisons has a depth of 1, and each $and, $or, $nor, $not or
+	// $elemMatch adds a level.
+	MaxPredicateDepth int
+
+	// MaxPredicateNodes is the maximum number of expressions of the
+	// predicate, logical operators included.
+	MaxPredicateNodes int
+
+	// MaxRegexComplexity is the maximum complexity of the regular expressions
+	// of the predicate, measured as the number of instructions of the
+	// compiled expression (see RegexComplexity).
+	MaxRegexComplexity int
+
+	// MaxEmbedDepth is the maximum nesting level of the sub-resources
+	// embedded by the projection, through references or connections.
+	MaxEmbedDepth int
+
+	// MaxLimit is the maximum number of items returned per page, including
+	// for the sub-resources embedded through connections.
+	MaxLimit int
+
+	// MaxAggregatedItems is the maximum number of items aggregated in memory
+	// when the storage handler does not implement the Aggregator interface.
+	// Unlike the other limits, a zero value uses DefaultMaxAggregatedItems
+	// and a negative value disables the limit.
+	MaxAggregatedItems int
+}
+
+// DefaultMaxAggregatedItems is the default value of
+// Limits.MaxAggregatedItems.
+const DefaultMaxAggregatedItems = 10000
+
+// AggregatedItems returns the maximum number of items aggregated in memory, or
+// -1 if there is no limit.
+func (l Limits) AggregatedItems() int {
+	switch {
+	case l.MaxAggregatedItems == 0:
+		return DefaultMaxAggregatedItems
+	case l.MaxAggregatedItems < 0:
+		return -1
+	}
+	return l.MaxAggregatedItems
+}
+
+// CheckPredicate returns an error if the predicate exceeds the limits.
+func (l Limits) CheckPredicate(p Predicate) error {
+	if l.MaxPredicateDepth > 0 {
+		if depth := predicateDepth(p); depth > l.MaxPredicateDepth {
+			return fmt.Errorf("predicate depth of %d exceeds the maximum of %d", depth, l.MaxPredicateDepth)
+		}
+	}
+	if l.MaxPredicateNodes > 0 {
+		if nodes := predicateNodes(p); nodes > l.MaxPredicateNodes {
+			return fmt.Errorf("predicate of %d expressions exceeds the maximum of %d", nodes, l.MaxPredicateNodes)
+		}
+	}
+	if l.MaxRegexComplexity > 0 {
+		var err error
+		walkPredicate(p, func(exp Expression) {
+			if re, ok := exp.(*Regex); ok && err == nil {
+				if c := RegexComplexity(re.Value); c > l.MaxRegexComplexity {
+					err = fmt.Errorf("%s: %s: complexity of %d exceeds the maximum of %d", re.Field, opRegex, c, l.MaxRegexComplexity)
+				}
+			}
+		})
+		return err
+	}
+	return nil
+}
+
+// CheckProjection returns an error if the projection exceeds the limits. The
+// projection must have been validated against fg.
+func (l Limits) CheckProjection(p Projection, fg schema.FieldGetter) error {
+	return l.checkProjection(p, fg, 0)
+}
+
+func (l Limits) checkProjection(p Projection, fg schema.FieldGetter, depth int) error {
+	if fg == nil {
+		return nil
+	}
+	for _, pf := range p {
+		if pf.Name == "*" || pf.Exclude {
+			continue
+		}
+		if err := l.CheckPredicate(pf.Filter); err != nil {
+			return fmt.Errorf("%s: %v", pf.Name, err)
+		}
+		def := fg.GetField(pf.Name)
+		if def == nil {
+			continue
+		}
+		childFG, embedded := childrenFieldGetter(def)
+		if !embedded {
+			if childFG != nil {
+				if err := l.checkProjection(pf.Children, childFG, depth); err != nil {
+					return fmt.Errorf("%s.%v", pf.Name, err)
+				}
+			}
+			continue
+		}
+		if l.MaxEmbedDepth > 0 && depth+1 > l.MaxEmbedDepth {
+			return fmt.Errorf("%s: embedding depth exceeds the maximum of %d", pf.Name, l.MaxEmbedDepth)
+		}
+		if _, ok := def.Validator.(*schema.Connection); ok && l.MaxLimit > 0 {
+			if limit, ok := limitParam(pf); ok && limit > l.MaxLimit {
+				return fmt.Errorf("%s: limit of %d exceeds the maximum of %d", pf.Name, limit, l.MaxLimit)
+			}
+		}
+		if err := l.checkProjection(pf.Children, childFG, depth+1); err != nil {
+			return fmt.Errorf("%s.%v", pf.Name, err)
+		}
+	}
+	return nil
+}
+
+// ConnectionLimit returns the number of items embedded by a connection with no
+// limit param: 20, or MaxLimit when lower.
+func (l Limits) ConnectionLimit() int {
+	if l.MaxLimit > 0 && l.MaxLimit < defaultConnectionItems {
+		return l.MaxLimit
+	}
+	return defaultConnectionItems
+}
+
+// DefaultLimit returns the limit of a query with no limit param: def, capped by
+// MaxLimit, or MaxLimit when def is not set. A -1 value means no limit.
+func (l Limits) DefaultLimit(def int) int {
+	switch {
+	case def > 0 && (l.MaxLimit <= 0 || def < l.MaxLimit):
+		return def
+	case l.MaxLimit > 0:
+		return l.MaxLimit
+	}
+	return -1
+}
+
+// CheckWindow returns an error if the window exceeds the limits. A nil window
+// or a window with no limit is accepted: callers are expected to use MaxLimit
+// as the default limit.
+func (l Limits) CheckWindow(w *Window) error {
+	if w != nil && l.MaxLimit > 0 && w.Limit > l.MaxLimit {
+		return fmt.Errorf("%d exceeds the maximum of %d", w.Limit, l.MaxLimit)
+	}
+	return nil
+}
+
+// RegexComplexity returns the number of instructions of the program compiled
+// from re, used as an estimate of the cost of matching a value.
+func RegexComplexity(re *regexp.Regexp) int {
+	r, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return 0
+	}
+	prog, err := syntax.Compile(r.Simplify())
+	if err != nil {
+		return 0
+	}
+	return len(prog.Inst)
+}
+
+const (
+	// unboundedItems is the number of items returned by a query with no
+	// limit, as counted by Cost.
+	unboundedItems = 1000
+
+	// defaultConnectionItems is the number of items embedded by a connection
+	// with no limit param.
+	defaultConnectionItems = 20
+)
+
+// Cost returns an estimate of the cost of executing q against the resource
+// described by validator, meant to be compared to a budget by hooks
+// implementing rate limiting or quotas.
+//
+// Each predicate expression and sort field costs 1, and regular expressions
+// add their complexity. Each returned item costs 1 plus the cost of the
+// sub-requests embedding its references and connections, a connection costing
+// its limit (20 by default) times the cost of its items. A query with no limit
+// counts as returning 1000 items.
+func Cost(q *Query, validator schema.Validator) int {
+	items := unboundedItems
+	if q.Window != nil && q.Window.Limit >= 0 {
+		items = q.Window.Limit
+	}
+	return predicateCost(q.Predicate) + len(q.Sort) + items*(1+projectionCost(q.Projection, validator))
+}
+
+// projectionCost returns the cost of the sub-requests of the projection, per
+// projected item.
+func projectionCost(p Projection, fg schema.FieldGetter) int {
+	cost := 0
+	for _, pf := range p {
+		if pf.Name == "*" || pf.Exclude || fg == nil {
+			continue
+		}
+		def := fg.GetField(pf.Name)
+		if def == nil {
+			continue
+		}
+		childFG, embedded := childrenFieldGetter(def)
+		if !embedded {
+			cost += projectionCost(pf.Children, childFG)
+			continue
+		}
+		sub := 1 + predicateCost(pf.Filter) + projectionCost(pf.Children, childFG)
+		if _, ok := def.Validator.(*schema.Connection); ok {
+			items := defaultConnectionItems
+			if limit, ok := limitParam(pf); ok && limit >= 0 {
+				items = limit
+			}
+			sub *= items
+		}
+		cost += sub
+	}
+	return cost
+}
+
+// limitParam returns the limit param of a connection, either validated as an
+// integer or parsed as a number.
+func limitParam(pf ProjectionField) (int, bool) {
+	switch v := pf.Params["limit"].(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// childrenFieldGetter returns the field getter of the children of a field,
+// and tells if they are fetched by a sub-request (references and
+// connections).
+func childrenFieldGetter(def *schema.Field) (fg schema.FieldGetter, embedded bool) {
+	if def.Schema != nil {
+		return def.Schema, false
+	}
+	switch v := def.Validator.(type) {
+	case *schema.Reference:
+		return v.SchemaValidator, true
+	case *schema.Connection:
+		return v.Validator, true
+	case *schema.Array:
+		if ref, ok := v.Values.Validator.(*schema.Reference); ok {
+			return ref.SchemaValidator, true
+		}
+		if fg, ok := v.Values.Validator.(schema.FieldGetter); ok {
+			return fg, false
+		}
+	case schema.FieldGetter:
+		return v, false
+	}
+	return nil, false
+}
+
+// predicateCost returns the number of expressions of the predicate plus the
+// complexity of its regular expressions.
+func predicateCost(p Predicate) int {
+	cost := 0
+	walkPredicate(p, func(exp Expression) {
+		cost++
+		if re, ok := exp.(*Regex); ok {
+			cost += RegexComplexity(re.Value)
+		}
+	})
+	return cost
+}
+
+// predicateNodes returns the number of expressions of the predicate.
+func predicateNodes(p Predicate) int {
+	nodes := 0
+	walkPredicate(p, func(Expression) { nodes++ })
+	return nodes
+}
+
+// predicateDepth returns the nesting level of a list of expressions.
+func predicateDepth(exps []Expression) int {
+	if len(exps) == 0 {
+		return 0
+	}
+	max := 0
+	for _, exp := range exps {
+		if sub := subExpressions(exp); sub != nil {
+			if d := predicateDepth(sub); d > max {
+				max = d
+			}
+		}
+	}
+	return max + 1
+}
+
+// walkPredicate calls fn for each expression of the list, recursively.
+func walkPredicate(exps []Expression, fn func(Expression)) {
+	for _, exp := range exps {
+		fn(exp)
+		walkPredicate(subExpressions(exp), fn)
+	}
+}
+
+// subExpressions returns the expressions nested in exp, if any.
+func subExpressions(exp Expression) []Expression {
+	switch t := exp.(type) {
+	case *And:
+		return *t
+	case *Or:
+		return *t
+	case *Nor:
+		return *t
+	case *Not:
+		return *t
+	case *ElemMatch:
+		return t.Exps
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+func TestLimitsCheckPredicate(t *testing.T) {
+	tests := []struct {
+		limits    Limits
+		predicate string
+		err       error
+	}{
+		{Limits{}, `{$or: [{a: 1}, {$and: [{b: 1}, {c: {$not: {$gt: 1}}}]}]}`, nil},
+		{Limits{MaxPredicateDepth: 1}, `{a: 1, b: 2}`, nil},
+		{Limits{MaxPredicateDepth: 1}, `{$or: [{a: 1}, {b: 2}]}`,
+			errors.New("predicate depth of 2 exceeds the maximum of 1")},
+		{Limits{MaxPredicateDepth: 2}, `{a: {$elemMatch: {b: {$not: {$gt: 1}}}}}`,
+			errors.New("predicate depth of 3 exceeds the maximum of 2")},
+		{Limits{MaxPredicateNodes: 3}, `{$or: [{a: 1}, {b: 2}]}`, nil},
+		{Limits{MaxPredicateNodes: 2}, `{$or: [{a: 1}, {b: 2}]}`,
+			errors.New("predicate of 3 expressions exceeds the maximum of 2")},
+		{Limits{MaxRegexComplexity: 10}, `{a: {$regex: "^foo"}}`, nil},
+		{Limits{MaxRegexComplexity: 10}, `{$or: [{a: 1}, {b: {$regex: "^(a|b|c)+x{20}$"}}]}`,
+			errors.New("b: $regex: complexity of 28 exceeds the maximum of 10")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.predicate, func(t *testing.T) {
+			err := tt.limits.CheckPredicate(MustParsePredicate(tt.predicate))
+			if !reflect.DeepEqual(err, tt.err) {
+				t.Errorf("CheckPredicate() error = %v, wanted %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLimitsCheckProjection(t *testing.T) {
+	sub := &schema.Schema{
+		Fields: schema.Fields{
+			"id":   {Filterable: true},
+			"meta": {Schema: &schema.Schema{Fields: schema.Fields{"title": {}}}},
+		},
+	}
+	sub.Fields["parent"] = schema.Field{Validator: &schema.Reference{Path: "sub", SchemaValidator: sub}}
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"id":     {},
+			"ref":    {Validator: &schema.Reference{Path: "sub", SchemaValidator: sub}},
+			"refs":   {Validator: &schema.Array{Values: schema.Field{Validator: &schema.Reference{Path: "sub", SchemaValidator: sub}}}},
+			"conn":   {Validator: &schema.Connection{Path: "sub", Validator: sub}},
+			"parent": {Schema: &schema.Schema{Fields: schema.Fields{"ref": {Validator: &schema.Reference{Path: "sub", SchemaValidator: sub}}}}},
+		},
+	}
+	tests := []struct {
+		limits     Limits
+		projection string
+		err        error
+	}{
+		{Limits{}, `ref{parent{parent{id}}}`, nil},
+		{Limits{MaxEmbedDepth: 1}, `id,ref{id,meta{title}}`, nil},
+		{Limits{MaxEmbedDepth: 1}, `ref{parent{id}}`,
+			errors.New("ref.parent: embedding depth exceeds the maximum of 1")},
+		{Limits{MaxEmbedDepth: 1}, `refs{parent{id}}`,
+			errors.New("refs.parent: embedding depth exceeds the maximum of 1")},
+		{Limits{MaxEmbedDepth: 1}, `parent{ref{id}}`, nil},
+		{Limits{MaxEmbedDepth: 1}, `parent{ref{parent{id}}}`,
+			errors.New("parent.ref.parent: embedding depth exceeds the maximum of 1")},
+		{Limits{MaxLimit: 10}, `conn(limit:10){id}`, nil},
+		{Limits{MaxLimit: 10}, `conn(limit:20){id}`,
+			errors.New("conn: limit of 20 exceeds the maximum of 10")},
+		{Limits{MaxPredicateNodes: 1}, `refs[{id: 1}]`, nil},
+		{Limits{MaxPredicateNodes: 1}, `refs[{$or: [{id: 1}, {id: 2}]}]`,
+			errors.New("refs: predicate of 3 expressions exceeds the maximum of 1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.projection, func(t *testing.T) {
+			p := MustParseProjection(tt.projection)
+			err := tt.limits.CheckProjection(p, s)
+			if !reflect.DeepEqual(err, tt.err) {
+				t.Errorf("CheckProjection() error = %v, wanted %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLimitsCheckWindow(t *testing.T) {
+	l := Limits{MaxLimit: 10}
+	if err := l.CheckWindow(nil); err != nil {
+		t.Errorf("CheckWindow(nil) error = %v", err)
+	}
+	if err := l.CheckWindow(&Window{Limit: 10}); err != nil {
+		t.Errorf("CheckWindow(10) error = %v", err)
+	}
+	want := errors.New("11 exceeds the maximum of 10")
+	if err := l.CheckWindow(&Window{Limit: 11}); !reflect.DeepEqual(err, want) {
+		t.Errorf("CheckWindow(11) error = %v, wanted %v", err, want)
+	}
+}
+
+func TestLimitsConnectionLimit(t *testing.T) {
+	for _, tc := range []struct{ max, want int }{{0, 20}, {5, 5}, {50, 20}} {
+		if got := (Limits{MaxLimit: tc.max}).ConnectionLimit(); got != tc.want {
+			t.Errorf("ConnectionLimit() with MaxLimit %d = %d, wanted %d", tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestLimitsDefaultLimit(t *testing.T) {
+	for _, tc := range []struct{ def, max, want int }{{0, 0, -1}, {100, 0, 100}, {0, 10, 10}, {100, 10, 10}, {5, 10, 5}} {
+		if got := (Limits{MaxLimit: tc.max}).DefaultLimit(tc.def); got != tc.want {
+			t.Errorf("DefaultLimit(%d) with MaxLimit %d = %d, wanted %d", tc.def, tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestRegexComplexity(t *testing.T) {
+	if c := RegexComplexity(regexp.MustCompile("foo")); c != 5 {
+		t.Errorf("RegexComplexity(foo) = %d, wanted 5", c)
+	}
+	small := RegexComplexity(regexp.MustCompile("a{2}"))
+	large := RegexComplexity(regexp.MustCompile("a{200}"))
+	if large <= small {
+		t.Errorf("RegexComplexity(a{200}) = %d, wanted more than %d", large, small)
+	}
+}
+
+func TestCost(t *testing.T) {
+	sub := &schema.Schema{Fields: schema.Fields{"id": {Filterable: true}}}
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"id":   {Filterable: true, Sortable: true},
+			"ref":  {Validator: &schema.Reference{Path: "sub", SchemaValidator: sub}},
+			"conn": {Validator: &schema.Connection{Path: "sub", Validator: sub}},
+		},
+	}
+	tests := []struct {
+		name string
+		q    *Query
+		cost int
+	}{
+		{"unbounded", &Query{}, 1000},
+		{"window", &Query{Window: &Window{Limit: 10}}, 10},
+		{"predicate", &Query{
+			Predicate: MustParsePredicate(`{$or: [{id: 1}, {id: 2}]}`),
+			Sort:      MustParseSort("id"),
+			Window:    &Window{Limit: 10},
+		}, 3 + 1 + 10},
+		{"regex", &Query{
+			Predicate: MustParsePredicate(`{id: {$regex: "foo"}}`),
+			Window:    &Window{Limit: 0},
+		}, 1 + 5},
+		{"reference", &Query{
+			Projection: MustParseProjection(`id,ref{id}`),
+			Window:     &Window{Limit: 10},
+		}, 10 * (1 + 1)},
+		{"connection", &Query{
+			Projection: MustParseProjection(`conn{id}`),
+			Window:     &Window{Limit: 10},
+		}, 10 * (1 + 20)},
+		{"connection-limit", &Query{
+			Projection: MustParseProjection(`conn(limit:5)[{id: 1}]{id}`),
+			Window:     &Window{Limit: 10},
+		}, 10 * (1 + 5*(1+1))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cost := Cost(tt.q, s); cost != tt.cost {
+				t.Errorf("Cost() = %d, wanted %d", cost, tt.cost)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+// ParseFlatFilter parses a predicate expressed as flat query-string parameters.
+// Each parameter is a constraint on the field it is named after, with an
+// optional operator in brackets. Arguments are converted to the type of the
+// field using the provided validator, so the predicate still needs to be
+// prepared. When validator is nil, arguments are kept as strings.
+//
+// Supported operators are eq (the default), ne, gt, gte, lt, lte, in and nin
+// (with comas separated arguments), exists (true or false) and regex. All the
+// constraints must match.
+//
+// Example:
+//
+//   age[gte]=18&status=active&role[in]=admin,owner
+//
+// The parameters used for other purposes than filtering (like sort or limit)
+// must be removed from params beforehand.
+func ParseFlatFilter(params url.Values, validator schema.Validator) (Predicate, error) {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	p := Predicate{}
+	for _, key := range keys {
+		field, op := key, "eq"
+		if i := strings.IndexByte(key, '['); i != -1 && key[len(key)-1] == ']' {
+			field, op = key[:i], key[i+1:len(key)-1]
+		}
+		if field == "" {
+			return nil, fmt.Errorf("%s: empty field", key)
+		}
+		for _, arg := range params[key] {
+			exp, err := flatExpression(field, op, arg, validator)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			p = append(p, exp)
+		}
+	}
+	return p, nil
+}
+
+// flatExpression returns the expression of a flat filter constraint.
+func flatExpression(field, op, arg string, validator schema.Validator) (Expression, error) {
+	switch op {
+	case "in", "nin":
+		args := strings.Split(arg, ",")
+		values := make([]Value, 0, len(args))
+		for _, a := range args {
+			v, err := typedValue(field, a, validator)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		if op == "in" {
+			return &In{Field: field, Values: values}, nil
+		}
+		return &NotIn{Field: field, Values: values}, nil
+	case "exists":
+		switch arg {
+		case "true", "1", "":
+			return &Exist{Field: field}, nil
+		case "false", "0":
+			return &NotExist{Field: field}, nil
+		}
+		return nil, errors.New("expected true or false")
+	case "regex":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %v", err)
+		}
+		return &Regex{Field: field, Value: re}, nil
+	case "eq", "ne", "gt", "gte", "lt", "lte":
+	default:
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+	value, err := typedValue(field, arg, validator)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "eq":
+		return &Equal{Field: field, Value: value}, nil
+	case "ne":
+		return &NotEqual{Field: field, Value: value}, nil
+	case "gt":
+		return &GreaterThan{Field: field, Value: value}, nil
+	case "gte":
+		return &GreaterOrEqual{Field: field, Value: value}, nil
+	case "lt":
+		return &LowerThan{Field: field, Value: value}, nil
+	}
+	return &LowerOrEqual{Field: field, Value: value}, nil
+}
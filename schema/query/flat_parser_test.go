@@ -0,0 +1,51 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlatFilter(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"name":   {Filterable: true, Validator: &schema.String{}},
+		"age":    {Filterable: true, Validator: &schema.Integer{}},
+		"active": {Filterable: true, Validator: &schema.Bool{}},
+	}}
+	tests := []struct {
+		params string
+		want   string
+		err    error
+	}{
+		{``, `{}`, nil},
+		{`name=bob`, `{name: "bob"}`, nil},
+		{`age[gte]=18&age[lt]=30`, `{age: {$gte: 18}, age: {$lt: 30}}`, nil},
+		{`age[gt]=1&age[lte]=2&name[ne]=bob&active[eq]=true`, `{active: true, age: {$gt: 1}, age: {$lte: 2}, name: {$ne: "bob"}}`, nil},
+		{`name[in]=bob,alice&age[nin]=1,2`, `{age: {$nin: [1, 2]}, name: {$in: ["bob", "alice"]}}`, nil},
+		{`name[exists]=true&age[exists]=false`, `{age: {$exists: false}, name: {$exists: true}}`, nil},
+		{`name[regex]=^b`, `{name: {$regex: "^b"}}`, nil},
+		{`name=bob&name=alice`, `{name: "bob", name: "alice"}`, nil},
+		{`age[foo]=1`, ``, errors.New("age[foo]: unknown operator \"foo\"")},
+		{`age=old`, ``, errors.New("age: invalid value \"old\": not a number")},
+		{`[eq]=1`, ``, errors.New("[eq]: empty field")},
+		{`name[exists]=maybe`, ``, errors.New("name[exists]: expected true or false")},
+		{`name[regex]=[`, ``, errors.New("name[regex]: invalid regex: error parsing regexp: missing closing ]: `[`")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.params, func(t *testing.T) {
+			params, err := url.ParseQuery(tt.params)
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, err := ParseFlatFilter(params, s)
+			assert.Equal(t, tt.err, err)
+			if err == nil {
+				assert.Equal(t, tt.want, got.String())
+				assert.NoError(t, got.Prepare(s))
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+// EarthRadius is the mean radius of the Earth in meters used to compute
+// distances between geo points.
+const EarthRadius = 6371008.8
+
+// GeoCoordinates are the longitude and latitude of a point in degrees.
+type GeoCoordinates struct {
+	Lng, Lat float64
+}
+
+// Distance returns the great-circle distance in meters between c and other,
+// computed using the haversine formula.
+func (c GeoCoordinates) Distance(other GeoCoordinates) float64 {
+	rad := math.Pi / 180
+	dLat := (other.Lat - c.Lat) * rad / 2
+	dLng := (other.Lng - c.Lng) * rad / 2
+	h := math.Sin(dLat)*math.Sin(dLat) +
+		math.Cos(c.Lat*rad)*math.Cos(other.Lat*rad)*math.Sin(dLng)*math.Sin(dLng)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(math.Min(h, 1)))
+}
+
+// String output the coordinates as a [longitude, latitude] array.
+func (c GeoCoordinates) String() string {
+	return "[" + valueString(c.Lng) + ", " + valueString(c.Lat) + "]"
+}
+
+// geoCoordinates returns the coordinates of a geo point in any of the
+// representations accepted by the schema.GeoPoint validator.
+func geoCoordinates(value interface{}) (GeoCoordinates, bool) {
+	lng, lat, ok := schema.GeoPointCoordinates(value)
+	return GeoCoordinates{Lng: lng, Lat: lat}, ok
+}
+
+// geoPoints returns the coordinates of a list of geo points.
+func geoPoints(values []Value) ([]GeoCoordinates, error) {
+	points := make([]GeoCoordinates, 0, len(values))
+	for i, v := range values {
+		c, ok := geoCoordinates(v)
+		if !ok {
+			return nil, fmt.Errorf("item #%d: invalid geo point", i)
+		}
+		points = append(points, c)
+	}
+	return points, nil
+}
+
+// GeoShape is a geographic area used by the GeoWithin expression.
+type GeoShape interface {
+	// Contains returns true if the point is within the area.
+	Contains(lng, lat float64) bool
+
+	// String output the shape in its DSL form (i.e.: $box: [...]).
+	String() string
+}
+
+// GeoBox is a rectangular area.
+type GeoBox struct {
+	schema.GeoBounds
+}
+
+// String implements GeoShape interface.
+func (b GeoBox) String() string {
+	sw := GeoCoordinates{Lng: b.MinLng, Lat: b.MinLat}
+	ne := GeoCoordinates{Lng: b.MaxLng, Lat: b.MaxLat}
+	return opBox + ": [" + sw.String() + ", " + ne.String() + "]"
+}
+
+// GeoCircle is a circular area on the surface of the Earth.
+type GeoCircle struct {
+	Center GeoCoordinates
+
+	// Radius is the radius of the circle in meters.
+	Radius float64
+}
+
+// Contains implements GeoShape interface.
+func (c GeoCircle) Contains(lng, lat float64) bool {
+	return c.Center.Distance(GeoCoordinates{Lng: lng, Lat: lat}) <= c.Radius
+}
+
+// String implements GeoShape interface.
+func (c GeoCircle) String() string {
+	return opCircle + ": [" + c.Center.String() + ", " + valueString(c.Radius) + "]"
+}
+
+// GeoPolygon is a polygonal area defined by the list of its vertices. The
+// polygon is implicitly closed. Edges are straight lines in the
+// longitude/latitude plane.
+type GeoPolygon []GeoCoordinates
+
+// Contains implements GeoShape interface.
+func (p GeoPolygon) Contains(lng, lat float64) bool {
+	// Ray casting: count the edges crossed by a ray going east from the point.
+	in := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Lat > lat) != (b.Lat > lat) &&
+			lng < (b.Lng-a.Lng)*(lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			in = !in
+		}
+	}
+	return in
+}
+
+// String implements GeoShape interface.
+func (p GeoPolygon) String() string {
+	s := make([]string, 0, len(p))
+	for _, c := range p {
+		s = append(s, c.String())
+	}
+	return opPolygon + ": [" + strings.Join(s, ", ") + "]"
+}
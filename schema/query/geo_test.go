@@ -0,0 +1,30 @@
+package query
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeoCoordinatesDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b GeoCoordinates
+		want float64
+	}{
+		{"Same", GeoCoordinates{Lng: 2.3522, Lat: 48.8566}, GeoCoordinates{Lng: 2.3522, Lat: 48.8566}, 0},
+		{"Paris/London", GeoCoordinates{Lng: 2.3522, Lat: 48.8566}, GeoCoordinates{Lng: -0.1276, Lat: 51.5072}, 343500},
+		{"Antipodes", GeoCoordinates{Lng: 0, Lat: 0}, GeoCoordinates{Lng: 180, Lat: 0}, math.Pi * EarthRadius},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.a.Distance(tt.b)
+			// Allow 0.1% of error.
+			if math.Abs(got-tt.want) > tt.want/1000+1e-6 {
+				t.Errorf("Distance() = %v, want %v", got, tt.want)
+			}
+			if back := tt.b.Distance(tt.a); math.Abs(back-got) > 1e-6 {
+				t.Errorf("Distance() is not symmetric: %v != %v", back, got)
+			}
+		})
+	}
+}
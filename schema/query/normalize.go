@@ -0,0 +1,368 @@
+package query
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Normalize returns a simplified version of the predicate matching the same
+// payloads. Nested $and and $or are flattened, duplicated expressions are
+// removed, ranges on the same field are merged, and $or of equalities on the
+// same field are collapsed into $in. Expressions are sorted so equivalent
+// predicates normalize to the same string, which can be used as a cache key.
+//
+// The returned boolean is false if the predicate can never match, like when
+// a field is compared to disjoint ranges or is both equal and not equal to a
+// value. The returned predicate is then empty and must not be used.
+//
+// Expressions are reused as-is, so normalizing a prepared predicate returns a
+// prepared predicate.
+func (e Predicate) Normalize() (Predicate, bool) {
+	exps, ok := normalizeAnd(e)
+	if !ok {
+		return Predicate{}, false
+	}
+	return Predicate(exps), true
+}
+
+// normalizeAnd normalizes a conjunction of expressions. An empty conjunction
+// always matches. False is returned if the conjunction can never match.
+func normalizeAnd(exps []Expression) ([]Expression, bool) {
+	out := make([]Expression, 0, len(exps))
+	for _, exp := range exps {
+		switch t := exp.(type) {
+		case *And:
+			sub, ok := normalizeAnd(*t)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, sub...)
+		case *Or:
+			alts, ok := normalizeOr(*t)
+			if !ok {
+				return nil, false
+			}
+			if len(alts) == 1 {
+				out = append(out, alts[0]...)
+			} else if len(alts) > 1 {
+				or := Or(alternatives(alts))
+				out = append(out, &or)
+			}
+		case *Not:
+			sub, ok := normalizeAnd(*t)
+			if !ok {
+				// The negation of a never matching conjunction always matches.
+				continue
+			}
+			if len(sub) == 0 {
+				return nil, false
+			}
+			not := Not(sub)
+			out = append(out, &not)
+		case *Nor:
+			alts, ok := normalizeOr(*t)
+			if !ok {
+				continue
+			}
+			if len(alts) == 0 {
+				return nil, false
+			}
+			nor := Nor(alternatives(alts))
+			out = append(out, &nor)
+		case *ElemMatch:
+			sub, ok := normalizeAnd(t.Exps)
+			if !ok {
+				return nil, false
+			}
+			em := *t
+			em.Exps = sub
+			out = append(out, &em)
+		case *In:
+			if len(t.Values) == 0 {
+				return nil, false
+			}
+			out = append(out, t)
+		default:
+			out = append(out, exp)
+		}
+	}
+	out = uniqueExpressions(out)
+	out, ok := mergeFields(out)
+	if !ok {
+		return nil, false
+	}
+	sortExpressions(out)
+	return out, true
+}
+
+// normalizeOr normalizes a disjunction of expressions into a list of
+// normalized conjunctions. An empty list is returned with true if one of the
+// alternatives always matches, and false is returned if none can match.
+func normalizeOr(exps []Expression) ([][]Expression, bool) {
+	var alts [][]Expression
+	var flatten func(exps []Expression) bool
+	flatten = func(exps []Expression) bool {
+		for _, exp := range exps {
+			if or, ok := exp.(*Or); ok {
+				if flatten(*or) {
+					return true
+				}
+				continue
+			}
+			alt, ok := normalizeAnd([]Expression{exp})
+			if !ok {
+				continue
+			}
+			if len(alt) == 0 {
+				return true
+			}
+			alts = append(alts, alt)
+		}
+		return false
+	}
+	if flatten(exps) {
+		return nil, true
+	}
+	if len(alts) == 0 {
+		return nil, false
+	}
+	alts = collapseEqualities(alts)
+	// Remove duplicated alternatives and sort them.
+	seen := map[string]bool{}
+	unique := alts[:0]
+	for _, alt := range alts {
+		key := Predicate(alt).String()
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, alt)
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return Predicate(unique[i]).String() < Predicate(unique[j]).String()
+	})
+	return unique, true
+}
+
+// collapseEqualities merges alternatives made of a single equality or $in on
+// the same field into a single $in.
+func collapseEqualities(alts [][]Expression) [][]Expression {
+	ins := map[string]*In{}
+	out := make([][]Expression, 0, len(alts))
+	for _, alt := range alts {
+		var field string
+		var values []Value
+		if len(alt) == 1 {
+			switch t := alt[0].(type) {
+			case *Equal:
+				// Equality with an array value is an exact match and can't be
+				// expressed with $in.
+				if _, isArray := t.Value.([]interface{}); !isArray && !t.CaseInsensitive {
+					field, values = t.Field, []Value{t.Value}
+				}
+			case *In:
+				if !t.CaseInsensitive {
+					field, values = t.Field, t.Values
+				}
+			}
+		}
+		if field == "" {
+			out = append(out, alt)
+			continue
+		}
+		in, found := ins[field]
+		if !found {
+			in = &In{Field: field}
+			ins[field] = in
+			out = append(out, []Expression{in})
+		}
+		for _, v := range values {
+			if !containsValue(in.Values, v) {
+				in.Values = append(in.Values, v)
+			}
+		}
+	}
+	for i, alt := range out {
+		// Restore single equalities.
+		if in, ok := alt[0].(*In); ok && len(in.Values) == 1 && ins[in.Field] == in {
+			out[i] = []Expression{&Equal{Field: in.Field, Value: in.Values[0]}}
+		}
+	}
+	return out
+}
+
+// alternatives returns the expressions of a list of conjunctions.
+func alternatives(alts [][]Expression) []Expression {
+	exps := make([]Expression, 0, len(alts))
+	for _, alt := range alts {
+		if len(alt) == 1 {
+			exps = append(exps, alt[0])
+			continue
+		}
+		and := And(alt)
+		exps = append(exps, &and)
+	}
+	return exps
+}
+
+// fieldBounds holds the tightest numeric range set on a field.
+type fieldBounds struct {
+	lower, upper             int
+	lowerValue, upperValue   float64
+	lowerStrict, upperStrict bool
+}
+
+// mergeFields merges the ranges set on a same field by a conjunction of
+// expressions and checks the expressions on a same field are compatible.
+// False is returned if the conjunction can never match.
+//
+// Equalities on a field may match several values of an array, so different
+// equalities on a same field are not considered a contradiction. Ranges only
+// match scalar values.
+func mergeFields(exps []Expression) ([]Expression, bool) {
+	bounds := map[string]*fieldBounds{}
+	getBounds := func(field string) *fieldBounds {
+		b := bounds[field]
+		if b == nil {
+			b = &fieldBounds{lower: -1, upper: -1}
+			bounds[field] = b
+		}
+		return b
+	}
+	removed := map[int]bool{}
+	setLower := func(i int, field string, value Value, strict bool) {
+		v, ok := isNumber(value)
+		if !ok {
+			return
+		}
+		b := getBounds(field)
+		if b.lower != -1 {
+			if v < b.lowerValue || (v == b.lowerValue && (b.lowerStrict || !strict)) {
+				removed[i] = true
+				return
+			}
+			removed[b.lower] = true
+		}
+		b.lower, b.lowerValue, b.lowerStrict = i, v, strict
+	}
+	setUpper := func(i int, field string, value Value, strict bool) {
+		v, ok := isNumber(value)
+		if !ok {
+			return
+		}
+		b := getBounds(field)
+		if b.upper != -1 {
+			if v > b.upperValue || (v == b.upperValue && (b.upperStrict || !strict)) {
+				removed[i] = true
+				return
+			}
+			removed[b.upper] = true
+		}
+		b.upper, b.upperValue, b.upperStrict = i, v, strict
+	}
+	exists := map[string]bool{}
+	notExists := map[string]bool{}
+	for i, exp := range exps {
+		switch t := exp.(type) {
+		case *GreaterThan:
+			setLower(i, t.Field, t.Value, true)
+		case *GreaterOrEqual:
+			setLower(i, t.Field, t.Value, false)
+		case *LowerThan:
+			setUpper(i, t.Field, t.Value, true)
+		case *LowerOrEqual:
+			setUpper(i, t.Field, t.Value, false)
+		case *Exist:
+			exists[t.Field] = true
+		case *NotExist:
+			notExists[t.Field] = true
+		}
+	}
+	for _, b := range bounds {
+		if b.lower != -1 && b.upper != -1 && !b.contains(b.lowerValue) {
+			return nil, false
+		}
+	}
+	for field := range exists {
+		if notExists[field] {
+			return nil, false
+		}
+	}
+	for _, exp := range exps {
+		eq, ok := exp.(*Equal)
+		if !ok || eq.CaseInsensitive {
+			continue
+		}
+		if eq.Value != nil && notExists[eq.Field] {
+			return nil, false
+		}
+		if b := bounds[eq.Field]; b != nil {
+			if v, ok := isNumber(eq.Value); ok && !b.contains(v) {
+				return nil, false
+			}
+		}
+		for _, other := range exps {
+			switch t := other.(type) {
+			case *NotEqual:
+				if t.Field == eq.Field && reflect.DeepEqual(t.Value, eq.Value) {
+					return nil, false
+				}
+			case *NotIn:
+				if t.Field == eq.Field && containsValue(t.Values, eq.Value) {
+					return nil, false
+				}
+			}
+		}
+	}
+	if len(removed) == 0 {
+		return exps, true
+	}
+	out := make([]Expression, 0, len(exps)-len(removed))
+	for i, exp := range exps {
+		if !removed[i] {
+			out = append(out, exp)
+		}
+	}
+	return out, true
+}
+
+// contains tells if v is within the bounds.
+func (b fieldBounds) contains(v float64) bool {
+	if b.lower != -1 && (v < b.lowerValue || (v == b.lowerValue && b.lowerStrict)) {
+		return false
+	}
+	if b.upper != -1 && (v > b.upperValue || (v == b.upperValue && b.upperStrict)) {
+		return false
+	}
+	return true
+}
+
+// uniqueExpressions removes the duplicated expressions of a conjunction.
+func uniqueExpressions(exps []Expression) []Expression {
+	seen := make(map[string]bool, len(exps))
+	out := exps[:0]
+	for _, exp := range exps {
+		key := exp.String()
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, exp)
+		}
+	}
+	return out
+}
+
+// sortExpressions sorts expressions by their string representation.
+func sortExpressions(exps []Expression) {
+	sort.SliceStable(exps, func(i, j int) bool {
+		return exps[i].String() < exps[j].String()
+	})
+}
+
+// containsValue tells if values contains v.
+func containsValue(values []Value, v Value) bool {
+	for _, value := range values {
+		if reflect.DeepEqual(value, v) {
+			return true
+		}
+	}
+	return false
+}
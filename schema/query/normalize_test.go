@@ -0,0 +1,83 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		ok    bool
+	}{
+		{`{}`, `{}`, true},
+		{`{b: 2, a: 1}`, `{a: 1, b: 2}`, true},
+		{`{a: 1, $and: [{b: 2}, {$and: [{c: 3}, {a: 1}]}]}`, `{a: 1, b: 2, c: 3}`, true},
+		{`{$or: [{a: 1}]}`, `{a: 1}`, true},
+		{`{$or: [{a: 1, b: 2}]}`, `{a: 1, b: 2}`, true},
+		{`{$or: [{b: 1}, {$or: [{c: 1}, {b: 1}]}]}`, `{$or: [{b: 1}, {c: 1}]}`, true},
+		{`{$or: [{a: 1}, {a: 2}, {a: {$in: [2, 3]}}]}`, `{a: {$in: [1, 2, 3]}}`, true},
+		{`{$or: [{a: 1}, {b: 2}, {a: 3}]}`, `{$or: [{a: {$in: [1, 3]}}, {b: 2}]}`, true},
+		{`{$or: [{a: 1}, {a: 1}]}`, `{a: 1}`, true},
+		{`{$or: [{a: [1]}, {a: 2}]}`, `{$or: [{a: 2}, {a: [1]}]}`, true},
+		{`{$or: [{a: {$eq: "x", $options: "i"}}, {a: "y"}]}`, `{$or: [{a: "y"}, {a: {$eq: "x", $options: "i"}}]}`, true},
+		{`{a: {$gt: 1}, a: {$gte: 3}, a: {$lt: 10}, a: {$lte: 10}}`, `{a: {$gte: 3}, a: {$lt: 10}}`, true},
+		{`{a: {$gt: 3}, a: {$gte: 3}}`, `{a: {$gt: 3}}`, true},
+		{`{a: {$gte: 3}, a: {$lte: 3}}`, `{a: {$gte: 3}, a: {$lte: 3}}`, true},
+		{`{a: {$gte: 3}, a: {$lt: 3}}`, ``, false},
+		{`{a: {$gt: 5}, a: {$lt: 1}}`, ``, false},
+		{`{a: {$gt: "b"}, a: {$lt: "a"}}`, `{a: {$gt: "b"}, a: {$lt: "a"}}`, true},
+		{`{a: 1, a: 2}`, `{a: 1, a: 2}`, true},
+		{`{a: 1, a: {$ne: 1}}`, ``, false},
+		{`{a: 1, a: {$nin: [0, 1]}}`, ``, false},
+		{`{a: 1, a: {$gt: 1}}`, ``, false},
+		{`{a: 1, a: {$gte: 1}}`, `{a: 1, a: {$gte: 1}}`, true},
+		{`{a: 1, a: {$exists: false}}`, ``, false},
+		{`{a: null, a: {$exists: false}}`, `{a: null, a: {$exists: false}}`, true},
+		{`{a: {$exists: true}, a: {$exists: false}}`, ``, false},
+		{`{a: {$in: []}}`, ``, false},
+		{`{$or: [{a: {$in: []}}, {b: 1}]}`, `{b: 1}`, true},
+		{`{$or: [{a: {$in: []}}, {a: 1, a: {$ne: 1}}]}`, ``, false},
+		{`{$or: [{a: 1}, {$not: {a: 1, a: {$ne: 1}}}], b: 1}`, `{b: 1}`, true},
+		{`{$not: {a: 1, a: {$ne: 1}}, b: 1}`, `{b: 1}`, true},
+		{`{$not: {b: 1, a: 1}}`, `{$not: {a: 1, b: 1}}`, true},
+		{`{$nor: [{a: 1}, {a: 2}]}`, `{$nor: [{a: {$in: [1, 2]}}]}`, true},
+		{`{$nor: [{a: {$in: []}}]}`, `{}`, true},
+		{`{a: {$elemMatch: {c: 1, b: {$gt: 1}, b: {$gt: 2}}}}`, `{a: {$elemMatch: {b: {$gt: 2}, c: 1}}}`, true},
+		{`{a: {$elemMatch: {b: {$gt: 2}, b: {$lt: 1}}}}`, ``, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, ok := MustParsePredicate(tt.query).Normalize()
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+}
+
+func TestNormalizeEquivalent(t *testing.T) {
+	p1, _ := MustParsePredicate(`{$or: [{b: 1}, {a: 2}], c: {$gt: 1}, d: "x"}`).Normalize()
+	p2, _ := MustParsePredicate(`{d: "x", $and: [{c: {$gt: 1}}, {$or: [{a: 2}, {b: 1}]}]}`).Normalize()
+	assert.Equal(t, p1.String(), p2.String())
+}
+
+func TestNormalizePrepared(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"a": {Filterable: true, Validator: &schema.Integer{}},
+	}}
+	p := MustParsePredicate(`{$and: [{a: {$gt: 1}}, {a: {$gt: 2}}]}`)
+	if !assert.NoError(t, p.Prepare(s)) {
+		return
+	}
+	got, ok := p.Normalize()
+	if assert.True(t, ok) {
+		assert.Equal(t, `{a: {$gt: 2}}`, got.String())
+		assert.True(t, got.Match(map[string]interface{}{"a": 3}))
+		assert.False(t, got.Match(map[string]interface{}{"a": 2}))
+	}
+}
@@ -2,9 +2,12 @@ package query
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/entropyinf/rest-layer/schema"
 )
@@ -23,6 +26,21 @@ const (
 	opRegex          = "$regex"
 	opElemMatch      = "$elemMatch"
 	opNot            = "$not"
+	opNor            = "$nor"
+	opAll            = "$all"
+	opSize           = "$size"
+	opType           = "$type"
+	opMod            = "$mod"
+	opEqual          = "$eq"
+	opOptions        = "$options"
+	opIRegex         = "$iregex"
+	opNear           = "$near"
+	opMaxDistance    = "$maxDistance"
+	opGeoWithin      = "$geoWithin"
+	opBox            = "$box"
+	opCircle         = "$circle"
+	opPolygon        = "$polygon"
+	opSearch         = "$search"
 )
 
 // Predicate defines an expression against a schema to perform a match on schema's data.
@@ -134,10 +152,66 @@ func (e Or) String() string {
 	return opOr + ": [" + strings.Join(s, ", ") + "]"
 }
 
+// Nor joins query clauses with a logical NOR, returns all documents that fail
+// to match all the clauses.
+type Nor []Expression
+
+// Match implements Expression interface.
+func (e Nor) Match(payload map[string]interface{}) bool {
+	for _, subQuery := range e {
+		if subQuery.Match(payload) {
+			return false
+		}
+	}
+	return true
+}
+
+// Prepare implements Expression interface.
+func (e *Nor) Prepare(validator schema.Validator) error {
+	return prepareExpressions(*e, validator)
+}
+
+// String implements Expression interface.
+func (e Nor) String() string {
+	if len(e) == 0 {
+		return opNor + ": []"
+	}
+	s := make([]string, 0, len(e))
+	for _, subQuery := range e {
+		s = append(s, "{"+subQuery.String()+"}")
+	}
+	return opNor + ": [" + strings.Join(s, ", ") + "]"
+}
+
+// Not inverts the result of its expressions, returns all documents that don't
+// match all of them.
+type Not []Expression
+
+// Match implements Expression interface.
+func (e Not) Match(payload map[string]interface{}) bool {
+	return !Predicate(e).Match(payload)
+}
+
+// Prepare implements Expression interface.
+func (e *Not) Prepare(validator schema.Validator) error {
+	return prepareExpressions(*e, validator)
+}
+
+// String implements Expression interface.
+func (e Not) String() string {
+	s := make([]string, 0, len(e))
+	for _, subQuery := range e {
+		s = append(s, subQuery.String())
+	}
+	return opNot + ": {" + strings.Join(s, ", ") + "}"
+}
+
 // In matches any of the values specified in an array.
 type In struct {
 	Field  string
 	Values []Value
+	// CaseInsensitive makes string values compare without regard to case.
+	CaseInsensitive bool
 }
 
 // Match implements Expression interface.
@@ -151,14 +225,14 @@ func (e In) Match(payload map[string]interface{}) bool {
 	case []interface{}:
 		for _, v := range e.Values {
 			for _, vv := range vt {
-				if reflect.DeepEqual(v, vv) {
+				if valueEqual(v, vv, e.CaseInsensitive) {
 					return true
 				}
 			}
 		}
 	default:
 		for _, v := range e.Values {
-			if reflect.DeepEqual(v, value) {
+			if valueEqual(v, value, e.CaseInsensitive) {
 				return true
 			}
 		}
@@ -178,7 +252,7 @@ func (e In) String() string {
 	for _, v := range e.Values {
 		s = append(s, valueString(v))
 	}
-	return quoteField(e.Field) + ": {" + opIn + ": [" + strings.Join(s, ", ") + "]}"
+	return quoteField(e.Field) + ": {" + opIn + ": [" + strings.Join(s, ", ") + "]" + optionsString(e.CaseInsensitive) + "}"
 }
 
 // NotIn matches none of the values specified in an array.
@@ -231,6 +305,8 @@ func (e *NotIn) Prepare(validator schema.Validator) error {
 type Equal struct {
 	Field string
 	Value Value
+	// CaseInsensitive makes string values compare without regard to case.
+	CaseInsensitive bool
 }
 
 // Match implements Expression interface.
@@ -244,12 +320,12 @@ func (e Equal) Match(payload map[string]interface{}) bool {
 	_, eok := e.Value.([]interface{})
 	if vok && !eok {
 		for _, vv := range vt {
-			if reflect.DeepEqual(e.Value, vv) {
+			if valueEqual(e.Value, vv, e.CaseInsensitive) {
 				return true
 			}
 		}
 	} else {
-		return reflect.DeepEqual(value, e.Value)
+		return valueEqual(value, e.Value, e.CaseInsensitive)
 	}
 	return false
 }
@@ -263,6 +339,9 @@ func (e *Equal) Prepare(validator schema.Validator) error {
 
 // String implements Expression interface.
 func (e Equal) String() string {
+	if e.CaseInsensitive {
+		return quoteField(e.Field) + ": {" + opEqual + ": " + valueString(e.Value) + optionsString(true) + "}"
+	}
 	return quoteField(e.Field) + ": " + valueString(e.Value)
 }
 
@@ -475,7 +554,9 @@ func (e LowerOrEqual) String() string {
 	return quoteField(e.Field) + ": {" + opLowerOrEqual + ": " + valueString(e.Value) + "}"
 }
 
-// Regex matches values that match to a specified regular expression.
+// Regex matches values that match to a specified regular expression. A case
+// insensitive match is obtained by compiling the expression with the (?i)
+// flag.
 type Regex struct {
 	Field   string
 	Value   *regexp.Regexp
@@ -489,6 +570,11 @@ func (e Regex) Match(payload map[string]interface{}) bool {
 
 // Prepare implements Expression interface.
 func (e *Regex) Prepare(validator schema.Validator) error {
+	if f := validator.GetField(e.Field); f != nil {
+		if _, ok := f.Validator.(*schema.Encrypted); ok {
+			return fmt.Errorf("%s: can't match an encrypted field", e.Field)
+		}
+	}
 	_, err := prepareValue(e.Field, e.Value.String(), validator)
 	return err
 }
@@ -560,3 +646,295 @@ func (e ElemMatch) String() string {
 	}
 	return quoteField(e.Field) + ": {" + opElemMatch + ": {" + strings.Join(s, ", ") + "}}"
 }
+
+// All matches arrays containing all the values specified.
+type All struct {
+	Field  string
+	Values []Value
+}
+
+// Match implements Expression interface.
+func (e All) Match(payload map[string]interface{}) bool {
+	value := getField(payload, e.Field)
+	arr, ok := value.([]interface{})
+	if !ok {
+		// As with MongoDB, a non array value is handled as a single element
+		// array.
+		arr = []interface{}{value}
+	}
+	if len(e.Values) == 0 {
+		return false
+	}
+	for _, v := range e.Values {
+		found := false
+		for _, vv := range arr {
+			if reflect.DeepEqual(v, vv) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Prepare implements Expression interface.
+func (e *All) Prepare(validator schema.Validator) error {
+	if err := prepareArrayField(e.Field, validator); err != nil {
+		return err
+	}
+	return prepareValues(e.Field, e.Values, validator)
+}
+
+// String implements Expression interface.
+func (e All) String() string {
+	s := make([]string, 0, len(e.Values))
+	for _, v := range e.Values {
+		s = append(s, valueString(v))
+	}
+	return quoteField(e.Field) + ": {" + opAll + ": [" + strings.Join(s, ", ") + "]}"
+}
+
+// Size matches arrays with the specified number of elements.
+type Size struct {
+	Field string
+	Value int
+}
+
+// Match implements Expression interface.
+func (e Size) Match(payload map[string]interface{}) bool {
+	arr, ok := getField(payload, e.Field).([]interface{})
+	return ok && len(arr) == e.Value
+}
+
+// Prepare implements Expression interface.
+func (e *Size) Prepare(validator schema.Validator) error {
+	return prepareArrayField(e.Field, validator)
+}
+
+// String implements Expression interface.
+func (e Size) String() string {
+	return quoteField(e.Field) + ": {" + opSize + ": " + strconv.Itoa(e.Value) + "}"
+}
+
+// Types supported by the $type operator.
+const (
+	TypeString = "string"
+	TypeNumber = "number"
+	TypeInt    = "int"
+	TypeDouble = "double"
+	TypeBool   = "bool"
+	TypeObject = "object"
+	TypeArray  = "array"
+	TypeNull   = "null"
+	TypeDate   = "date"
+)
+
+// Type matches values of the specified type. The number type matches both int
+// and double values.
+type Type struct {
+	Field string
+	Type  string
+}
+
+// isType returns true if t is a type supported by Type.
+func isType(t string) bool {
+	switch t {
+	case TypeString, TypeNumber, TypeInt, TypeDouble, TypeBool, TypeObject, TypeArray, TypeNull, TypeDate:
+		return true
+	}
+	return false
+}
+
+// Match implements Expression interface.
+func (e Type) Match(payload map[string]interface{}) bool {
+	value, found := getFieldExist(payload, e.Field)
+	if !found {
+		return false
+	}
+	switch v := value.(type) {
+	case nil:
+		return e.Type == TypeNull
+	case string:
+		return e.Type == TypeString
+	case bool:
+		return e.Type == TypeBool
+	case float32, float64:
+		return e.Type == TypeNumber || e.Type == TypeDouble
+	case map[string]interface{}:
+		return e.Type == TypeObject
+	case []interface{}:
+		return e.Type == TypeArray
+	case time.Time:
+		return e.Type == TypeDate
+	default:
+		if _, ok := isNumber(v); ok {
+			return e.Type == TypeNumber || e.Type == TypeInt
+		}
+	}
+	return false
+}
+
+// Prepare implements Expression interface.
+func (e *Type) Prepare(validator schema.Validator) error {
+	if !isType(e.Type) {
+		return fmt.Errorf("%s: unknown type %q", e.Field, e.Type)
+	}
+	return validateField(e.Field, validator)
+}
+
+// String implements Expression interface.
+func (e Type) String() string {
+	return quoteField(e.Field) + ": {" + opType + ": " + strconv.Quote(e.Type) + "}"
+}
+
+// Mod matches numeric values for which the remainder of the division by
+// Divisor equals Remainder. As with MongoDB, values are truncated to integers.
+type Mod struct {
+	Field     string
+	Divisor   int64
+	Remainder int64
+}
+
+// Match implements Expression interface.
+func (e Mod) Match(payload map[string]interface{}) bool {
+	n, ok := isNumber(getField(payload, e.Field))
+	if !ok || e.Divisor == 0 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return false
+	}
+	return int64(n)%e.Divisor == e.Remainder
+}
+
+// Prepare implements Expression interface.
+func (e *Mod) Prepare(validator schema.Validator) error {
+	if e.Divisor == 0 {
+		return fmt.Errorf("%s: divisor can't be 0", e.Field)
+	}
+	f, err := getValidatorField(e.Field, validator)
+	if err != nil {
+		return err
+	}
+	switch f.Validator.(type) {
+	case schema.Integer, *schema.Integer, schema.Float, *schema.Float:
+	default:
+		return fmt.Errorf("%s: not a numeric field", e.Field)
+	}
+	return nil
+}
+
+// String implements Expression interface.
+func (e Mod) String() string {
+	return quoteField(e.Field) + ": {" + opMod + ": [" + strconv.FormatInt(e.Divisor, 10) + ", " + strconv.FormatInt(e.Remainder, 10) + "]}"
+}
+
+// Near matches geo points within a maximum distance of a point.
+type Near struct {
+	Field string
+	Point GeoCoordinates
+
+	// MaxDistance is the maximum distance in meters. There is no limit if
+	// zero.
+	MaxDistance float64
+}
+
+// Match implements Expression interface.
+func (e Near) Match(payload map[string]interface{}) bool {
+	c, ok := geoCoordinates(getField(payload, e.Field))
+	if !ok {
+		return false
+	}
+	return e.MaxDistance == 0 || e.Point.Distance(c) <= e.MaxDistance
+}
+
+// Prepare implements Expression interface.
+func (e *Near) Prepare(validator schema.Validator) error {
+	if e.MaxDistance < 0 {
+		return fmt.Errorf("%s: %s: must be positive", e.Field, opMaxDistance)
+	}
+	return prepareGeoField(e.Field, validator)
+}
+
+// String implements Expression interface.
+func (e Near) String() string {
+	s := quoteField(e.Field) + ": {" + opNear + ": " + e.Point.String()
+	if e.MaxDistance != 0 {
+		s += ", " + opMaxDistance + ": " + valueString(e.MaxDistance)
+	}
+	return s + "}"
+}
+
+// GeoWithin matches geo points within a geographic area.
+type GeoWithin struct {
+	Field string
+	Shape GeoShape
+}
+
+// Match implements Expression interface.
+func (e GeoWithin) Match(payload map[string]interface{}) bool {
+	c, ok := geoCoordinates(getField(payload, e.Field))
+	if !ok || e.Shape == nil {
+		return false
+	}
+	return e.Shape.Contains(c.Lng, c.Lat)
+}
+
+// Prepare implements Expression interface.
+func (e *GeoWithin) Prepare(validator schema.Validator) error {
+	if e.Shape == nil {
+		return fmt.Errorf("%s: %s: shape required", e.Field, opGeoWithin)
+	}
+	return prepareGeoField(e.Field, validator)
+}
+
+// String implements Expression interface.
+func (e GeoWithin) String() string {
+	shape := ""
+	if e.Shape != nil {
+		shape = e.Shape.String()
+	}
+	return quoteField(e.Field) + ": {" + opGeoWithin + ": {" + shape + "}}"
+}
+
+// Search matches strings containing all the terms of a full-text search
+// query. The query and the strings are tokenized into case folded words (see
+// Tokenize), and a term ending with a star (i.e.: foo*) matches all the words
+// starting with it.
+type Search struct {
+	Field string
+	Value string
+}
+
+// Terms returns the terms of the search query.
+func (e Search) Terms() []SearchTerm {
+	return SearchTerms(e.Value)
+}
+
+// Match implements Expression interface.
+func (e Search) Match(payload map[string]interface{}) bool {
+	text, ok := getField(payload, e.Field).(string)
+	if !ok {
+		return false
+	}
+	return matchTerms(e.Terms(), Tokenize(text))
+}
+
+// Prepare implements Expression interface.
+func (e *Search) Prepare(validator schema.Validator) error {
+	if len(e.Terms()) == 0 {
+		return fmt.Errorf("%s: %s: no search terms", e.Field, opSearch)
+	}
+	if f := validator.GetField(e.Field); f != nil {
+		if _, ok := f.Validator.(*schema.Encrypted); ok {
+			return fmt.Errorf("%s: can't search an encrypted field", e.Field)
+		}
+	}
+	_, err := prepareValue(e.Field, e.Value, validator)
+	return err
+}
+
+// String implements Expression interface.
+func (e Search) String() string {
+	return quoteField(e.Field) + ": {" + opSearch + ": " + valueString(e.Value) + "}"
+}
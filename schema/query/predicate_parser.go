@@ -3,8 +3,11 @@ package query
 import (
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
+
+	"github.com/entropyinf/rest-layer/schema"
 )
 
 type predicateParser struct {
@@ -92,7 +95,7 @@ func (p *predicateParser) parseExpression() (Expression, error) {
 	}
 	p.eatWhitespaces()
 	switch label {
-	case opAnd, opOr:
+	case opAnd, opOr, opNor:
 		subExp, err := p.parseSubExpressions()
 		if err != nil {
 			return nil, fmt.Errorf("%s: %v", label, err)
@@ -100,14 +103,36 @@ func (p *predicateParser) parseExpression() (Expression, error) {
 		if len(subExp) < 1 {
 			return nil, fmt.Errorf("%s: one expressions or more required", label)
 		}
-		if label == opAnd {
+		switch label {
+		case opAnd:
 			and := And(subExp)
 			return &and, nil
+		case opNor:
+			nor := Nor(subExp)
+			return &nor, nil
 		}
 		or := Or(subExp)
 		return &or, nil
+	case opNot:
+		if p.peek() != '{' {
+			// Only expressions can be negated at the top level.
+			p.pos = oldPos
+			return nil, fmt.Errorf("%s: invalid placement", label)
+		}
+		exps, err := p.parseExpressions()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", label, err)
+		}
+		if len(exps) < 1 {
+			return nil, fmt.Errorf("%s: one expressions or more required", label)
+		}
+		not := Not(exps)
+		return &not, nil
 	case opExists, opIn, opNotIn, opNotEqual, opRegex, opElemMatch,
-		opLowerThan, opLowerOrEqual, opGreaterThan, opGreaterOrEqual, opNot:
+		opLowerThan, opLowerOrEqual, opGreaterThan, opGreaterOrEqual,
+		opAll, opSize, opType, opMod, opEqual, opOptions, opIRegex,
+		opNear, opMaxDistance, opGeoWithin, opBox, opCircle, opPolygon,
+		opSearch:
 		p.pos = oldPos
 		return nil, fmt.Errorf("%s: invalid placement", label)
 	default:
@@ -165,6 +190,15 @@ func (p *predicateParser) parseSubExpressions() ([]Expression, error) {
 //   {$exist: true}
 //   {$ne: "foo"}
 //   {$in: ["foo", "bar"]}
+//   {$not: {$gt: 10}}
+//   {$all: ["foo", "bar"]}
+//   {$size: 2}
+//   {$type: "string"}
+//   {$mod: [4, 0]}
+//   {$eq: "foo", $options: "i"}
+//   {$iregex: "^foo"}
+//   {$near: [2.35, 48.85], $maxDistance: 1000}
+//   {$geoWithin: {$box: [[2.2, 48.8], [2.4, 48.9]]}}
 func (p *predicateParser) parseCommand(field string) (Expression, error) {
 	oldPos := p.pos
 	if p.expect('{') {
@@ -192,7 +226,27 @@ func (p *predicateParser) parseCommand(field string) (Expression, error) {
 				return &Exist{Field: field}, nil
 			}
 			return &NotExist{Field: field}, nil
-		case opIn, opNotIn:
+		case opIn:
+			values, err := p.parseValues()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			ci, err := p.parseOptions()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			return &In{Field: field, Values: values, CaseInsensitive: ci}, nil
+		case opEqual:
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			ci, err := p.parseOptions()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			return &Equal{Field: field, Value: value, CaseInsensitive: ci}, nil
+		case opNotIn, opAll:
 			values, err := p.parseValues()
 			if err != nil {
 				return nil, fmt.Errorf("%s: %v", label, err)
@@ -201,10 +255,65 @@ func (p *predicateParser) parseCommand(field string) (Expression, error) {
 			if !p.expect('}') {
 				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
 			}
-			if label == opIn {
-				return &In{Field: field, Values: values}, nil
+			switch label {
+			case opAll:
+				return &All{Field: field, Values: values}, nil
 			}
 			return &NotIn{Field: field, Values: values}, nil
+		case opSize:
+			n, err := p.parseInteger()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			if n < 0 {
+				return nil, fmt.Errorf("%s: must be positive", label)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			return &Size{Field: field, Value: int(n)}, nil
+		case opType:
+			t, err := p.parseString()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			if !isType(t) {
+				return nil, fmt.Errorf("%s: unknown type %q", label, t)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			return &Type{Field: field, Type: t}, nil
+		case opMod:
+			values, err := p.parseValues()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			mod := &Mod{Field: field}
+			if len(values) != 2 {
+				return nil, fmt.Errorf("%s: expected [divisor, remainder]", label)
+			}
+			for i, v := range values {
+				f, ok := v.(float64)
+				if !ok || f != math.Trunc(f) {
+					return nil, fmt.Errorf("%s: item #%d: not an integer", label, i)
+				}
+				if i == 0 {
+					mod.Divisor = int64(f)
+				} else {
+					mod.Remainder = int64(f)
+				}
+			}
+			if mod.Divisor == 0 {
+				return nil, fmt.Errorf("%s: divisor can't be 0", label)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			return mod, nil
 		case opNotEqual:
 			value, err := p.parseValue()
 			if err != nil {
@@ -234,7 +343,21 @@ func (p *predicateParser) parseCommand(field string) (Expression, error) {
 			case opGreaterOrEqual:
 				return &GreaterOrEqual{Field: field, Value: value}, nil
 			}
-		case opRegex, opNot:
+		case opNot:
+			if p.peek() == '{' {
+				// Negation of an operator expression on the field.
+				exp, err := p.parseCommand(field)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", label, err)
+				}
+				p.eatWhitespaces()
+				if !p.expect('}') {
+					return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+				}
+				return &Not{exp}, nil
+			}
+			fallthrough
+		case opRegex, opIRegex:
 			str, err := p.parseString()
 			if err != nil {
 				return nil, fmt.Errorf("%s: %v", label, err)
@@ -243,12 +366,59 @@ func (p *predicateParser) parseCommand(field string) (Expression, error) {
 			if err != nil {
 				return nil, fmt.Errorf("%s: invalid regex: %v", label, err)
 			}
+			ci := label == opIRegex
+			if label == opRegex {
+				if ci, err = p.parseOptions(); err != nil {
+					return nil, fmt.Errorf("%s: %v", label, err)
+				}
+			} else {
+				p.eatWhitespaces()
+				if !p.expect('}') {
+					return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+				}
+			}
+			if ci {
+				re = regexp.MustCompile("(?i)" + str)
+			}
+			negated := label == opNot
+			return &Regex{Field: field, Value: re, Negated: negated}, nil
+		case opNear:
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			point, ok := geoCoordinates(value)
+			if !ok {
+				return nil, fmt.Errorf("%s: invalid geo point", label)
+			}
+			maxDistance, err := p.parseMaxDistance()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			return &Near{Field: field, Point: point, MaxDistance: maxDistance}, nil
+		case opGeoWithin:
+			shape, err := p.parseGeoShape()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
 			p.eatWhitespaces()
 			if !p.expect('}') {
 				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
 			}
-			negated := label == opNot
-			return &Regex{Field: field, Value: re, Negated: negated}, nil
+			return &GeoWithin{Field: field, Shape: shape}, nil
+		case opSearch:
+			str, err := p.parseString()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", label, err)
+			}
+			if len(SearchTerms(str)) == 0 {
+				return nil, fmt.Errorf("%s: no search terms", label)
+			}
+			p.eatWhitespaces()
+			if !p.expect('}') {
+				return nil, fmt.Errorf("%s: expected '}' got %q", label, p.peek())
+			}
+			return &Search{Field: field, Value: str}, nil
 		case opElemMatch:
 			exps, err := p.parseExpressions()
 			if err != nil {
@@ -272,6 +442,140 @@ VALUE:
 	return &Equal{Field: field, Value: value}, nil
 }
 
+// parseOptions parses the optional $options flags following the value of an
+// operator and advance the cursor after the closing brace. The only supported
+// flag is "i", for case insensitive matching.
+func (p *predicateParser) parseOptions() (caseInsensitive bool, err error) {
+	p.eatWhitespaces()
+	if p.expect(',') {
+		p.eatWhitespaces()
+		label, err := p.parseLabel()
+		if err != nil {
+			return false, err
+		}
+		if label != opOptions {
+			return false, fmt.Errorf("unexpected label %q", label)
+		}
+		p.eatWhitespaces()
+		opts, err := p.parseString()
+		if err != nil {
+			return false, fmt.Errorf("%s: %v", label, err)
+		}
+		for _, o := range opts {
+			if o != 'i' {
+				return false, fmt.Errorf("%s: unsupported option %q", label, o)
+			}
+		}
+		caseInsensitive = opts != ""
+		p.eatWhitespaces()
+	}
+	if !p.expect('}') {
+		return false, fmt.Errorf("expected '}' got %q", p.peek())
+	}
+	return caseInsensitive, nil
+}
+
+// parseMaxDistance parses the optional $maxDistance following the point of
+// a $near operator and advance the cursor after the closing brace. Zero is
+// returned if no maximum distance is set.
+func (p *predicateParser) parseMaxDistance() (maxDistance float64, err error) {
+	p.eatWhitespaces()
+	if p.expect(',') {
+		p.eatWhitespaces()
+		label, err := p.parseLabel()
+		if err != nil {
+			return 0, err
+		}
+		if label != opMaxDistance {
+			return 0, fmt.Errorf("unexpected label %q", label)
+		}
+		p.eatWhitespaces()
+		if maxDistance, err = p.parseNumber(); err != nil {
+			return 0, fmt.Errorf("%s: %v", label, err)
+		}
+		if maxDistance <= 0 {
+			return 0, fmt.Errorf("%s: must be positive", label)
+		}
+		p.eatWhitespaces()
+	}
+	if !p.expect('}') {
+		return 0, fmt.Errorf("expected '}' got %q", p.peek())
+	}
+	return maxDistance, nil
+}
+
+// parseGeoShape parses the shape of a $geoWithin operator.
+//
+// Examples:
+//   {$box: [[2.2, 48.8], [2.4, 48.9]]}
+//   {$circle: [[2.35, 48.85], 1000]}
+//   {$polygon: [[2.2, 48.8], [2.4, 48.8], [2.3, 48.9]]}
+func (p *predicateParser) parseGeoShape() (GeoShape, error) {
+	if !p.expect('{') {
+		return nil, fmt.Errorf("expected '{' got %q", p.peek())
+	}
+	p.eatWhitespaces()
+	oldPos := p.pos
+	label, err := p.parseLabel()
+	if err != nil {
+		return nil, err
+	}
+	switch label {
+	case opBox, opCircle, opPolygon:
+	default:
+		p.pos = oldPos
+		return nil, fmt.Errorf("unknown shape %q", label)
+	}
+	p.eatWhitespaces()
+	values, err := p.parseValues()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", label, err)
+	}
+	var shape GeoShape
+	switch label {
+	case opBox:
+		if len(values) != 2 {
+			return nil, fmt.Errorf("%s: expected [south-west, north-east]", label)
+		}
+		points, err := geoPoints(values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", label, err)
+		}
+		sw, ne := points[0], points[1]
+		if sw.Lng > ne.Lng || sw.Lat > ne.Lat {
+			return nil, fmt.Errorf("%s: south-west corner must be lower than north-east corner", label)
+		}
+		shape = GeoBox{schema.GeoBounds{MinLat: sw.Lat, MaxLat: ne.Lat, MinLng: sw.Lng, MaxLng: ne.Lng}}
+	case opCircle:
+		if len(values) != 2 {
+			return nil, fmt.Errorf("%s: expected [center, radius]", label)
+		}
+		center, ok := geoCoordinates(values[0])
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid center", label)
+		}
+		radius, ok := values[1].(float64)
+		if !ok || radius <= 0 {
+			return nil, fmt.Errorf("%s: radius must be a positive number", label)
+		}
+		shape = GeoCircle{Center: center, Radius: radius}
+	case opPolygon:
+		if len(values) < 3 {
+			return nil, fmt.Errorf("%s: three points or more required", label)
+		}
+		points, err := geoPoints(values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", label, err)
+		}
+		shape = GeoPolygon(points)
+	}
+	p.eatWhitespaces()
+	if !p.expect('}') {
+		return nil, fmt.Errorf("expected '}' got %q", p.peek())
+	}
+	return shape, nil
+}
+
 // parseLabel parses a label with or without quotes and advance the curser right
 // after the ":".
 func (p *predicateParser) parseLabel() (label string, err error) {
@@ -443,6 +747,18 @@ func (p *predicateParser) parseNumber() (float64, error) {
 	return f, nil
 }
 
+// parseInteger parses a number and checks it is an integer.
+func (p *predicateParser) parseInteger() (int64, error) {
+	f, err := p.parseNumber()
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, errors.New("not an integer")
+	}
+	return int64(f), nil
+}
+
 // parseString parses a string.
 func (p *predicateParser) parseString() (string, error) {
 	if p.peek() != '"' {
@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
 )
 
 func TestParse(t *testing.T) {
@@ -113,6 +115,91 @@ func TestParse(t *testing.T) {
 			Predicate{&And{&Equal{Field: "foo", Value: "bar"}, &Equal{Field: "foo", Value: "baz"}}},
 			nil,
 		},
+		{
+			`{"foo": {"$not": {"$gt": 1}}}`,
+			Predicate{&Not{&GreaterThan{Field: "foo", Value: float64(1)}}},
+			nil,
+		},
+		{
+			`{"$not": {"foo": "bar", "bar": "baz"}}`,
+			Predicate{&Not{&Equal{Field: "foo", Value: "bar"}, &Equal{Field: "bar", Value: "baz"}}},
+			nil,
+		},
+		{
+			`{"$nor": [{"foo": "bar"}, {"foo": "baz"}]}`,
+			Predicate{&Nor{&Equal{Field: "foo", Value: "bar"}, &Equal{Field: "foo", Value: "baz"}}},
+			nil,
+		},
+		{
+			`{"foo": {"$all": ["bar", "baz"]}}`,
+			Predicate{&All{Field: "foo", Values: []Value{"bar", "baz"}}},
+			nil,
+		},
+		{
+			`{"foo": {"$size": 2}}`,
+			Predicate{&Size{Field: "foo", Value: 2}},
+			nil,
+		},
+		{
+			`{"foo": {"$eq": "bar"}}`,
+			Predicate{&Equal{Field: "foo", Value: "bar"}},
+			nil,
+		},
+		{
+			`{"foo": {"$eq": "bar", "$options": "i"}}`,
+			Predicate{&Equal{Field: "foo", Value: "bar", CaseInsensitive: true}},
+			nil,
+		},
+		{
+			`{"foo": {"$in": ["bar", "baz"], "$options": ""}}`,
+			Predicate{&In{Field: "foo", Values: []Value{"bar", "baz"}}},
+			nil,
+		},
+		{
+			`{"foo": {"$in": ["bar", "baz"], "$options": "i"}}`,
+			Predicate{&In{Field: "foo", Values: []Value{"bar", "baz"}, CaseInsensitive: true}},
+			nil,
+		},
+		{
+			`{"foo": {"$iregex": "b.r"}}`,
+			Predicate{&Regex{Field: "foo", Value: regexp.MustCompile("(?i)b.r")}},
+			nil,
+		},
+		{
+			`{"foo": {"$type": "string"}}`,
+			Predicate{&Type{Field: "foo", Type: "string"}},
+			nil,
+		},
+		{
+			`{"foo": {"$mod": [4, -1]}}`,
+			Predicate{&Mod{Field: "foo", Divisor: 4, Remainder: -1}},
+			nil,
+		},
+		{
+			`{"loc": {"$near": {"type": "Point", "coordinates": [2.35, 48.85]}, "$maxDistance": 1000}}`,
+			Predicate{&Near{Field: "loc", Point: GeoCoordinates{Lng: 2.35, Lat: 48.85}, MaxDistance: 1000}},
+			nil,
+		},
+		{
+			`{"loc": {"$geoWithin": {"$box": [{"lat": 1, "lng": 2}, [3, 4]]}}}`,
+			Predicate{&GeoWithin{Field: "loc", Shape: GeoBox{schema.GeoBounds{MinLat: 1, MaxLat: 4, MinLng: 2, MaxLng: 3}}}},
+			nil,
+		},
+		{
+			`{"loc": {"$geoWithin": {"$circle": [[2, 1], 10]}}}`,
+			Predicate{&GeoWithin{Field: "loc", Shape: GeoCircle{Center: GeoCoordinates{Lng: 2, Lat: 1}, Radius: 10}}},
+			nil,
+		},
+		{
+			`{"loc": {"$geoWithin": {"$polygon": [[0, 0], [1, 0], [0, 1]]}}}`,
+			Predicate{&GeoWithin{Field: "loc", Shape: GeoPolygon{{Lng: 0, Lat: 0}, {Lng: 1, Lat: 0}, {Lng: 0, Lat: 1}}}},
+			nil,
+		},
+		{
+			`{"foo": {"$search": "quick br*"}}`,
+			Predicate{&Search{Field: "foo", Value: "quick br*"}},
+			nil,
+		},
 		{
 			`{"$and": [{"foo": "bar", "bar": "baz"}, {"baz": "foo"}]}`,
 			Predicate{&And{&And{&Equal{Field: "foo", Value: "bar"}, &Equal{Field: "bar", Value: "baz"}}, &Equal{Field: "baz", Value: "foo"}}},
@@ -371,6 +458,106 @@ func TestParse(t *testing.T) {
 			Predicate{},
 			errors.New("char 1: $not: invalid placement"),
 		},
+		{
+			`{"$eq": 1}`,
+			Predicate{},
+			errors.New("char 1: $eq: invalid placement"),
+		},
+		{
+			`{"foo": {"$eq": "bar", "$options": "x"}}`,
+			Predicate{},
+			errors.New("char 38: foo: $eq: $options: unsupported option 'x'"),
+		},
+		{
+			`{"foo": {"$in": ["bar"], "bar": "i"}}`,
+			Predicate{},
+			errors.New("char 31: foo: $in: unexpected label \"bar\""),
+		},
+		{
+			`{"$size": 1}`,
+			Predicate{},
+			errors.New("char 1: $size: invalid placement"),
+		},
+		{
+			`{"$nor": []}`,
+			Predicate{},
+			errors.New("char 11: $nor: one expressions or more required"),
+		},
+		{
+			`{"foo": {"$size": 1.5}}`,
+			Predicate{},
+			errors.New("char 21: foo: $size: not an integer"),
+		},
+		{
+			`{"foo": {"$type": "foo"}}`,
+			Predicate{},
+			errors.New("char 23: foo: $type: unknown type \"foo\""),
+		},
+		{
+			`{"foo": {"$mod": [0, 1]}}`,
+			Predicate{},
+			errors.New("char 23: foo: $mod: divisor can't be 0"),
+		},
+		{
+			`{"foo": {"$mod": [4]}}`,
+			Predicate{},
+			errors.New("char 20: foo: $mod: expected [divisor, remainder]"),
+		},
+		{
+			`{"loc": {"$near": [200, 0]}}`,
+			Predicate{},
+			errors.New("char 26: loc: $near: invalid geo point"),
+		},
+		{
+			`{"loc": {"$near": [2, 1], "$maxDistance": -1}}`,
+			Predicate{},
+			errors.New("char 44: loc: $near: $maxDistance: must be positive"),
+		},
+		{
+			`{"loc": {"$near": [2, 1], "$options": "i"}}`,
+			Predicate{},
+			errors.New("char 37: loc: $near: unexpected label \"$options\""),
+		},
+		{
+			`{"$near": [2, 1]}`,
+			Predicate{},
+			errors.New("char 1: $near: invalid placement"),
+		},
+		{
+			`{"loc": {"$geoWithin": {"$square": [[1, 2], [3, 4]]}}}`,
+			Predicate{},
+			errors.New("char 24: loc: $geoWithin: unknown shape \"$square\""),
+		},
+		{
+			`{"loc": {"$geoWithin": {"$box": [[3, 4], [1, 2]]}}}`,
+			Predicate{},
+			errors.New("char 48: loc: $geoWithin: $box: south-west corner must be lower than north-east corner"),
+		},
+		{
+			`{"loc": {"$geoWithin": {"$circle": [[1, 2], -5]}}}`,
+			Predicate{},
+			errors.New("char 47: loc: $geoWithin: $circle: radius must be a positive number"),
+		},
+		{
+			`{"loc": {"$geoWithin": {"$polygon": [[0, 0], [1, 0]]}}}`,
+			Predicate{},
+			errors.New("char 52: loc: $geoWithin: $polygon: three points or more required"),
+		},
+		{
+			`{"foo": {"$search": " ,* "}}`,
+			Predicate{},
+			errors.New("char 26: foo: $search: no search terms"),
+		},
+		{
+			`{"foo": {"$search": 1}}`,
+			Predicate{},
+			errors.New("char 20: foo: $search: not a string"),
+		},
+		{
+			`{"$search": "foo"}`,
+			Predicate{},
+			errors.New("char 1: $search: invalid placement"),
+		},
 	}
 	for i := range tests {
 		tt := tests[i]
@@ -206,6 +206,184 @@ func TestMatch(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			`{"foo": {"$eq": "bob", "$options": "i"}}`, []test{
+				{map[string]interface{}{"foo": "Bob"}, true},
+				{map[string]interface{}{"foo": []interface{}{"alice", "BOB"}}, true},
+				{map[string]interface{}{"foo": "bobby"}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$eq": "bob"}}`, []test{
+				{map[string]interface{}{"foo": "bob"}, true},
+				{map[string]interface{}{"foo": "Bob"}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$in": ["bob", 1], "$options": "i"}}`, []test{
+				{map[string]interface{}{"foo": "BOB"}, true},
+				{map[string]interface{}{"foo": float64(1)}, true},
+				{map[string]interface{}{"foo": "alice"}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$iregex": "^bo"}}`, []test{
+				{map[string]interface{}{"foo": "Bob"}, true},
+				{map[string]interface{}{"foo": "abo"}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$regex": "^bo", "$options": "i"}}`, []test{
+				{map[string]interface{}{"foo": "BOB"}, true},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$not": {"$gt": 1}}}`, []test{
+				{map[string]interface{}{"foo": 1}, true},
+				{map[string]interface{}{"foo": 2}, false},
+				{map[string]interface{}{"bar": 2}, true},
+			},
+			&schemaFooInteger,
+		},
+		{
+			`{"$not": {"foo": "bar", "bar": 1}}`, []test{
+				{map[string]interface{}{"foo": "bar"}, true},
+				{map[string]interface{}{"foo": "bar", "bar": float64(1)}, false},
+			},
+			nil,
+		},
+		{
+			`{"$nor": [{"foo": "bar"}, {"bar": 1}]}`, []test{
+				{map[string]interface{}{"foo": "bar"}, false},
+				{map[string]interface{}{"bar": float64(1)}, false},
+				{map[string]interface{}{"foo": "baz", "bar": float64(2)}, true},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$all": ["bar", "baz"]}}`, []test{
+				{map[string]interface{}{"foo": []interface{}{"baz", "foo", "bar"}}, true},
+				{map[string]interface{}{"foo": []interface{}{"bar"}}, false},
+				{map[string]interface{}{"foo": "bar"}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$all": ["bar"]}}`, []test{
+				{map[string]interface{}{"foo": "bar"}, true},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$size": 2}}`, []test{
+				{map[string]interface{}{"foo": []interface{}{"bar", "baz"}}, true},
+				{map[string]interface{}{"foo": []interface{}{"bar"}}, false},
+				{map[string]interface{}{"foo": "ba"}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$type": "number"}}`, []test{
+				{map[string]interface{}{"foo": 1}, true},
+				{map[string]interface{}{"foo": 1.5}, true},
+				{map[string]interface{}{"foo": "1"}, false},
+				{map[string]interface{}{}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$type": "int"}}`, []test{
+				{map[string]interface{}{"foo": 1}, true},
+				{map[string]interface{}{"foo": 1.5}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$type": "null"}}`, []test{
+				{map[string]interface{}{"foo": nil}, true},
+				{map[string]interface{}{}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$type": "array"}}`, []test{
+				{map[string]interface{}{"foo": []interface{}{}}, true},
+				{map[string]interface{}{"foo": map[string]interface{}{}}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$mod": [4, 1]}}`, []test{
+				{map[string]interface{}{"foo": 5}, true},
+				{map[string]interface{}{"foo": 5.5}, true},
+				{map[string]interface{}{"foo": 8}, false},
+				{map[string]interface{}{"foo": "5"}, false},
+			},
+			&schemaFooInteger,
+		},
+		{
+			`{"loc": {"$near": {"lat": 48.8566, "lng": 2.3522}, "$maxDistance": 5000}}`, []test{
+				{map[string]interface{}{"loc": schema.NewGeoJSONPoint(2.2945, 48.8584)}, true}, // ~4.2km
+				{map[string]interface{}{"loc": []interface{}{2.1301, 48.8049}}, false},         // ~17km
+				{map[string]interface{}{"loc": "paris"}, false},
+				{map[string]interface{}{}, false},
+			},
+			nil,
+		},
+		{
+			`{"loc": {"$near": [2.3522, 48.8566]}}`, []test{
+				{map[string]interface{}{"loc": []interface{}{-74.006, 40.7128}}, true},
+				{map[string]interface{}{}, false},
+			},
+			nil,
+		},
+		{
+			`{"loc": {"$geoWithin": {"$box": [[2.2, 48.8], [2.4, 48.9]]}}}`, []test{
+				{map[string]interface{}{"loc": []interface{}{2.3522, 48.8566}}, true},
+				{map[string]interface{}{"loc": []interface{}{2.1, 48.85}}, false},
+			},
+			nil,
+		},
+		{
+			`{"loc": {"$geoWithin": {"$circle": [[2.3522, 48.8566], 5000]}}}`, []test{
+				{map[string]interface{}{"loc": []interface{}{2.2945, 48.8584}}, true},
+				{map[string]interface{}{"loc": []interface{}{2.1301, 48.8049}}, false},
+			},
+			nil,
+		},
+		{
+			`{"loc": {"$geoWithin": {"$polygon": [[0, 0], [10, 0], [10, 10], [5, 5], [0, 10]]}}}`, []test{
+				{map[string]interface{}{"loc": []interface{}{2.0, 2.0}}, true},
+				{map[string]interface{}{"loc": []interface{}{5.0, 8.0}}, false},
+				{map[string]interface{}{"loc": []interface{}{9.0, 8.0}}, true},
+				{map[string]interface{}{"loc": []interface{}{11.0, 2.0}}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$search": "Quick fox"}}`, []test{
+				{map[string]interface{}{"foo": "The quick brown fox"}, true},
+				{map[string]interface{}{"foo": "THE QUICK, BROWN FOX!"}, true},
+				{map[string]interface{}{"foo": "The quick brown dog"}, false},
+				{map[string]interface{}{"foo": "The quickest fox"}, false},
+				{map[string]interface{}{"foo": 1}, false},
+				{map[string]interface{}{}, false},
+			},
+			nil,
+		},
+		{
+			`{"foo": {"$search": "qui* fox"}}`, []test{
+				{map[string]interface{}{"foo": "The quickest fox"}, true},
+				{map[string]interface{}{"foo": "The quiet fox"}, true},
+				{map[string]interface{}{"foo": "The fast fox"}, false},
+			},
+			nil,
+		},
 	}
 	for i := range tests {
 		tt := tests[i]
@@ -252,6 +430,27 @@ func TestString(t *testing.T) {
 		`{"foo": ["bar", "baz"]}`:                                 `{foo: ["bar","baz"]}`,
 		`{"foo.bar": "baz"}`:                                      `{foo.bar: "baz"}`,
 		`{"foo":{"$elemMatch":{"a":"bar","b":"baz"}}}`:            `{foo: {$elemMatch: {a: "bar", b: "baz"}}}`,
+		`{"foo": {"$not": {"$gt": 1}}}`:                           `{$not: {foo: {$gt: 1}}}`,
+		`{"$not": {"foo": "bar", "bar": 1}}`:                      `{$not: {foo: "bar", bar: 1}}`,
+		`{"$nor": [{"foo": "bar"}, {"bar": 1}]}`:                  `{$nor: [{foo: "bar"}, {bar: 1}]}`,
+		`{"foo": {"$all": ["bar", "baz"]}}`:                       `{foo: {$all: ["bar", "baz"]}}`,
+		`{"foo": {"$size": 2}}`:                                   `{foo: {$size: 2}}`,
+		`{"foo": {"$type": "string"}}`:                            `{foo: {$type: "string"}}`,
+		`{"foo": {"$mod": [4, 0]}}`:                               `{foo: {$mod: [4, 0]}}`,
+		`{"foo": {"$eq": "bar"}}`:                                 `{foo: "bar"}`,
+		`{"foo": {"$eq": "bar", "$options": "i"}}`:                `{foo: {$eq: "bar", $options: "i"}}`,
+		`{"foo": {"$in": ["bar"], "$options": "i"}}`:              `{foo: {$in: ["bar"], $options: "i"}}`,
+		`{"foo": {"$iregex": "^bar"}}`:                            `{foo: {$regex: "(?i)^bar"}}`,
+
+		// Geospatial operators.
+		`{"loc": {"$near": {"lat": 48.85, "lng": 2.35}}}`:                 `{loc: {$near: [2.35, 48.85]}}`,
+		`{"loc": {"$near": [2.35, 48.85], "$maxDistance": 1e3}}`:          `{loc: {$near: [2.35, 48.85], $maxDistance: 1000}}`,
+		`{"loc": {"$geoWithin": {"$box": [[1, 2], [3, 4]]}}}`:             `{loc: {$geoWithin: {$box: [[1, 2], [3, 4]]}}}`,
+		`{"loc": {"$geoWithin": {"$circle": [[1, 2], 10]}}}`:              `{loc: {$geoWithin: {$circle: [[1, 2], 10]}}}`,
+		`{"loc": {"$geoWithin": {"$polygon": [[0, 0], [1, 0], [0, 1]]}}}`: `{loc: {$geoWithin: {$polygon: [[0, 0], [1, 0], [0, 1]]}}}`,
+
+		// Full-text search.
+		`{"foo": {"$search": "quick br*"}}`: `{foo: {$search: "quick br*"}}`,
 	}
 	for query, want := range tests {
 		q, err := ParsePredicate(query)
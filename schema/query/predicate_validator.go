@@ -1,20 +1,142 @@
 package query
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/entropyinf/rest-layer/schema"
 )
 
+// CheckReadPermission returns an error if the caller identified by ctx may not
+// read one of the fields used by the predicate, or one of their parents, so
+// filters can't be used to guess the value of unreadable fields. As for
+// aggregations, read permissions are called with a nil document.
+func (e Predicate) CheckReadPermission(ctx context.Context, validator schema.Validator) error {
+	return checkExpressionsReadPermission(ctx, e, validator)
+}
+
+func checkExpressionsReadPermission(ctx context.Context, exps []Expression, validator schema.Validator) error {
+	for _, exp := range exps {
+		var err error
+		switch t := exp.(type) {
+		case *And:
+			err = checkExpressionsReadPermission(ctx, *t, validator)
+		case *Or:
+			err = checkExpressionsReadPermission(ctx, *t, validator)
+		case *Nor:
+			err = checkExpressionsReadPermission(ctx, *t, validator)
+		case *Not:
+			err = checkExpressionsReadPermission(ctx, *t, validator)
+		case *ElemMatch:
+			if err = checkFieldReadPermission(ctx, t.Field, validator); err == nil {
+				if f := validator.GetField(t.Field); f != nil {
+					if arr, ok := f.Validator.(*schema.Array); ok {
+						if obj, ok := arr.Values.Validator.(*schema.Object); ok && obj.Schema != nil {
+							err = checkExpressionsReadPermission(ctx, t.Exps, obj.Schema)
+						}
+					}
+				}
+			}
+		default:
+			if field := expressionField(exp); field != "" {
+				err = checkFieldReadPermission(ctx, field, validator)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expressionField returns the field the expression applies to, if any.
+func expressionField(exp Expression) string {
+	switch t := exp.(type) {
+	case *In:
+		return t.Field
+	case *NotIn:
+		return t.Field
+	case *Equal:
+		return t.Field
+	case *NotEqual:
+		return t.Field
+	case *Exist:
+		return t.Field
+	case *NotExist:
+		return t.Field
+	case *GreaterThan:
+		return t.Field
+	case *GreaterOrEqual:
+		return t.Field
+	case *LowerThan:
+		return t.Field
+	case *LowerOrEqual:
+		return t.Field
+	case *Regex:
+		return t.Field
+	case *All:
+		return t.Field
+	case *Size:
+		return t.Field
+	case *Type:
+		return t.Field
+	case *Mod:
+		return t.Field
+	case *Near:
+		return t.Field
+	case *GeoWithin:
+		return t.Field
+	case *Search:
+		return t.Field
+	}
+	return ""
+}
+
 func prepareExpressions(exps []Expression, validator schema.Validator) error {
 	for _, exp := range exps {
 		if err := exp.Prepare(validator); err != nil {
 			return err
 		}
+		if err := checkEncryptedExpression(exp, validator); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// checkEncryptedExpression ensures encrypted fields are only used with the
+// operators comparing ciphertexts for equality: $eq and $in, without options.
+func checkEncryptedExpression(exp Expression, validator schema.Validator) error {
+	field := expressionField(exp)
+	if field == "" || !isEncryptedField(field, validator) {
+		return nil
+	}
+	switch t := exp.(type) {
+	case *Equal:
+		if !t.CaseInsensitive {
+			return nil
+		}
+	case *In:
+		if !t.CaseInsensitive {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: only %s and %s without options are supported on encrypted fields", field, opEqual, opIn)
+}
+
+// isEncryptedField tells if field is stored encrypted.
+func isEncryptedField(field string, validator schema.Validator) bool {
+	f := validator.GetField(field)
+	if f == nil {
+		return false
+	}
+	switch f.Validator.(type) {
+	case *schema.Encrypted, schema.Encrypted:
+		return true
+	}
+	return false
+}
+
 func getValidatorField(field string, validator schema.Validator) (f *schema.Field, err error) {
 	f = validator.GetField(field)
 	if f == nil {
@@ -31,6 +153,31 @@ func validateField(field string, validator schema.Validator) error {
 	return err
 }
 
+// prepareArrayField checks field is a filterable array.
+func prepareArrayField(field string, validator schema.Validator) error {
+	f, err := getValidatorField(field, validator)
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Validator.(*schema.Array); !ok {
+		return fmt.Errorf("%s: is not an array", field)
+	}
+	return nil
+}
+
+// prepareGeoField checks field is a filterable geo point.
+func prepareGeoField(field string, validator schema.Validator) error {
+	f, err := getValidatorField(field, validator)
+	if err != nil {
+		return err
+	}
+	switch f.Validator.(type) {
+	case schema.GeoPoint, *schema.GeoPoint:
+		return nil
+	}
+	return fmt.Errorf("%s: not a geo point field", field)
+}
+
 func prepareValues(field string, values []Value, validator schema.Validator) error {
 	f, err := getValidatorField(field, validator)
 	if err != nil {
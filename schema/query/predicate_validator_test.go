@@ -1,6 +1,7 @@
 package query
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
@@ -91,12 +92,68 @@ func TestPrepareErrors(t *testing.T) {
 			"foo": schema.Field{Validator: schema.String{}, Filterable: true},
 			"bar": schema.Field{Validator: schema.Integer{}, Filterable: true},
 			"baz": schema.Field{Validator: schema.Integer{}, Filterable: false},
+			"enc": schema.Field{Validator: &schema.Encrypted{Keys: schema.StaticKeys{"k": []byte("0123456789abcdef")}, KeyID: "k"}, Filterable: true},
+			"det": schema.Field{Validator: &schema.Encrypted{Keys: schema.StaticKeys{"k": []byte("0123456789abcdef")}, KeyID: "k", Deterministic: true}, Filterable: true},
+			"arr": schema.Field{Validator: &schema.Array{Values: schema.Field{Validator: &schema.Integer{}}}, Filterable: true},
+			"loc": schema.Field{Validator: &schema.GeoPoint{}, Filterable: true},
 		},
 	}
 	tests := []struct {
 		query string
 		want  error
 	}{
+		{
+			`{"loc": {"$near": [1, 2]}, "foo": {"$near": [1, 2]}}`,
+			errors.New("foo: not a geo point field"),
+		},
+		{
+			`{"loc": {"$geoWithin": {"$circle": [[1, 2], 10]}}, "bar": {"$geoWithin": {"$circle": [[1, 2], 10]}}}`,
+			errors.New("bar: not a geo point field"),
+		},
+		{
+			`{"foo": {"$search": "bar"}, "bar": {"$search": "1"}}`,
+			errors.New("bar: invalid query expression: not an integer"),
+		},
+		{
+			`{"enc": {"$search": "bar"}}`,
+			errors.New("enc: can't search an encrypted field"),
+		},
+		{
+			`{"foo": {"$size": 1}}`,
+			errors.New("foo: is not an array"),
+		},
+		{
+			`{"arr": {"$all": [1, "a"]}}`,
+			errors.New("arr: invalid query expression `\"a\"': invalid value at #1: not an integer"),
+		},
+		{
+			`{"foo": {"$mod": [2, 0]}}`,
+			errors.New("foo: not a numeric field"),
+		},
+		{
+			`{"foo": {"$not": {"$gt": 1}}}`,
+			errors.New("foo: invalid query expression: not a string"),
+		},
+		{
+			`{"$nor": [{"baz": 1}]}`,
+			errors.New("baz: field is not filterable"),
+		},
+		{
+			`{"unknown": {"$type": "string"}}`,
+			errors.New("unknown: unknown query field"),
+		},
+		{
+			`{"enc": "foo"}`,
+			errors.New("enc: invalid query expression: can't be filtered"),
+		},
+		{
+			`{"det": {"$gt": "foo"}}`,
+			errors.New("det: not-comparable"),
+		},
+		{
+			`{"det": {"$regex": "fo+"}}`,
+			errors.New("det: can't match an encrypted field"),
+		},
 
 		{
 			`{"foo": 1}`,
@@ -180,3 +237,77 @@ func TestPrepareErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestPredicateCheckReadPermission(t *testing.T) {
+	deny := func(ctx context.Context, doc map[string]interface{}) bool { return false }
+	s := schema.Schema{Fields: schema.Fields{
+		"name":   {Filterable: true},
+		"salary": {Filterable: true, ReadPermission: deny, Validator: &schema.Integer{}},
+		"meta": {ReadPermission: deny, Schema: &schema.Schema{Fields: schema.Fields{
+			"x": {Filterable: true, Validator: &schema.Integer{}},
+		}}},
+		"items": {Filterable: true, Validator: &schema.Array{Values: schema.Field{Validator: &schema.Object{
+			Schema: &schema.Schema{Fields: schema.Fields{
+				"secret": {Filterable: true, ReadPermission: deny},
+			}},
+		}}}},
+	}}
+	tests := map[string]string{
+		`{name: "a"}`:                                      "",
+		`{salary: {$gt: 100000}}`:                          "salary: permission denied",
+		`{$or: [{name: "a"}, {salary: {$gt: 1}}]}`:         "salary: permission denied",
+		`{meta.x: 1}`:                                      "meta.x: permission denied",
+		`{items: {$elemMatch: {secret: "a"}}}`:             "secret: permission denied",
+		`{$nor: [{name: "a"}, {salary: {$exists: true}}]}`: "salary: permission denied",
+	}
+	for predicate, want := range tests {
+		t.Run(predicate, func(t *testing.T) {
+			p, err := ParsePredicate(predicate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Prepare(s); err != nil {
+				t.Fatal(err)
+			}
+			err = p.CheckReadPermission(context.Background(), s)
+			if (err == nil && want != "") || (err != nil && err.Error() != want) {
+				t.Errorf("CheckReadPermission() error = %v, want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestPrepareEncrypted(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"ssn": {Filterable: true, Sortable: true, Validator: &schema.Encrypted{
+			Keys:          schema.StaticKeys{"k1": []byte("0123456789abcdef")},
+			KeyID:         "k1",
+			Deterministic: true,
+		}},
+	}}
+	tests := map[string]string{
+		`{ssn: "123"}`:                           "",
+		`{ssn: {$eq: "123"}}`:                    "",
+		`{ssn: {$in: ["123", "456"]}}`:           "",
+		`{ssn: {$eq: "abc", $options: "i"}}`:     "ssn: only $eq and $in without options are supported on encrypted fields",
+		`{ssn: {$ne: "123"}}`:                    "ssn: only $eq and $in without options are supported on encrypted fields",
+		`{ssn: {$type: "string"}}`:               "ssn: only $eq and $in without options are supported on encrypted fields",
+		`{$or: [{ssn: {$ne: "1"}}, {ssn: "2"}]}`: "ssn: only $eq and $in without options are supported on encrypted fields",
+	}
+	for predicate, want := range tests {
+		t.Run(predicate, func(t *testing.T) {
+			p, err := ParsePredicate(predicate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.Prepare(s)
+			if (err == nil && want != "") || (err != nil && err.Error() != want) {
+				t.Errorf("Prepare() error = %v, want %q", err, want)
+			}
+		})
+	}
+	want := "ssn: encrypted fields can't be sorted"
+	if err := MustParseSort("ssn").Validate(s); err == nil || err.Error() != want {
+		t.Errorf("Sort.Validate() error = %v, want %q", err, want)
+	}
+}
@@ -30,6 +30,91 @@ type ProjectionField struct {
 
 	// Children holds references to child projections if any.
 	Children Projection
+
+	// Exclude removes the field from the representation. A projection made
+	// of excluded fields only selects all the other fields.
+	Exclude bool
+
+	// Filter selects the items of an array, reference list or sub-resource
+	// list field matching the predicate.
+	Filter Predicate
+
+	// Slice selects a range of the items of an array, reference list or
+	// sub-resource list field. Slicing is applied after filtering.
+	Slice *ProjectionSlice
+}
+
+// ProjectionSlice selects a range of items of a list. As with Go slices, the
+// item at End is not included. Negative indexes are relative to the end of
+// the list.
+type ProjectionSlice struct {
+	Start int
+
+	// End is the index of the first item after the range. If nil, the range
+	// stops at the end of the list.
+	End *int
+}
+
+// negative tells if the slice has an index relative to the end of the list.
+func (s ProjectionSlice) negative() bool {
+	return s.Start < 0 || (s.End != nil && *s.End < 0)
+}
+
+// window returns the window selecting the slice of the items of w, or of all
+// the items if w is nil. If the slice has no end, limit items are selected. The
+// slice must not be negative.
+func (s ProjectionSlice) window(w *Window, limit int) *Window {
+	sw := &Window{Offset: s.Start, Limit: limit}
+	if s.End != nil {
+		sw.Limit = nonNegative(*s.End - s.Start)
+	}
+	if w != nil {
+		sw.Offset += w.Offset
+		if remaining := nonNegative(w.Limit - s.Start); w.Limit >= 0 && (sw.Limit < 0 || sw.Limit > remaining) {
+			sw.Limit = remaining
+		}
+	}
+	return sw
+}
+
+func nonNegative(i int) int {
+	if i < 0 {
+		return 0
+	}
+	return i
+}
+
+// bounds returns the bounds of the slice for a list of n items.
+func (s ProjectionSlice) bounds(n int) (start, end int) {
+	clamp := func(i int) int {
+		if i < 0 {
+			i += n
+		}
+		if i < 0 {
+			return 0
+		}
+		if i > n {
+			return n
+		}
+		return i
+	}
+	start, end = clamp(s.Start), n
+	if s.End != nil {
+		end = clamp(*s.End)
+	}
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// String output the slice in its DSL form.
+func (s ProjectionSlice) String() string {
+	end := ""
+	if s.End != nil {
+		end = strconv.Itoa(*s.End)
+	}
+	return "[" + strconv.Itoa(s.Start) + ":" + end + "]"
 }
 
 // Validate validates the projection against the provided validator.
@@ -54,11 +139,22 @@ func (p Projection) String() string {
 // String output the projection field in its DSL form.
 func (pf ProjectionField) String() string {
 	buf := &bytes.Buffer{}
+	if pf.Exclude {
+		buf.WriteByte('-')
+	}
 	if pf.Alias != "" {
 		buf.WriteString(pf.Alias)
 		buf.WriteByte(':')
 	}
 	buf.WriteString(pf.Name)
+	if len(pf.Filter) > 0 {
+		buf.WriteByte('[')
+		buf.WriteString(pf.Filter.String())
+		buf.WriteByte(']')
+	}
+	if pf.Slice != nil {
+		buf.WriteString(pf.Slice.String())
+	}
 	if len(pf.Params) > 0 {
 		buf.WriteByte('(')
 		names := make([]string, 0, len(pf.Params))
@@ -29,11 +29,24 @@ type Resource interface {
 	Path() string
 }
 
+// LimitedResource is a Resource bounding the queries it accepts. When the
+// Resource given to Projection.Eval implements it, the default number of items
+// embedded by connections is capped by its MaxLimit.
+type LimitedResource interface {
+	Resource
+
+	// QueryLimits returns the limits of the queries on the resource.
+	QueryLimits() Limits
+}
+
 // Eval evaluate the projection on the given payload with the help of the
 // validator. The resolver is used to fetch payload of references outside of the
 // provided payload.
 func (p Projection) Eval(ctx context.Context, payload map[string]interface{}, rsc Resource) (map[string]interface{}, error) {
-	rbr := &referenceBatchResolver{}
+	rbr := &referenceBatchResolver{connectionLimit: defaultConnectionItems}
+	if lr, ok := rsc.(LimitedResource); ok {
+		rbr.connectionLimit = lr.QueryLimits().ConnectionLimit()
+	}
 	validator := rsc.Validator()
 	payload, err := evalProjection(ctx, p, payload, validator, rbr, rsc)
 	if err == nil {
@@ -43,8 +56,14 @@ func (p Projection) Eval(ctx context.Context, payload map[string]interface{}, rs
 	return payload, err
 }
 
-func prepareProjection(p Projection, payload map[string]interface{}) (Projection, error) {
+func prepareProjection(p Projection, payload map[string]interface{}, fg schema.FieldGetter) (Projection, error) {
 	var proj Projection
+	// Computed fields are not part of the payload, those evaluated by default
+	// are added when all fields are selected.
+	var computed []string
+	if l, ok := fg.(schema.ComputedFieldLister); ok {
+		computed = l.DefaultComputedFields()
+	}
 	if len(p) == 0 {
 		// When the Projection is empty, it's like saying "all fields".
 		// This allows notations like id,user{} to embed all fields of the user
@@ -52,13 +71,21 @@ func prepareProjection(p Projection, payload map[string]interface{}) (Projection
 		for fn := range payload {
 			proj = append(proj, ProjectionField{Name: fn})
 		}
+		for _, fn := range computed {
+			if _, found := payload[fn]; !found {
+				proj = append(proj, ProjectionField{Name: fn})
+			}
+		}
 		return proj, nil
 	}
 
 	hasStar := false
 	var starChildren Projection
+	excluded := map[string]bool{}
 	for _, pf := range p {
-		if pf.Name == "*" {
+		if pf.Exclude {
+			excluded[pf.Name] = true
+		} else if pf.Name == "*" {
 			if hasStar {
 				return nil, fmt.Errorf("only one * in projection allowed")
 			}
@@ -68,8 +95,21 @@ func prepareProjection(p Projection, payload map[string]interface{}) (Projection
 			proj = append(proj, pf)
 		}
 	}
+	if len(proj) == 0 && !hasStar {
+		// A projection made of excluded fields only selects all the others.
+		hasStar = true
+	}
 	if hasStar {
+		names := make([]string, 0, len(payload)+len(computed))
 		for fn := range payload {
+			names = append(names, fn)
+		}
+		for _, fn := range computed {
+			if _, found := payload[fn]; !found {
+				names = append(names, fn)
+			}
+		}
+		for _, fn := range names {
 			exists := false
 			for _, pf := range proj {
 				if fn == pf.Name && pf.Alias == "" {
@@ -81,6 +121,16 @@ func prepareProjection(p Projection, payload map[string]interface{}) (Projection
 			}
 		}
 	}
+	if len(excluded) > 0 {
+		selected := proj[:0]
+		for _, pf := range proj {
+			// Aliased fields are explicitly selected.
+			if !excluded[pf.Name] || pf.Alias != "" {
+				selected = append(selected, pf)
+			}
+		}
+		proj = selected
+	}
 	return proj, nil
 }
 
@@ -104,7 +154,7 @@ func evalProjectionArray(ctx context.Context, pf ProjectionField, payload []inte
 		e := &In{Field: "id", Values: payload}
 		q := &Query{
 			Projection: pf.Children,
-			Predicate:  Predicate{e},
+			Predicate:  append(Predicate{e}, pf.Filter...),
 		}
 		subRsc, err := rsc.SubResource(ctx, fieldType.Path)
 		if err != nil {
@@ -122,6 +172,11 @@ func evalProjectionArray(ctx context.Context, pf ProjectionField, payload []inte
 				return fmt.Errorf("%s: error resolving field handler on sub-field: %v", name, err)
 			}
 			vv := v.([]map[string]interface{})
+			if pf.Filter != nil && pf.Slice != nil {
+				// Filtered references are sliced once resolved.
+				start, end := pf.Slice.bounds(len(vv))
+				vv = vv[start:end]
+			}
 			resMu.Lock()
 			for _, item := range vv {
 				res = append(res, item)
@@ -174,7 +229,7 @@ func evalProjection(ctx context.Context, p Projection, payload map[string]interf
 	res := map[string]interface{}{}
 	resMu := sync.Mutex{}
 	var err error
-	p, err = prepareProjection(p, payload)
+	p, err = prepareProjection(p, payload, fg)
 	if err != nil {
 		return nil, err
 	}
@@ -186,20 +241,29 @@ func evalProjection(ctx context.Context, p Projection, payload map[string]interf
 			name = pf.Alias
 		}
 		def := fg.GetField(pf.Name)
-		// Skip hidden fields
-		if def != nil && def.Hidden {
+		// Skip hidden fields and fields the caller is not allowed to read.
+		if def != nil && (def.Hidden || !def.CanRead(ctx, payload)) {
 			continue
 		}
-		if val, found := payload[pf.Name]; found {
-			// Handle sub field selection (if field has a value)
-			if len(pf.Children) > 0 && val != nil {
-				if def != nil && def.Schema != nil {
+		val, found := payload[pf.Name]
+		if def != nil && def.Compute != nil {
+			if val, err = def.Compute(ctx, payload); err != nil {
+				return nil, fmt.Errorf("%s: %v", pf.Name, err)
+			}
+			found = true
+		}
+		if found {
+			// Handle sub field selection (if field has a value). Sub-schema
+			// documents are always evaluated so their fields are filtered too.
+			sub := subSchema(def, val)
+			if (len(pf.Children) > 0 || sub != nil || pf.Filter != nil || pf.Slice != nil) && val != nil {
+				if sub != nil {
 					subval, ok := val.(map[string]interface{})
 					if !ok {
 						return nil, fmt.Errorf("%s: invalid value: not a dict", pf.Name)
 					}
 					var err error
-					if subval, err = evalProjection(ctx, pf.Children, subval, def.Schema, rbr, rsc); err != nil {
+					if subval, err = evalProjection(ctx, pf.Children, subval, sub, rbr, rsc); err != nil {
 						return nil, fmt.Errorf("%s.%v", pf.Name, err)
 					}
 					if res[name], err = resolveFieldHandler(ctx, pf, def, subval); err != nil {
@@ -234,6 +298,20 @@ func evalProjection(ctx context.Context, p Projection, payload map[string]interf
 				} else if array, ok := def.Validator.(*schema.Array); ok {
 					if payload, ok := val.([]interface{}); ok {
 						var err error
+						_, isRef := array.Values.Validator.(*schema.Reference)
+						if !isRef {
+							payload = filterItems(pf.Filter, payload)
+						}
+						if !isRef || pf.Filter == nil {
+							// Filtered references are sliced once resolved.
+							payload = sliceItems(pf.Slice, payload)
+						}
+						if len(pf.Children) == 0 && (!isRef || pf.Filter == nil) {
+							if res[name], err = resolveFieldHandler(ctx, pf, def, payload); err != nil {
+								return nil, err
+							}
+							continue
+						}
 						var subvalp *[]interface{}
 						if subvalp, err = evalProjectionArray(ctx, pf, payload, &array.Values, rbr, rsc); err != nil {
 							return nil, fmt.Errorf("%s: error applying projection on array item #%d: %v", pf.Name, i, err)
@@ -272,7 +350,7 @@ func evalProjection(ctx context.Context, p Projection, payload map[string]interf
 				if !ok {
 					return nil, fmt.Errorf("%s: error applying projection on sub-resource: item lacks ID field", pf.Name)
 				}
-				q, err := connectionQuery(pf, ref.Field, id, ref.Validator)
+				q, err := connectionQuery(pf, ref.Field, id, ref.Validator, rbr.connectionLimit)
 				if err != nil {
 					return nil, err
 				}
@@ -301,8 +379,34 @@ func evalProjection(ctx context.Context, p Projection, payload map[string]interf
 	return res, nil
 }
 
-// connectionQuery builds a query from a projection field on a schema.Connection type field.
-func connectionQuery(pf ProjectionField, field string, id interface{}, validator schema.Validator) (*Query, error) {
+// subSchema returns the schema of the sub-document val of the field def, if
+// any. The schema of polymorphic fields is resolved from val.
+func subSchema(def *schema.Field, val interface{}) *schema.Schema {
+	if def == nil {
+		return nil
+	}
+	if def.Schema != nil {
+		return def.Schema
+	}
+	switch v := def.Validator.(type) {
+	case *schema.Object:
+		return v.Schema
+	case schema.Object:
+		return v.Schema
+	case *schema.OneOfSchema:
+		if doc, ok := val.(map[string]interface{}); ok {
+			if s, err := v.Resolve(doc); err == nil {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+// connectionQuery builds a query from a projection field on a schema.Connection
+// type field. Up to defaultLimit items are embedded when the field has no
+// limit param.
+func connectionQuery(pf ProjectionField, field string, id interface{}, validator schema.Validator, defaultLimit int) (*Query, error) {
 	q := &Query{
 		Projection: pf.Children,
 		Predicate:  Predicate{&Equal{Field: field, Value: id}},
@@ -318,6 +422,7 @@ func connectionQuery(pf ProjectionField, field string, id interface{}, validator
 		}
 		q.Predicate = append(q.Predicate, p...)
 	}
+	q.Predicate = append(q.Predicate, pf.Filter...)
 	if sort, ok := pf.Params["sort"].(string); ok {
 		s, err := ParseSort(sort)
 		if err != nil {
@@ -333,14 +438,48 @@ func connectionQuery(pf ProjectionField, field string, id interface{}, validator
 	if v, ok := pf.Params["page"].(int); ok {
 		page = v
 	}
-	limit := 20
-	if v, ok := pf.Params["limit"].(int); ok {
-		limit = v
+	limit, hasLimit := pf.Params["limit"].(int)
+	if !hasLimit {
+		limit = defaultLimit
+	}
+	switch {
+	case pf.Slice == nil:
+		q.Window = Page(page, limit, skip)
+	case hasLimit:
+		// The slice selects items of the requested page.
+		q.Window = pf.Slice.window(Page(page, limit, skip), limit)
+	default:
+		// The slice selects items of the connection, up to the default
+		// limit when it has no end.
+		q.Window = pf.Slice.window(&Window{Offset: skip, Limit: -1}, limit)
 	}
-	q.Window = Page(page, limit, skip)
 	return q, nil
 }
 
+// filterItems returns the items matching the filter. Items which are not
+// objects never match.
+func filterItems(filter Predicate, items []interface{}) []interface{} {
+	if filter == nil {
+		return items
+	}
+	matched := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok && filter.Match(obj) {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
+
+// sliceItems returns the items selected by the slice.
+func sliceItems(slice *ProjectionSlice, items []interface{}) []interface{} {
+	if slice == nil {
+		return items
+	}
+	start, end := slice.bounds(len(items))
+	return items[start:end]
+}
+
 // resolveFieldHandler calls the field handler with the provided params (if any).
 func resolveFieldHandler(ctx context.Context, pf ProjectionField, def *schema.Field, val interface{}) (interface{}, error) {
 	if def == nil {
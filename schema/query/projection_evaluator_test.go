@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/entropyinf/rest-layer/internal/testutil"
@@ -33,6 +34,16 @@ func (r resource) Find(ctx context.Context, query *Query) ([]map[string]interfac
 			payloads = append(payloads, p)
 		}
 	}
+	if w := query.Window; w != nil {
+		offset := w.Offset
+		if offset > len(payloads) {
+			offset = len(payloads)
+		}
+		payloads = payloads[offset:]
+		if w.Limit >= 0 && w.Limit < len(payloads) {
+			payloads = payloads[:w.Limit]
+		}
+	}
 	return payloads, nil
 }
 func (r resource) MultiGet(ctx context.Context, ids []interface{}) ([]map[string]interface{}, error) {
@@ -60,7 +71,7 @@ func (r resource) Path() string {
 func TestProjectionEval(t *testing.T) {
 	cnxShema := schema.Schema{Fields: schema.Fields{
 		"id":   {},
-		"name": {},
+		"name": {Filterable: true},
 		"ref":  {},
 	}}
 
@@ -105,7 +116,7 @@ func TestProjectionEval(t *testing.T) {
 						Validator: &schema.Object{
 							Schema: &schema.Schema{
 								Fields: schema.Fields{
-									"child":  {},
+									"child":  {Filterable: true},
 									"child2": {},
 								},
 							},
@@ -534,6 +545,83 @@ func TestProjectionEval(t *testing.T) {
 			nil,
 			`{"dict":{"x":"2","y":{"name":"third"}}}`,
 		},
+		{
+			"Exclude",
+			`-simple`,
+			`{"parent":{"child":"value"},"simple":"value"}`,
+			nil,
+			`{"parent":{"child":"value"}}`,
+		},
+		{
+			"Exclude/Nested",
+			`parent{-child}`,
+			`{"parent":{"child":"value","child2":"value"},"simple":"value"}`,
+			nil,
+			`{"parent":{"child2":"value"}}`,
+		},
+		{
+			"Exclude/Aliased",
+			`*,-simple,s:simple`,
+			`{"parent":{"child":"value"},"simple":"value"}`,
+			nil,
+			`{"parent":{"child":"value"},"s":"value"}`,
+		},
+		{
+			"ArrayOfObject/Slice",
+			`arrayObject[1:]`,
+			`{"arrayObject":[{"child":"foo"},{"child":"foo2"},{"child":"foo3"}]}`,
+			nil,
+			`{"arrayObject":[{"child":"foo2"},{"child":"foo3"}]}`,
+		},
+		{
+			"ArrayOfObject/Slice-negative",
+			`arrayObject[-1:]{child}`,
+			`{"arrayObject":[{"child":"foo","child2":"bar"},{"child":"foo2","child2":"bar"}]}`,
+			nil,
+			`{"arrayObject":[{"child":"foo2"}]}`,
+		},
+		{
+			"ArrayOfObject/Filter",
+			`arrayObject[{child:{$in:["foo","foo3"]}}][:1]{child2}`,
+			`{"arrayObject":[{"child":"foo2","child2":"a"},{"child":"foo","child2":"b"},{"child":"foo3","child2":"c"}]}`,
+			nil,
+			`{"arrayObject":[{"child2":"b"}]}`,
+		},
+		{
+			"ArrayOfReference/Slice",
+			`arrayReference[0:1]`,
+			`{"arrayReference":["2","3"]}`,
+			nil,
+			`{"arrayReference":["2"]}`,
+		},
+		{
+			"ArrayOfReference/Filter",
+			`arrayReference[{name:{$ne:"first"}}][1:]{name}`,
+			`{"arrayReference":["1","2","3"]}`,
+			nil,
+			`{"arrayReference":[{"name":"third"}]}`,
+		},
+		{
+			"Connection/Filter",
+			`connection[{name:"forth"}]{id}`,
+			`{"id":"a"}`,
+			nil,
+			`{"connection":[{"id":"4"}]}`,
+		},
+		{
+			"Connection/Slice",
+			`connection[1:]{name}`,
+			`{"id":"a"}`,
+			nil,
+			`{"connection":[{"name":"forth"}]}`,
+		},
+		{
+			"Connection/Slice-end",
+			`connection[0:1]{name}`,
+			`{"id":"a"}`,
+			nil,
+			`{"connection":[{"name":"second"}]}`,
+		},
 	}
 
 	for i := range cases {
@@ -564,3 +652,208 @@ func TestProjectionEval(t *testing.T) {
 		})
 	}
 }
+
+func TestProjectionEvalReadPermission(t *testing.T) {
+	type roleKey struct{}
+	isAdmin := func(ctx context.Context, doc map[string]interface{}) bool {
+		return ctx.Value(roleKey{}) == "admin"
+	}
+	r := resource{
+		validator: schema.Schema{Fields: schema.Fields{
+			"id":     {},
+			"salary": {ReadPermission: isAdmin},
+			"parent": {
+				Schema: &schema.Schema{
+					Fields: schema.Fields{
+						"child":  {},
+						"secret": {ReadPermission: isAdmin},
+					},
+				},
+			},
+			"meta": {
+				Validator: &schema.Object{Schema: &schema.Schema{
+					Fields: schema.Fields{
+						"a":      {},
+						"secret": {ReadPermission: isAdmin},
+						"hidden": {Hidden: true},
+					},
+				}},
+			},
+		}},
+	}
+	payload := map[string]interface{}{
+		"id":     "1",
+		"salary": 10,
+		"parent": map[string]interface{}{"child": "a", "secret": "b"},
+		"meta":   map[string]interface{}{"a": 1, "secret": 2, "hidden": 3},
+	}
+	p := MustParseProjection("id,salary,parent{child,secret},meta")
+	if err := p.Validate(r.validator); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.Eval(context.Background(), payload, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"id": "1", "parent": map[string]interface{}{"child": "a"}, "meta": map[string]interface{}{"a": 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid output:\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	got, err = Projection{}.Eval(context.Background(), payload, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid output:\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	ctx := context.WithValue(context.Background(), roleKey{}, "admin")
+	got, err = Projection{}.Eval(ctx, payload, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]interface{}{
+		"id":     "1",
+		"salary": 10,
+		"parent": map[string]interface{}{"child": "a", "secret": "b"},
+		"meta":   map[string]interface{}{"a": 1, "secret": 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid output:\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestProjectionEvalComputed(t *testing.T) {
+	r := resource{
+		validator: schema.Schema{Fields: schema.Fields{
+			"id":   {},
+			"name": {},
+			"upper": {
+				Compute: func(ctx context.Context, doc map[string]interface{}) (interface{}, error) {
+					return strings.ToUpper(doc["name"].(string)), nil
+				},
+			},
+			"length": {
+				ComputeByDefault: true,
+				Compute: func(ctx context.Context, doc map[string]interface{}) (interface{}, error) {
+					return len(doc["name"].(string)), nil
+				},
+			},
+			"failing": {
+				Compute: func(ctx context.Context, doc map[string]interface{}) (interface{}, error) {
+					return nil, errors.New("failure")
+				},
+			},
+		}},
+	}
+	payload := map[string]interface{}{"id": "1", "name": "foo"}
+	cases := []struct {
+		projection string
+		want       map[string]interface{}
+		err        error
+	}{
+		{"", map[string]interface{}{"id": "1", "name": "foo", "length": 3}, nil},
+		{"*", map[string]interface{}{"id": "1", "name": "foo", "length": 3}, nil},
+		{"name,upper", map[string]interface{}{"name": "foo", "upper": "FOO"}, nil},
+		{"*,u:upper", map[string]interface{}{"id": "1", "name": "foo", "length": 3, "u": "FOO"}, nil},
+		{"failing", nil, errors.New("failing: failure")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.projection, func(t *testing.T) {
+			p := MustParseProjection(tc.projection)
+			got, err := p.Eval(context.Background(), payload, r)
+			if !reflect.DeepEqual(err, tc.err) {
+				t.Errorf("unexpected error:\ngot:  %v\nwant: %v", err, tc.err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("invalid output:\ngot:  %#v\nwant: %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProjectionEvalOneOfSchema(t *testing.T) {
+	type roleKey struct{}
+	isAdmin := func(ctx context.Context, doc map[string]interface{}) bool {
+		return ctx.Value(roleKey{}) == "admin"
+	}
+	r := resource{
+		validator: schema.Schema{Fields: schema.Fields{
+			"id": {},
+			"payment": {
+				Validator: &schema.OneOfSchema{
+					Discriminator: "type",
+					Mapping: map[string]*schema.Schema{
+						"card": {Fields: schema.Fields{"type": {}, "number": {ReadPermission: isAdmin}, "last4": {}}},
+						"bank": {Fields: schema.Fields{"type": {}, "iban": {}}},
+					},
+				},
+			},
+		}},
+	}
+	payload := map[string]interface{}{"id": "1", "payment": map[string]interface{}{"type": "card", "number": "4242424242424242", "last4": "4242"}}
+	cases := []struct {
+		projection string
+		want       map[string]interface{}
+	}{
+		{"", map[string]interface{}{"id": "1", "payment": map[string]interface{}{"type": "card", "last4": "4242"}}},
+		{"payment{type,last4}", map[string]interface{}{"payment": map[string]interface{}{"type": "card", "last4": "4242"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.projection, func(t *testing.T) {
+			p := MustParseProjection(tc.projection)
+			if err := p.Validate(r.validator); err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Eval(context.Background(), payload, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("invalid output:\ngot:  %#v\nwant: %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConnectionQuerySlice(t *testing.T) {
+	end := func(i int) *int { return &i }
+	cases := []struct {
+		name   string
+		pf     ProjectionField
+		window *Window
+	}{
+		{"NoSlice", ProjectionField{}, &Window{Offset: 0, Limit: 20}},
+		{"Start", ProjectionField{Slice: &ProjectionSlice{Start: 30}}, &Window{Offset: 30, Limit: 20}},
+		{"Range", ProjectionField{Slice: &ProjectionSlice{Start: 30, End: end(35)}}, &Window{Offset: 30, Limit: 5}},
+		{"Empty", ProjectionField{Slice: &ProjectionSlice{Start: 5, End: end(3)}}, &Window{Offset: 5, Limit: 0}},
+		{"Skip", ProjectionField{Params: map[string]interface{}{"skip": 2}, Slice: &ProjectionSlice{Start: 1}}, &Window{Offset: 3, Limit: 20}},
+		{"Page", ProjectionField{Params: map[string]interface{}{"page": 2, "limit": 10}, Slice: &ProjectionSlice{Start: 2, End: end(5)}}, &Window{Offset: 12, Limit: 3}},
+		{"PageEnd", ProjectionField{Params: map[string]interface{}{"limit": 10}, Slice: &ProjectionSlice{Start: 8}}, &Window{Offset: 8, Limit: 2}},
+		{"PageOut", ProjectionField{Params: map[string]interface{}{"limit": 10}, Slice: &ProjectionSlice{Start: 11}}, &Window{Offset: 11, Limit: 0}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := connectionQuery(tc.pf, "ref", "a", schema.Schema{}, 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(q.Window, tc.window) {
+				t.Errorf("Window = %#v, wanted %#v", q.Window, tc.window)
+			}
+		})
+	}
+}
+
+func TestConnectionQueryDefaultLimit(t *testing.T) {
+	l := Limits{MaxLimit: 5}
+	q, err := connectionQuery(ProjectionField{}, "ref", "a", schema.Schema{}, l.ConnectionLimit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&Window{Offset: 0, Limit: 5}); !reflect.DeepEqual(q.Window, want) {
+		t.Errorf("Window = %#v, wanted %#v", q.Window, want)
+	}
+}
@@ -56,6 +56,23 @@ With this example, the resulted document would be:
     "thumbnail_small_url": "the url with size 80",
     "thumbnail_large_url": "the url with size 500",
   }
+Fields can be excluded by prefixing their name with a minus (-). A projection
+made of excluded fields only selects all the other fields. Sub-fields are
+excluded by listing them as the children of their parent field, an excluded
+field having no children:
+
+-password_hint,internal{-secret}
+
+A range of the items of an array, a list of references or a sub-resource can
+be selected by appending a slice, with the first index included and the last
+excluded. Negative indexes are relative to the end of the list:
+
+comments[0:5],tags[-3:]
+
+The items of those lists can also be filtered by appending a predicate
+between brackets. The filter is applied before the slice:
+
+comments[{score: {$gt: 3}}][0:5]{text}
 
 */
 func ParseProjection(projection string) (Projection, error) {
@@ -83,15 +100,23 @@ func (p *projectionParser) parseExpression(opened bool) (Projection, error) {
 	for p.more() {
 		if field == nil {
 			p.eatWhitespaces()
+			exclude := p.expect('-')
 			name, alias := p.scanFieldNameWithAlias()
 			if name == "" {
 				return nil, fmt.Errorf("looking for field name at char %d", p.pos)
 			}
-			field = &ProjectionField{Name: name, Alias: alias}
+			if exclude && alias != "" {
+				return nil, fmt.Errorf("excluded field can't have an alias at char %d", p.pos)
+			}
+			field = &ProjectionField{Name: name, Alias: alias, Exclude: exclude}
 			expectField = false
 			continue
 		}
-		switch p.peek() {
+		c := p.peek()
+		if field.Exclude && (c == '{' || c == '(' || c == '[') {
+			return nil, fmt.Errorf("unexpected `%c' after excluded field at char %d", c, p.pos)
+		}
+		switch c {
 		case '{':
 			p.pos++
 			children, err := p.parseExpression(true)
@@ -112,6 +137,11 @@ func (p *projectionParser) parseExpression(opened bool) (Projection, error) {
 				return nil, err
 			}
 			field.Params = params
+		case '[':
+			p.pos++
+			if err := p.scanFieldSelector(field); err != nil {
+				return nil, err
+			}
 		case ',':
 			projection = append(projection, *field)
 			field = nil
@@ -172,6 +202,73 @@ func (p *projectionParser) scanFieldParams() (map[string]interface{}, error) {
 	return params, nil
 }
 
+// p.scanFieldSelector parses a filter or a slice until it finds a closing
+// bracket, and sets it on field. If a syntax error is found or field already
+// has a filter or a slice, an error is returned.
+func (p *projectionParser) scanFieldSelector(field *ProjectionField) error {
+	p.eatWhitespaces()
+	if p.peek() == '{' {
+		if field.Filter != nil || field.Slice != nil {
+			return fmt.Errorf("unexpected filter at char %d", p.pos)
+		}
+		pp := &predicateParser{query: p.exp, pos: p.pos}
+		filter, err := pp.parseExpressions()
+		if err != nil {
+			return fmt.Errorf("invalid filter at char %d: %v", pp.pos, err)
+		}
+		p.pos = pp.pos
+		field.Filter = filter
+	} else {
+		if field.Slice != nil {
+			return fmt.Errorf("unexpected slice at char %d", p.pos)
+		}
+		slice := &ProjectionSlice{}
+		if start, found, err := p.scanIndex(); err != nil {
+			return err
+		} else if found {
+			slice.Start = start
+		}
+		p.eatWhitespaces()
+		if !p.expect(':') {
+			return fmt.Errorf("looking for `:' at char %d", p.pos)
+		}
+		if end, found, err := p.scanIndex(); err != nil {
+			return err
+		} else if found {
+			slice.End = &end
+		}
+		field.Slice = slice
+	}
+	p.eatWhitespaces()
+	if p.peek() != ']' {
+		return fmt.Errorf("looking for `]' at char %d", p.pos)
+	}
+	return nil
+}
+
+// p.scanIndex captures an optional integer at current position and advance
+// the cursor position "pos" at the next character following the integer.
+func (p *projectionParser) scanIndex() (index int, found bool, err error) {
+	p.eatWhitespaces()
+	start := p.pos
+	end := start
+	if p.peekAt(end) == '-' {
+		end++
+	}
+	for c := p.peekAt(end); c >= '0' && c <= '9'; c = p.peekAt(end) {
+		end++
+	}
+	if end == start {
+		return 0, false, nil
+	}
+	index, err = strconv.Atoi(p.exp[start:end])
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid index at char %d", p.pos)
+	}
+	p.pos = end
+	return index, true, nil
+}
+
 // p.scanFieldName captures a field name at current position and advance
 // the cursor position "pos" at the next character following the field name.
 func (p *projectionParser) scanFieldName() string {
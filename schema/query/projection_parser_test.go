@@ -196,11 +196,75 @@ func TestParseProjection(t *testing.T) {
 			errors.New("looking for field name at char 4"),
 			Projection{},
 		},
+		{
+			`-foo,bar{-baz}`,
+			nil,
+			Projection{
+				{Name: "foo", Exclude: true},
+				{Name: "bar", Children: Projection{{Name: "baz", Exclude: true}}},
+			},
+		},
+		{
+			`foo[1:3],bar[-2:],baz[0:-1]{a}`,
+			nil,
+			Projection{
+				{Name: "foo", Slice: &ProjectionSlice{Start: 1, End: intPtr(3)}},
+				{Name: "bar", Slice: &ProjectionSlice{Start: -2}},
+				{Name: "baz", Slice: &ProjectionSlice{End: intPtr(-1)}, Children: Projection{{Name: "a"}}},
+			},
+		},
+		{
+			`f:foo[{a: {$gt: 1}}][0:2](p:1){b}`,
+			nil,
+			Projection{{
+				Name:     "foo",
+				Alias:    "f",
+				Filter:   Predicate{&GreaterThan{Field: "a", Value: float64(1)}},
+				Slice:    &ProjectionSlice{Start: 0, End: intPtr(2)},
+				Params:   map[string]interface{}{"p": float64(1)},
+				Children: Projection{{Name: "b"}},
+			}},
+		},
 		{
 			`foo{bar,}`,
 			errors.New("looking for field name at char 8"),
 			Projection{},
 		},
+		{
+			`-foo,-bar{baz}`,
+			errors.New("unexpected `{' after excluded field at char 9"),
+			Projection{},
+		},
+		{
+			`-foo:bar`,
+			errors.New("excluded field can't have an alias at char 8"),
+			Projection{},
+		},
+		{
+			`foo[1]`,
+			errors.New("looking for `:' at char 5"),
+			Projection{},
+		},
+		{
+			`foo[0:1`,
+			errors.New("looking for `]' at char 7"),
+			Projection{},
+		},
+		{
+			`foo[0:1][2:3]`,
+			errors.New("unexpected slice at char 9"),
+			Projection{},
+		},
+		{
+			`foo[0:1][{a:1}]`,
+			errors.New("unexpected filter at char 9"),
+			Projection{},
+		},
+		{
+			`foo[{a:}]`,
+			errors.New("invalid filter at char 7: a: unexpected char '}'"),
+			Projection{},
+		},
 		// Fuzz crashers
 		{
 			"0(0:",
@@ -263,9 +327,13 @@ func TestParseProjection(t *testing.T) {
 				t.Errorf("Projection:\ngot:  %#v\nwant: %#v", pr, tc.want)
 			}
 
-			if got, want := pr.String(), normalize(tc.projection); got != want {
+			if got, want := normalize(pr.String()), normalize(tc.projection); got != want {
 				t.Errorf("Projection.String:\ngot:  %s\nwant: %s", got, want)
 			}
 		})
 	}
 }
+
+func intPtr(i int) *int {
+	return &i
+}
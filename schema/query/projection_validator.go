@@ -12,6 +12,9 @@ func (pf ProjectionField) Validate(fg schema.FieldGetter) error {
 		if pf.Alias != "" {
 			return fmt.Errorf("%s: can't have an alias", pf.Name)
 		}
+		if pf.Exclude {
+			return fmt.Errorf("%s: can't be excluded", pf.Name)
+		}
 		return nil
 	}
 
@@ -19,6 +22,10 @@ func (pf ProjectionField) Validate(fg schema.FieldGetter) error {
 	if def == nil {
 		return fmt.Errorf("%s: unknown field", pf.Name)
 	}
+	if pf.Exclude {
+		// Hidden fields are never selected, excluding them is harmless.
+		return nil
+	}
 	if def.Hidden {
 		// Hidden fields can't be selected
 		return fmt.Errorf("%s: hidden field", pf.Name)
@@ -39,6 +46,11 @@ func (pf ProjectionField) Validate(fg schema.FieldGetter) error {
 			if err := pf.Children.Validate(conn.Validator); err != nil {
 				return fmt.Errorf("%s.%v", pf.Name, err)
 			}
+		} else if oneOf, ok := def.Validator.(*schema.OneOfSchema); ok {
+			// Sub-field on a polymorphic object
+			if err := pf.Children.Validate(oneOf); err != nil {
+				return fmt.Errorf("%s.%v", pf.Name, err)
+			}
 		} else if _, ok := def.Validator.(*schema.Dict); ok {
 			// Sub-field on a dict resource
 		} else if array, ok := def.Validator.(*schema.Array); ok {
@@ -51,6 +63,25 @@ func (pf ProjectionField) Validate(fg schema.FieldGetter) error {
 			return fmt.Errorf("%s: field has no children", pf.Name)
 		}
 	}
+	if pf.Filter != nil || pf.Slice != nil {
+		isList, validator := listValidator(def)
+		if !isList {
+			return fmt.Errorf("%s: field is not a list", pf.Name)
+		}
+		if _, ok := def.Validator.(*schema.Connection); ok && pf.Slice != nil && pf.Slice.negative() {
+			// Connection slices are fetched as a window of the sub-resource,
+			// whose size is unknown.
+			return fmt.Errorf("%s: negative slice indexes are not supported on connections", pf.Name)
+		}
+		if pf.Filter != nil {
+			if validator == nil {
+				return fmt.Errorf("%s: filter requires a list of objects", pf.Name)
+			}
+			if err := pf.Filter.Prepare(validator); err != nil {
+				return fmt.Errorf("%s: invalid filter: %v", pf.Name, err)
+			}
+		}
+	}
 	if len(pf.Params) > 0 {
 		if len(def.Params) == 0 {
 			return fmt.Errorf("%s: params not allowed", pf.Name)
@@ -72,3 +103,21 @@ func (pf ProjectionField) Validate(fg schema.FieldGetter) error {
 	}
 	return nil
 }
+
+// listValidator tells if the field def holds a list of items which can be
+// sliced, and returns the validator of the items if they can be filtered.
+func listValidator(def *schema.Field) (isList bool, validator schema.Validator) {
+	switch v := def.Validator.(type) {
+	case *schema.Connection:
+		return true, v.Validator
+	case *schema.Array:
+		switch values := v.Values.Validator.(type) {
+		case *schema.Object:
+			return true, values.Schema
+		case *schema.Reference:
+			return true, values.SchemaValidator
+		}
+		return true, nil
+	}
+	return false, nil
+}
@@ -16,12 +16,17 @@ func TestProjectionValidate(t *testing.T) {
 					Values: schema.Field{
 						Validator: &schema.Object{
 							Schema: &schema.Schema{
-								Fields: schema.Fields{"child": {}},
+								Fields: schema.Fields{"child": {Filterable: true}},
 							},
 						},
 					},
 				},
 			},
+			"tags": {
+				Validator: &schema.Array{
+					Values: schema.Field{Validator: &schema.String{}},
+				},
+			},
 			"parent": {
 				Schema: &schema.Schema{
 					Fields: schema.Fields{"child": {}},
@@ -47,7 +52,7 @@ func TestProjectionValidate(t *testing.T) {
 					Field: "ref",
 					Validator: schema.Schema{Fields: schema.Fields{
 						"id":   {},
-						"name": {},
+						"name": {Filterable: true},
 					}},
 				},
 			},
@@ -86,6 +91,21 @@ func TestProjectionValidate(t *testing.T) {
 		{`connection{name}`, nil},
 		{`connection{*}`, nil},
 		{`connection{foo}`, errors.New("connection.foo: unknown field")},
+		{`-simple,-parent`, nil},
+		{`*,-simple`, nil},
+		{`-foo`, errors.New("foo: unknown field")},
+		{`-*`, errors.New("*: can't be excluded")},
+		{`parent{-child}`, nil},
+		{`array[0:2]`, nil},
+		{`array[{child:"foo"}][1:]{child}`, nil},
+		{`array[{foo:"bar"}]`, errors.New("array: invalid filter: foo: unknown query field")},
+		{`array[{child:{$gt:1}}]`, errors.New("array: invalid filter: child: not-comparable")},
+		{`connection[{name:"foo"}][1:]`, nil},
+		{`connection[-1:]`, errors.New("connection: negative slice indexes are not supported on connections")},
+		{`simple[0:1]`, errors.New("simple: field is not a list")},
+		{`parent[{child:"foo"}]`, errors.New("parent: field is not a list")},
+		{`tags[{child:"foo"}]`, errors.New("tags: filter requires a list of objects")},
+		{`tags[-1:]`, nil},
 	}
 	for i := range cases {
 		tc := cases[i]
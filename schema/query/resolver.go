@@ -14,6 +14,9 @@ type referenceBatchResolver struct {
 	mu       sync.Mutex
 	requests []referenceRequest
 	rsc      Resource
+	// connectionLimit is the number of items embedded by connections with no
+	// limit param.
+	connectionLimit int
 }
 
 func (rbr *referenceBatchResolver) request(rsc Resource, q *Query, handler referenceResponseHandler) {
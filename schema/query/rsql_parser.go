@@ -0,0 +1,339 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/entropyinf/rest-layer/schema"
+)
+
+type rsqlParser struct {
+	query     string
+	pos       int
+	validator schema.Validator
+}
+
+// ParseRSQL parses a predicate expressed using the RSQL/FIQL syntax. Arguments
+// are converted to the type of the field they are compared to using the
+// provided validator, so the predicate still needs to be prepared. When
+// validator is nil, arguments are kept as strings.
+//
+// Constraints are joined with a semicolon (;) for a logical AND or with a
+// coma (,) for a logical OR, AND having precedence. Parenthesis can be used to
+// group constraints. A constraint is made of a field, an operator and an
+// argument:
+//
+//   ==         equal (use * as a wildcard in an unquoted argument)
+//   !=         not equal (use * as a wildcard in an unquoted argument)
+//   =lt= or <  lower than
+//   =le= or <= lower than or equal
+//   =gt= or >  greater than
+//   =ge= or >= greater than or equal
+//   =in=       in a list of arguments: (a,b,c)
+//   =out=      not in a list of arguments: (a,b,c)
+//   =exists=   field exists (true) or not (false)
+//
+// Arguments containing reserved characters must be quoted using single or
+// double quotes.
+//
+// Example:
+//
+//   age=ge=18;(name==bob*,role=in=(admin,owner))
+func ParseRSQL(rsql string, validator schema.Validator) (Predicate, error) {
+	if strings.TrimSpace(rsql) == "" {
+		return Predicate{}, nil
+	}
+	p := &rsqlParser{query: rsql, validator: validator}
+	exps, err := p.parseOr()
+	if err == nil && p.more() {
+		err = fmt.Errorf("unexpected %q", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("char %d: %v", p.pos, err)
+	}
+	if and, ok := exps.(*And); ok {
+		return Predicate(*and), nil
+	}
+	return Predicate{exps}, nil
+}
+
+// parseOr parses constraints joined with comas.
+func (p *rsqlParser) parseOr() (Expression, error) {
+	or := Or{}
+	for {
+		exp, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, exp)
+		if !p.expect(',') {
+			break
+		}
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return &or, nil
+}
+
+// parseAnd parses constraints joined with semicolons.
+func (p *rsqlParser) parseAnd() (Expression, error) {
+	and := And{}
+	for {
+		exp, err := p.parseConstraint()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, exp)
+		if !p.expect(';') {
+			break
+		}
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return &and, nil
+}
+
+// parseConstraint parses a comparison or a group of constraints enclosed in
+// parenthesis.
+func (p *rsqlParser) parseConstraint() (Expression, error) {
+	p.eatWhitespaces()
+	if p.expect('(') {
+		exp, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.eatWhitespaces()
+		if !p.expect(')') {
+			return nil, fmt.Errorf("expected ')' got %q", p.peek())
+		}
+		p.eatWhitespaces()
+		return exp, nil
+	}
+	field := p.parseSelector()
+	if field == "" {
+		return nil, fmt.Errorf("expected a field got %q", p.peek())
+	}
+	p.eatWhitespaces()
+	op, err := p.parseOperator()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", field, err)
+	}
+	p.eatWhitespaces()
+	exp, err := p.parseComparison(field, op)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %v", field, op, err)
+	}
+	p.eatWhitespaces()
+	return exp, nil
+}
+
+// parseComparison parses the argument of a comparison and returns the
+// corresponding expression.
+func (p *rsqlParser) parseComparison(field, op string) (Expression, error) {
+	switch op {
+	case "=in=", "=out=":
+		args, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]Value, 0, len(args))
+		for _, arg := range args {
+			v, err := typedValue(field, arg, p.validator)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		if op == "=in=" {
+			return &In{Field: field, Values: values}, nil
+		}
+		return &NotIn{Field: field, Values: values}, nil
+	case "=exists=":
+		arg, _, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		switch arg {
+		case "true":
+			return &Exist{Field: field}, nil
+		case "false":
+			return &NotExist{Field: field}, nil
+		}
+		return nil, errors.New("expected true or false")
+	}
+	arg, quoted, err := p.parseArgument()
+	if err != nil {
+		return nil, err
+	}
+	if (op == "==" || op == "!=") && !quoted && strings.Contains(arg, "*") {
+		// Wildcards are translated to an anchored regular expression.
+		parts := strings.Split(arg, "*")
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		re := regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+		return &Regex{Field: field, Value: re, Negated: op == "!="}, nil
+	}
+	value, err := typedValue(field, arg, p.validator)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "==":
+		return &Equal{Field: field, Value: value}, nil
+	case "!=":
+		return &NotEqual{Field: field, Value: value}, nil
+	case "=lt=", "<":
+		return &LowerThan{Field: field, Value: value}, nil
+	case "=le=", "<=":
+		return &LowerOrEqual{Field: field, Value: value}, nil
+	case "=gt=", ">":
+		return &GreaterThan{Field: field, Value: value}, nil
+	case "=ge=", ">=":
+		return &GreaterOrEqual{Field: field, Value: value}, nil
+	}
+	return nil, errors.New("unknown operator")
+}
+
+// parseSelector parses a field name.
+func (p *rsqlParser) parseSelector() string {
+	start := p.pos
+	for p.more() && !isRSQLReserved(p.peek()) {
+		p.pos++
+	}
+	return p.query[start:p.pos]
+}
+
+// parseOperator parses a comparison operator.
+func (p *rsqlParser) parseOperator() (string, error) {
+	start := p.pos
+	switch {
+	case strings.HasPrefix(p.query[p.pos:], "=="),
+		strings.HasPrefix(p.query[p.pos:], "!="),
+		strings.HasPrefix(p.query[p.pos:], "<="),
+		strings.HasPrefix(p.query[p.pos:], ">="):
+		p.pos += 2
+	case p.peek() == '<' || p.peek() == '>':
+		p.pos++
+	case p.peek() == '=':
+		p.pos++
+		for c := p.peek(); c >= 'a' && c <= 'z'; c = p.peek() {
+			p.pos++
+		}
+		if !p.expect('=') || p.pos-start == 2 {
+			p.pos = start
+			return "", errors.New("invalid operator")
+		}
+	default:
+		return "", fmt.Errorf("expected an operator got %q", p.peek())
+	}
+	op := p.query[start:p.pos]
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=",
+		"=lt=", "=le=", "=gt=", "=ge=", "=in=", "=out=", "=exists=":
+		return op, nil
+	}
+	p.pos = start
+	return "", fmt.Errorf("unknown operator %q", op)
+}
+
+// parseArguments parses a list of arguments enclosed in parenthesis. A single
+// argument is accepted without parenthesis.
+func (p *rsqlParser) parseArguments() ([]string, error) {
+	if !p.expect('(') {
+		arg, _, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		return []string{arg}, nil
+	}
+	args := []string{}
+	for {
+		p.eatWhitespaces()
+		arg, _, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		p.eatWhitespaces()
+		if p.expect(')') {
+			return args, nil
+		}
+		if !p.expect(',') {
+			return nil, fmt.Errorf("expected ',' or ')' got %q", p.peek())
+		}
+	}
+}
+
+// parseArgument parses a quoted or unquoted argument.
+func (p *rsqlParser) parseArgument() (arg string, quoted bool, err error) {
+	if c := p.peek(); c == '"' || c == '\'' {
+		p.pos++
+		buf := strings.Builder{}
+		for p.more() {
+			b := p.query[p.pos]
+			p.pos++
+			switch {
+			case b == '\\' && p.more():
+				buf.WriteByte(p.query[p.pos])
+				p.pos++
+			case b == c:
+				return buf.String(), true, nil
+			default:
+				buf.WriteByte(b)
+			}
+		}
+		return "", true, errors.New("unterminated quoted argument")
+	}
+	start := p.pos
+	for p.more() && !isRSQLReserved(p.peek()) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", false, fmt.Errorf("expected an argument got %q", p.peek())
+	}
+	return p.query[start:p.pos], false, nil
+}
+
+// isRSQLReserved tells if c can't be used unquoted in a field or an argument.
+func isRSQLReserved(c byte) bool {
+	switch c {
+	case '"', '\'', '(', ')', ';', ',', '=', '!', '<', '>', ' ', '\t', '\r', '\n':
+		return true
+	}
+	return false
+}
+
+func (p *rsqlParser) more() bool {
+	return p.pos < len(p.query)
+}
+
+func (p *rsqlParser) peek() byte {
+	if p.more() {
+		return p.query[p.pos]
+	}
+	return 0
+}
+
+func (p *rsqlParser) expect(c byte) bool {
+	if p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *rsqlParser) eatWhitespaces() {
+	for p.more() {
+		switch p.query[p.pos] {
+		case ' ', '\n', '\r', '\t':
+			p.pos++
+			continue
+		}
+		break
+	}
+}
@@ -0,0 +1,68 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRSQL(t *testing.T) {
+	s := schema.Schema{Fields: schema.Fields{
+		"name":   {Filterable: true, Validator: &schema.String{}},
+		"age":    {Filterable: true, Validator: &schema.Integer{}},
+		"active": {Filterable: true, Validator: &schema.Bool{}},
+	}}
+	tests := []struct {
+		rsql string
+		want string
+		err  error
+	}{
+		{``, `{}`, nil},
+		{`name==bob`, `{name: "bob"}`, nil},
+		{`age=ge=18;age<30`, `{age: {$gte: 18}, age: {$lt: 30}}`, nil},
+		{`age=gt=18;age=le=30;age>=1;age<=99;age=lt=50;age>2`, `{age: {$gt: 18}, age: {$lte: 30}, age: {$gte: 1}, age: {$lte: 99}, age: {$lt: 50}, age: {$gt: 2}}`, nil},
+		{`name==bob,name==alice`, `{$or: [{name: "bob"}, {name: "alice"}]}`, nil},
+		{`age>18;name==bob,name==alice`, `{$or: [{$and: [{age: {$gt: 18}}, {name: "bob"}]}, {name: "alice"}]}`, nil},
+		{`age>18;(name==bob,name==alice)`, `{age: {$gt: 18}, $or: [{name: "bob"}, {name: "alice"}]}`, nil},
+		{`name!=bob;active==true`, `{name: {$ne: "bob"}, active: true}`, nil},
+		{`name==bob*`, `{name: {$regex: "^bob.*$"}}`, nil},
+		{`name!=*.b`, `{name: {$not: "^.*\\.b$"}}`, nil},
+		{`name=="bob*"`, `{name: "bob*"}`, nil},
+		{`name=='a \'b\''`, `{name: "a 'b'"}`, nil},
+		{`name=in=(bob, "alice")`, `{name: {$in: ["bob", "alice"]}}`, nil},
+		{`age=out=(1,2)`, `{age: {$nin: [1, 2]}}`, nil},
+		{`age=in=1`, `{age: {$in: [1]}}`, nil},
+		{`name=exists=true;age=exists=false`, `{name: {$exists: true}, age: {$exists: false}}`, nil},
+		{` name == bob ; age > 1 `, `{name: "bob", age: {$gt: 1}}`, nil},
+		{`name`, ``, errors.New("char 4: name: expected an operator got '\\x00'")},
+		{`==bob`, ``, errors.New("char 0: expected a field got '='")},
+		{`name=foo=bob`, ``, errors.New("char 4: name: unknown operator \"=foo=\"")},
+		{`name==`, ``, errors.New("char 6: name: ==: expected an argument got '\\x00'")},
+		{`name=="bob`, ``, errors.New("char 10: name: ==: unterminated quoted argument")},
+		{`(name==bob`, ``, errors.New("char 10: expected ')' got '\\x00'")},
+		{`name==bob)`, ``, errors.New("char 9: unexpected ')'")},
+		{`age==old`, ``, errors.New("char 8: age: ==: invalid value \"old\": not a number")},
+		{`active==yes`, ``, errors.New("char 11: active: ==: invalid value \"yes\": not a boolean")},
+		{`age=in=(1,2`, ``, errors.New("char 11: age: =in=: expected ',' or ')' got '\\x00'")},
+		{`age=exists=maybe`, ``, errors.New("char 16: age: =exists=: expected true or false")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rsql, func(t *testing.T) {
+			got, err := ParseRSQL(tt.rsql, s)
+			assert.Equal(t, tt.err, err)
+			if err == nil {
+				assert.Equal(t, tt.want, got.String())
+				assert.NoError(t, got.Prepare(s))
+			}
+		})
+	}
+}
+
+func TestParseRSQLNoValidator(t *testing.T) {
+	got, err := ParseRSQL(`age==18`, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, Predicate{&Equal{Field: "age", Value: "18"}}, got)
+	}
+}
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+)
+
+// ScoreField is the pseudo field used to sort the results of a $search by
+// relevance, the most relevant first.
+const ScoreField = "_score"
+
+// Tokenize splits a text into case folded words for full-text search. Words
+// are sequences of letters and digits.
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return nil
+	}
+	fold := cases.Fold()
+	for i, w := range words {
+		words[i] = fold.String(w)
+	}
+	return words
+}
+
+// SearchTerm is a word of a full-text search query.
+type SearchTerm struct {
+	Word string
+
+	// Prefix matches all the words starting with Word.
+	Prefix bool
+}
+
+// Match tells if the tokenized word matches the term.
+func (t SearchTerm) Match(word string) bool {
+	if t.Prefix {
+		return strings.HasPrefix(word, t.Word)
+	}
+	return word == t.Word
+}
+
+// SearchTerms returns the terms of a full-text search query. The query is
+// tokenized like the searched text, and a word directly followed by a star
+// (i.e.: foo*) matches all the words starting with it.
+func SearchTerms(query string) []SearchTerm {
+	var terms []SearchTerm
+	for _, field := range strings.Fields(query) {
+		words := Tokenize(field)
+		for i, w := range words {
+			terms = append(terms, SearchTerm{
+				Word:   w,
+				Prefix: i == len(words)-1 && strings.HasSuffix(field, "*"),
+			})
+		}
+	}
+	return terms
+}
+
+// matchTerms tells if all the terms match one of the words.
+func matchTerms(terms []SearchTerm, words []string) bool {
+	for _, t := range terms {
+		found := false
+		for _, w := range words {
+			if t.Match(w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
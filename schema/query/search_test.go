@@ -0,0 +1,36 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := map[string][]string{
+		"":                         nil,
+		" ,;! ":                    nil,
+		"The quick-brown FOX, 42!": {"the", "quick", "brown", "fox", "42"},
+		"Straße ÉCOLE":             {"strasse", "école"},
+		"l'été\tà\nParis":          {"l", "été", "à", "paris"},
+	}
+	for text, want := range tests {
+		if got := Tokenize(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("Tokenize(%q) = %q, wanted %q", text, got, want)
+		}
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := map[string][]SearchTerm{
+		"":            nil,
+		"*":           nil,
+		"Quick br*":   {{Word: "quick"}, {Word: "br", Prefix: true}},
+		"e-mail* fox": {{Word: "e"}, {Word: "mail", Prefix: true}, {Word: "fox"}},
+		"fo*o":        {{Word: "fo"}, {Word: "o"}},
+	}
+	for query, want := range tests {
+		if got := SearchTerms(query); !reflect.DeepEqual(got, want) {
+			t.Errorf("SearchTerms(%q) = %v, wanted %v", query, got, want)
+		}
+	}
+}
@@ -1,13 +1,21 @@
 package query
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/entropyinf/rest-layer/schema"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 )
 
+// CollationCaseInsensitive is the collation comparing strings without regard
+// to case.
+const CollationCaseInsensitive = "ci"
+
 // Sort TODO
 type Sort []SortField
 
@@ -18,6 +26,28 @@ type SortField struct {
 
 	// Reversed instruct to reverse the sorting if set to true.
 	Reversed bool
+
+	// Collation defines how string values are compared. It is either
+	// CollationCaseInsensitive or a BCP 47 language tag for a language
+	// specific order. When empty, strings are compared byte-wise.
+	Collation string
+
+	// Near sorts geo points by their distance to the given point, closest
+	// first, when set.
+	Near *GeoCoordinates
+}
+
+// Collator returns a new collator comparing strings according to the sort
+// field collation, or nil if the field has no collation. As a collator is not
+// safe for concurrent use, a new one is created on each call.
+func (sf SortField) Collator() *collate.Collator {
+	switch sf.Collation {
+	case "":
+		return nil
+	case CollationCaseInsensitive:
+		return collate.New(language.Und, collate.IgnoreCase)
+	}
+	return collate.New(language.Make(sf.Collation))
 }
 
 // MustParseSort parses a sort expression and panics in case of error.
@@ -31,12 +61,16 @@ func MustParseSort(sort string) Sort {
 
 // ParseSort parses a sort expression. A sort expression is a list of fields
 // separated by comas. A field sort is reverse if preceded by a minus sign (-).
+// A collation may be given after the field name, separated by a colon (i.e.:
+// name:ci for a case insensitive sort or name:fr for a french sort). Geo
+// points are sorted by distance to a point given as near(longitude,latitude)
+// after the colon (i.e.: location:near(2.35,48.85)).
 func ParseSort(sort string) (Sort, error) {
 	s := Sort{}
 	if strings.Trim(sort, " ") == "" {
 		return s, nil
 	}
-	for _, f := range strings.Split(sort, ",") {
+	for _, f := range splitSort(sort) {
 		sf := SortField{Name: strings.Trim(f, " ")}
 		if sf.Name == "" || sf.Name == "-" {
 			return nil, errors.New("empty sort field")
@@ -47,14 +81,100 @@ func ParseSort(sort string) (Sort, error) {
 			sf.Name = sf.Name[1:]
 			sf.Reversed = true
 		}
+		if i := strings.IndexByte(sf.Name, ':'); i != -1 {
+			sf.Name, sf.Collation = sf.Name[:i], sf.Name[i+1:]
+			if sf.Name == "" {
+				return nil, errors.New("empty sort field")
+			}
+			if strings.HasPrefix(sf.Collation, "near(") {
+				near, err := parseSortNear(sf.Collation)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", sf.Name, err)
+				}
+				sf.Collation, sf.Near = "", near
+			} else if sf.Collation != CollationCaseInsensitive {
+				tag, err := language.Parse(sf.Collation)
+				if err != nil {
+					return nil, fmt.Errorf("%s: invalid collation %q", sf.Name, sf.Collation)
+				}
+				sf.Collation = tag.String()
+			}
+		}
 		s = append(s, sf)
 	}
 	return s, nil
 }
 
+// splitSort splits a sort expression on the comas which are not enclosed in
+// parenthesis.
+func splitSort(sort string) []string {
+	fields := []string{}
+	depth, start := 0, 0
+	for i, c := range sort {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				fields = append(fields, sort[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, sort[start:])
+}
+
+// parseSortNear parses the near(longitude,latitude) point of a distance sort.
+func parseSortNear(near string) (*GeoCoordinates, error) {
+	if !strings.HasSuffix(near, ")") {
+		return nil, fmt.Errorf("invalid near %q", near)
+	}
+	coords := strings.Split(near[len("near("):len(near)-1], ",")
+	if len(coords) != 2 {
+		return nil, fmt.Errorf("invalid near %q: expected near(longitude,latitude)", near)
+	}
+	values := make([]interface{}, 2)
+	for i, c := range coords {
+		f, err := strconv.ParseFloat(strings.TrimSpace(c), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid near %q: not a number", near)
+		}
+		values[i] = f
+	}
+	c, ok := geoCoordinates(values)
+	if !ok {
+		return nil, fmt.Errorf("invalid near %q: invalid geo point", near)
+	}
+	return &c, nil
+}
+
+// CheckReadPermission returns an error if the caller identified by ctx may not
+// read one of the sort fields, or one of their parents, as the order of the
+// items would disclose their values.
+func (s Sort) CheckReadPermission(ctx context.Context, validator schema.Validator) error {
+	for _, sf := range s {
+		if sf.Name == ScoreField && validator.GetField(sf.Name) == nil {
+			continue
+		}
+		if err := checkFieldReadPermission(ctx, sf.Name, validator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Validate validates the sort against the provided validator.
 func (s Sort) Validate(validator schema.Validator) error {
 	for _, sf := range s {
+		if sf.Name == ScoreField && validator.GetField(sf.Name) == nil {
+			// Relevance of a full-text search.
+			if sf.Collation != "" || sf.Near != nil {
+				return fmt.Errorf("%s: can't have options", sf.Name)
+			}
+			continue
+		}
 		// Make sure the field exists.
 		f := validator.GetField(sf.Name)
 		if f == nil {
@@ -63,6 +183,32 @@ func (s Sort) Validate(validator schema.Validator) error {
 		if !f.Sortable {
 			return fmt.Errorf("%s: field is not sortable", sf.Name)
 		}
+		switch f.Validator.(type) {
+		case *schema.Encrypted, schema.Encrypted:
+			// The order of ciphertexts is meaningless.
+			return fmt.Errorf("%s: encrypted fields can't be sorted", sf.Name)
+		}
+		if sf.Collation != "" {
+			switch f.Validator.(type) {
+			case *schema.String, schema.String:
+			default:
+				return fmt.Errorf("%s: collation requires a string field", sf.Name)
+			}
+			// Sort fields may be built without ParseSort: storage handlers
+			// rely on the collation being a canonical language tag.
+			if sf.Collation != CollationCaseInsensitive {
+				if tag, err := language.Parse(sf.Collation); err != nil || tag.String() != sf.Collation {
+					return fmt.Errorf("%s: invalid collation %q", sf.Name, sf.Collation)
+				}
+			}
+		}
+		if sf.Near != nil {
+			switch f.Validator.(type) {
+			case schema.GeoPoint, *schema.GeoPoint:
+			default:
+				return fmt.Errorf("%s: near requires a geo point field", sf.Name)
+			}
+		}
 	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -27,6 +28,14 @@ func TestParseSort(t *testing.T) {
 		{"   ,   ,", Sort{}, errors.New("empty sort field")},
 		{"-", Sort{}, errors.New("empty sort field")},
 		{"- ", Sort{}, errors.New("empty sort field")},
+		{"foo:ci,-bar:fr", Sort{SortField{Name: "foo", Collation: "ci"}, SortField{Name: "bar", Reversed: true, Collation: "fr"}}, nil},
+		{"foo:en-us", Sort{SortField{Name: "foo", Collation: "en-US"}}, nil},
+		{":ci", Sort{}, errors.New("empty sort field")},
+		{"foo:$", Sort{}, errors.New("foo: invalid collation \"$\"")},
+		{"loc:near(2.35, 48.85),-foo", Sort{SortField{Name: "loc", Near: &GeoCoordinates{Lng: 2.35, Lat: 48.85}}, SortField{Name: "foo", Reversed: true}}, nil},
+		{"loc:near(2.35)", Sort{}, errors.New("loc: invalid near \"near(2.35)\": expected near(longitude,latitude)")},
+		{"loc:near(a,b)", Sort{}, errors.New("loc: invalid near \"near(a,b)\": not a number")},
+		{"loc:near(2,100)", Sort{}, errors.New("loc: invalid near \"near(2,100)\": invalid geo point")},
 	}
 	for i := range tests {
 		tt := tests[i]
@@ -54,6 +63,8 @@ func TestSortValidate(t *testing.T) {
 	s := schema.Schema{Fields: schema.Fields{
 		"foo": {Sortable: false},
 		"bar": {Sortable: true},
+		"str": {Sortable: true, Validator: &schema.String{}},
+		"loc": {Sortable: true, Validator: &schema.GeoPoint{}},
 	}}
 	tests := []struct {
 		sort string
@@ -62,6 +73,12 @@ func TestSortValidate(t *testing.T) {
 		{"foo", errors.New("foo: field is not sortable")},
 		{"bar", nil},
 		{"baz", errors.New("baz: unknown sort field")},
+		{"str:ci", nil},
+		{"bar:ci", errors.New("bar: collation requires a string field")},
+		{"loc:near(1,2)", nil},
+		{"str:near(1,2)", errors.New("str: near requires a geo point field")},
+		{"-_score,bar", nil},
+		{"_score:ci", errors.New("_score: can't have options")},
 	}
 	for i := range tests {
 		tt := tests[i]
@@ -75,4 +92,42 @@ func TestSortValidate(t *testing.T) {
 			}
 		})
 	}
+	for _, c := range []string{`fr" COLLATE "C`, "EN-us"} {
+		want := fmt.Errorf("str: invalid collation %q", c)
+		if err := (Sort{SortField{Name: "str", Collation: c}}).Validate(s); !reflect.DeepEqual(err, want) {
+			t.Errorf("unexpected validate error:\ngot:  %#v\nwant: %#v", err, want)
+		}
+	}
+}
+
+func TestSortFieldCollator(t *testing.T) {
+	if c := (SortField{Name: "foo"}).Collator(); c != nil {
+		t.Errorf("unexpected collator for a field without collation")
+	}
+	c := SortField{Name: "foo", Collation: CollationCaseInsensitive}.Collator()
+	if c.CompareString("adam", "Zoe") >= 0 {
+		t.Errorf("adam must sort before Zoe")
+	}
+	if c.CompareString("bob", "Bob") != 0 {
+		t.Errorf("bob must be equal to Bob")
+	}
+	c = SortField{Name: "foo", Collation: "fr"}.Collator()
+	if c.CompareString("Émile", "Zoe") >= 0 {
+		t.Errorf("Émile must sort before Zoe")
+	}
+}
+
+func TestSortCheckReadPermission(t *testing.T) {
+	deny := func(ctx context.Context, doc map[string]interface{}) bool { return false }
+	s := schema.Schema{Fields: schema.Fields{
+		"name":   {Sortable: true},
+		"salary": {Sortable: true, ReadPermission: deny},
+	}}
+	if err := MustParseSort("name,-_score").CheckReadPermission(context.Background(), s); err != nil {
+		t.Errorf("CheckReadPermission() error = %v", err)
+	}
+	want := "salary: permission denied"
+	if err := MustParseSort("name,-salary").CheckReadPermission(context.Background(), s); err == nil || err.Error() != want {
+		t.Errorf("CheckReadPermission() error = %v, want %q", err, want)
+	}
 }
@@ -3,8 +3,11 @@ package query
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+
+	"github.com/entropyinf/rest-layer/schema"
 )
 
 // isNumber takes an interface as input, and returns a float64 if the type is
@@ -92,6 +95,56 @@ func valueString(v Value) string {
 	}
 }
 
+// valueEqual tells if two values are equal. When caseInsensitive is true,
+// strings are compared under Unicode case-folding.
+func valueEqual(v1, v2 Value, caseInsensitive bool) bool {
+	if caseInsensitive {
+		if s1, ok := v1.(string); ok {
+			if s2, ok := v2.(string); ok {
+				return strings.EqualFold(s1, s2)
+			}
+		}
+	}
+	return reflect.DeepEqual(v1, v2)
+}
+
+// optionsString returns the $options flags of an expression, prefixed by a
+// coma, or an empty string if the expression has no option.
+func optionsString(caseInsensitive bool) string {
+	if !caseInsensitive {
+		return ""
+	}
+	return ", " + opOptions + ": \"i\""
+}
+
+// typedValue converts a string argument to the type of the field it is
+// compared to. Arguments of unknown fields or of fields with no numeric or
+// boolean validator are returned as strings.
+func typedValue(field, arg string, validator schema.Validator) (Value, error) {
+	if validator == nil {
+		return arg, nil
+	}
+	f := validator.GetField(field)
+	if f == nil {
+		return arg, nil
+	}
+	switch f.Validator.(type) {
+	case schema.Integer, *schema.Integer, schema.Float, *schema.Float:
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q: not a number", arg)
+		}
+		return n, nil
+	case schema.Bool, *schema.Bool:
+		b, err := strconv.ParseBool(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q: not a boolean", arg)
+		}
+		return b, nil
+	}
+	return arg, nil
+}
+
 // getField gets the value of a given field by supporting sub-field path. A get
 // on field.subfield is equivalent to payload["field"]["subfield].
 func getField(payload map[string]interface{}, name string) interface{} {
@@ -7,7 +7,11 @@ import (
 
 // Reference validates the ID of a linked resource.
 type Reference struct {
-	Path            string
+	Path string
+	// CheckExistence makes the write requests of the rest package check again
+	// that the referenced item exists right before storing the document,
+	// fetching all the references to a resource with a single MultiGet.
+	CheckExistence  bool
 	validator       FieldValidator
 	SchemaValidator Validator
 }
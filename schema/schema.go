@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"sort"
 )
 
 type internal struct{}
@@ -29,6 +30,18 @@ type Schema struct {
 	MinLen int
 	// MaxLen defines the maximum number of fields (default no limit).
 	MaxLen int
+	// Validators are called with the whole document once all fields are
+	// valid, so rules involving several fields can be enforced.
+	Validators []DocumentValidator
+	// Version is the version of the schema. Documents written with a previous
+	// version are upgraded with the Upgrades functions when read. Versions
+	// start at 1; the default 0 disables versioning.
+	Version int
+	// Upgrades holds the functions upgrading documents to the next version of
+	// the schema, indexed by the version they upgrade from (i.e.: Upgrades[1]
+	// upgrades documents from version 1 to version 2). A missing function means
+	// documents don't need to be changed for this version.
+	Upgrades map[int]UpgradeFunc
 }
 
 // Compile implements the ReferenceCompiler interface and call the same function
@@ -40,7 +53,16 @@ func (s Schema) Compile(rc ReferenceChecker) error {
 	if err := compileDependencies(s, s); err != nil {
 		return err
 	}
+	for version := range s.Upgrades {
+		if version < 1 || version >= s.Version {
+			return fmt.Errorf("upgrade from version %d out of range for schema version %d", version, s.Version)
+		}
+	}
 	for field, def := range s.Fields {
+		if e, ok := def.Validator.(*Encrypted); ok && e.Context == "" {
+			// Bind the encrypted values to the field.
+			e.Context = field
+		}
 		// Compile each field.
 		if err := def.Compile(rc); err != nil {
 			return fmt.Errorf("%s%v", field, err)
@@ -49,6 +71,18 @@ func (s Schema) Compile(rc ReferenceChecker) error {
 	return nil
 }
 
+// DefaultComputedFields implements the ComputedFieldLister interface.
+func (s Schema) DefaultComputedFields() []string {
+	var names []string
+	for name, def := range s.Fields {
+		if def.Compute != nil && def.ComputeByDefault {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // GetField implements the FieldGetter interface.
 func (s Schema) GetField(name string) *Field {
 	name, remaining, wasSplit := splitFieldPath(name)
@@ -91,7 +125,9 @@ func (s Schema) GetField(name string) *Field {
 // When the original map is defined, the payload is considered as an update on
 // the original document, default values are not assigned, and only fields which
 // are different than in the original are left in the change map. The OnUpdate
-// hook is executed on each field.
+// hook is executed on each field. The payload is applied following the JSON
+// Merge Patch semantics (RFC 7396): a null value removes the field, sub-schemas
+// are merged field by field and Dict or Object values are merged key by key.
 //
 // If the replace argument is set to true with the original document set, the
 // behavior is slightly different as any field not present in the payload but
@@ -104,6 +140,14 @@ func (s Schema) Prepare(ctx context.Context, payload map[string]interface{}, ori
 	base = map[string]interface{}{}
 	for field, def := range s.Fields {
 		value, found := payload[field]
+		if def.Compute != nil {
+			// Computed fields are never stored: keep provided values in the
+			// change map so Validate() can reject them.
+			if found && value != nil {
+				changes[field] = value
+			}
+			continue
+		}
 		if original == nil {
 			if replace == true {
 				log.Panic("Cannot use replace=true without original")
@@ -121,7 +165,22 @@ func (s Schema) Prepare(ctx context.Context, payload map[string]interface{}, ori
 			// Handle prepare on an updated document (original provided).
 			oValue, oFound := (*original)[field]
 			// Apply value to change-set only if the field was not identical same in the original doc.
-			if found {
+			if found && value == nil && !replace {
+				// Following JSON Merge Patch semantics (RFC 7396), a null value
+				// removes the field.
+				if oFound {
+					if def.Default != nil {
+						changes[field] = def.Default
+					} else {
+						changes[field] = Tombstone
+					}
+				}
+			} else if found {
+				if !replace {
+					// Merge objects with the original value rather than
+					// replacing them.
+					value = mergeFieldValue(def, oValue, value)
+				}
 				if def.Validator != nil {
 					if validated, err := def.Validator.Validate(value); err != nil {
 						// We treat a validation error as a change; the validation
@@ -140,7 +199,10 @@ func (s Schema) Prepare(ctx context.Context, payload map[string]interface{}, ori
 				// ReadOnly and then the field can be removed from the output document.
 				// One exception to that though: if the field is set to hidden and is not readonly, we use
 				// previous value as the client would have no way to resubmit the stored value.
-				if def.Hidden && !def.ReadOnly {
+				// The same goes for fields the client is not allowed to read or change.
+				if !def.CanRead(ctx, *original) || !def.CanWrite(ctx, *original) {
+					// Keep the stored value from base.
+				} else if def.Hidden && !def.ReadOnly {
 					changes[field] = oValue
 				} else if def.Default != nil {
 					changes[field] = def.Default
@@ -152,35 +214,47 @@ func (s Schema) Prepare(ctx context.Context, payload map[string]interface{}, ori
 				base[field] = oValue
 			}
 		}
-		if def.Schema != nil {
+		var oValue interface{}
+		if original != nil {
+			oValue = (*original)[field]
+		}
+		if sub, err := def.subSchema(value, oValue); sub != nil && err == nil {
 			// Prepare sub-schema
 			var subOriginal *map[string]interface{}
+			oFound := false
 			if original != nil {
 				// If original is provided, prepare the sub field if it exists and
 				// is a dictionary. Otherwise, use an empty dict.
-				oValue := (*original)[field]
+				_, oFound = (*original)[field]
 				subOriginal = &map[string]interface{}{}
-				if su, ok := oValue.(*map[string]interface{}); ok {
-					subOriginal = su
+				if su, ok := oValue.(map[string]interface{}); ok {
+					subOriginal = &su
 				}
 			}
 			if found {
 				if subPayload, ok := value.(map[string]interface{}); ok {
 					// If payload contains a sub-document for this field, validate it
-					// using the sub-validator.
-					c, b := def.Schema.Prepare(ctx, subPayload, subOriginal, replace)
-					changes[field] = c
+					// using the sub-validator. Only report the field as changed if
+					// some of its sub-fields did.
+					c, b := sub.Prepare(ctx, subPayload, subOriginal, replace)
+					if len(c) > 0 {
+						changes[field] = c
+					} else {
+						delete(changes, field)
+					}
 					base[field] = b
 				} else {
-					// Invalid payload, it will be caught by Validate().
+					// Invalid payload or removal, it will be handled by Validate().
 				}
-			} else {
+			} else if !replace || !oFound {
 				// If the payload doesn't contain a sub-document, perform validation
-				// on an empty one so we don't miss default values.
-				c, b := def.Schema.Prepare(ctx, map[string]interface{}{}, subOriginal, replace)
+				// on an empty one so we don't miss default values and hooks.
+				c, b := sub.Prepare(ctx, map[string]interface{}{}, subOriginal, replace)
+				if len(c) > 0 {
+					changes[field] = c
+				}
 				if len(c) > 0 || len(b) > 0 {
 					// Only apply prepared field if something was added.
-					changes[field] = c
 					base[field] = b
 				}
 			}
@@ -224,17 +298,29 @@ func (s Schema) Prepare(ctx context.Context, payload map[string]interface{}, ori
 // and generate an result document with the changes applied to the base document.
 // All errors in the process are reported in the returned errs value.
 func (s Schema) Validate(changes map[string]interface{}, base map[string]interface{}) (doc map[string]interface{}, errs map[string][]interface{}) {
-	return s.validate(changes, base, true)
+	return s.validate(context.Background(), changes, base, true)
+}
+
+// ValidateContext implements the ContextValidator interface. It is equivalent
+// to Validate, with ctx passed to the document validators.
+func (s Schema) ValidateContext(ctx context.Context, changes map[string]interface{}, base map[string]interface{}) (doc map[string]interface{}, errs map[string][]interface{}) {
+	return s.validate(ctx, changes, base, true)
 }
 
-func (s Schema) validate(changes map[string]interface{}, base map[string]interface{}, isRoot bool) (doc map[string]interface{}, errs map[string][]interface{}) {
+func (s Schema) validate(ctx context.Context, changes map[string]interface{}, base map[string]interface{}, isRoot bool) (doc map[string]interface{}, errs map[string][]interface{}) {
 	doc = map[string]interface{}{}
 	errs = map[string][]interface{}{}
 	for field, def := range s.Fields {
-		// Check read only fields.
-		if def.ReadOnly {
+		// Check read only and computed fields.
+		if def.ReadOnly || def.Compute != nil {
 			if _, found := changes[field]; found {
-				addFieldError(errs, field, "read-only")
+				addFieldError(errs, field, NewValidationError("read_only", nil))
+			}
+		}
+		// Check write permission.
+		if def.WritePermission != nil {
+			if _, found := changes[field]; found && !def.CanWrite(ctx, base) {
+				addFieldError(errs, field, NewValidationError("permission_denied", nil))
 			}
 		}
 		// Check required fields.
@@ -242,10 +328,10 @@ func (s Schema) validate(changes map[string]interface{}, base map[string]interfa
 			if value, found := changes[field]; !found || value == nil || value == Tombstone {
 				if found {
 					// If explicitly set to null, raise the required error.
-					addFieldError(errs, field, "required")
+					addFieldError(errs, field, NewValidationError("required", nil))
 				} else if value, found = base[field]; !found || value == nil {
 					// If field was omitted and isn't set by a Default of a hook, raise.
-					addFieldError(errs, field, "required")
+					addFieldError(errs, field, NewValidationError("required", nil))
 				}
 			}
 		}
@@ -255,7 +341,7 @@ func (s Schema) validate(changes map[string]interface{}, base map[string]interfa
 			if _, found := changes[field]; !found {
 				if _, found := base[field]; !found {
 					empty := map[string]interface{}{}
-					if _, subErrs := def.Schema.validate(empty, empty, false); len(subErrs) > 0 {
+					if _, subErrs := def.Schema.validate(ctx, empty, empty, false); len(subErrs) > 0 {
 						addFieldError(errs, field, subErrs)
 					}
 				}
@@ -270,6 +356,9 @@ func (s Schema) validate(changes map[string]interface{}, base map[string]interfa
 		if value == Tombstone {
 			// If the value is set for removal, remove it from the doc.
 			delete(doc, field)
+		} else if def, found := s.Fields[field]; found && def.Compute != nil {
+			// Computed fields are never stored.
+			delete(doc, field)
 		} else {
 			doc[field] = value
 		}
@@ -285,19 +374,31 @@ func (s Schema) validate(changes map[string]interface{}, base map[string]interfa
 		// the schema).
 		def, found := s.Fields[field]
 		if !found {
-			addFieldError(errs, field, "invalid field")
+			addFieldError(errs, field, NewValidationError("invalid_field", nil))
 			continue
 		}
-		if def.Schema != nil {
+		sub, err := def.subSchema(changes[field], base[field])
+		if err != nil {
+			// The schema of a polymorphic field can't be resolved.
+			addFieldError(errs, field, map[string][]interface{}{def.Validator.(*OneOfSchema).Discriminator: {fieldError(err)}})
+			continue
+		}
+		if sub != nil {
 			// Schema defines a sub-schema.
 			subChanges := map[string]interface{}{}
 			subBase := map[string]interface{}{}
+			// Object validators report their errors as the validator would.
+			object := def.Schema == nil && def.isObject()
+			notDict := NewValidationError("not_dict", nil)
+			if object {
+				notDict = NewValidationError("not_object", nil)
+			}
 			// Check if changes contains a valid sub-document.
 			if v, found := changes[field]; found {
 				if m, ok := v.(map[string]interface{}); ok {
 					subChanges = m
 				} else {
-					addFieldError(errs, field, "not a dict")
+					addFieldError(errs, field, notDict)
 				}
 			}
 			// Check if base contains a valid sub-document.
@@ -305,12 +406,16 @@ func (s Schema) validate(changes map[string]interface{}, base map[string]interfa
 				if m, ok := v.(map[string]interface{}); ok {
 					subBase = m
 				} else {
-					addFieldError(errs, field, "not a dict")
+					addFieldError(errs, field, notDict)
 				}
 			}
 			// Validate sub document and add the result to the current doc's field.
-			if subDoc, subErrs := def.Schema.validate(subChanges, subBase, false); len(subErrs) > 0 {
-				addFieldError(errs, field, subErrs)
+			if subDoc, subErrs := sub.validate(ctx, subChanges, subBase, false); len(subErrs) > 0 {
+				if object {
+					addFieldError(errs, field, fieldError(ErrorMap(subErrs)))
+				} else {
+					addFieldError(errs, field, subErrs)
+				}
 			} else {
 				doc[field] = subDoc
 			}
@@ -318,7 +423,7 @@ func (s Schema) validate(changes map[string]interface{}, base map[string]interfa
 			// Apply validator if provided.
 			var err error
 			if value, err = def.Validator.Validate(value); err != nil {
-				addFieldError(errs, field, err.Error())
+				addFieldError(errs, field, fieldError(err))
 			} else {
 				// Store the normalized value.
 				doc[field] = value
@@ -327,16 +432,57 @@ func (s Schema) validate(changes map[string]interface{}, base map[string]interfa
 	}
 	l := len(doc)
 	if l < s.MinLen {
-		addFieldError(errs, "", fmt.Sprintf("has fewer properties than %d", s.MinLen))
+		addFieldError(errs, "", NewValidationError("too_few_properties", map[string]interface{}{"min": s.MinLen}))
 		return nil, errs
 	}
 	if s.MaxLen > 0 && l > s.MaxLen {
-		addFieldError(errs, "", fmt.Sprintf("has more properties than %d", s.MaxLen))
+		addFieldError(errs, "", NewValidationError("too_many_properties", map[string]interface{}{"max": s.MaxLen}))
 		return nil, errs
 	}
+	if len(errs) == 0 {
+		s.validateDocument(ctx, changes, base, doc, errs)
+	}
 	return doc, errs
 }
 
+// subSchema returns the schema of the sub-document held by the field, if any.
+// Object validators are handled as sub-schemas so the hooks and checks of
+// their fields apply. The schema of polymorphic fields (OneOfSchema) is
+// resolved from the first of docs holding a discriminator value.
+func (f Field) subSchema(docs ...interface{}) (*Schema, error) {
+	if f.Schema != nil {
+		return f.Schema, nil
+	}
+	var oneOf *OneOfSchema
+	switch v := f.Validator.(type) {
+	case *Object:
+		return v.Schema, nil
+	case Object:
+		return v.Schema, nil
+	case *OneOfSchema:
+		oneOf = v
+	default:
+		return nil, nil
+	}
+	for _, doc := range docs {
+		if m, ok := doc.(map[string]interface{}); ok {
+			if _, found := m[oneOf.Discriminator]; found {
+				return oneOf.Resolve(m)
+			}
+		}
+	}
+	return nil, NewValidationError("required", nil)
+}
+
+// isObject tells if the field is validated by an Object validator.
+func (f Field) isObject() bool {
+	switch f.Validator.(type) {
+	case *Object, Object:
+		return true
+	}
+	return false
+}
+
 func addFieldError(errs map[string][]interface{}, field string, err interface{}) {
 	errs[field] = append(errs[field], err)
 }
@@ -61,7 +61,7 @@ func TestSchemaValidator(t *testing.T) {
 			Name:   `MinLen=2,Validate(map[string]interface{}{"foo":true})`,
 			Schema: minLenSchema,
 			Change: map[string]interface{}{"foo": true},
-			Errors: map[string][]interface{}{"": []interface{}{"has fewer properties than 2"}},
+			Errors: map[string][]interface{}{"": []interface{}{schema.NewValidationError("too_few_properties", map[string]interface{}{"min": 2})}},
 		},
 		{
 			Name:   `MaxLen=2,Validate(map[string]interface{}{"foo":true,"bar":false})`,
@@ -73,7 +73,7 @@ func TestSchemaValidator(t *testing.T) {
 			Name:   `MaxLen=2,Validate(map[string]interface{}{"foo":true,"bar":true,"baz":false})`,
 			Schema: maxLenSchema,
 			Change: map[string]interface{}{"foo": true, "bar": true, "baz": false},
-			Errors: map[string][]interface{}{"": []interface{}{"has more properties than 2"}},
+			Errors: map[string][]interface{}{"": []interface{}{schema.NewValidationError("too_many_properties", map[string]interface{}{"max": 2})}},
 		},
 	}
 
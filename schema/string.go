@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
-	"strings"
 )
 
 // String validates string based values
@@ -31,7 +30,7 @@ func (v *String) Compile(rc ReferenceChecker) (err error) {
 func (v String) ValidateQuery(value interface{}) (interface{}, error) {
 	s, ok := value.(string)
 	if !ok {
-		return nil, errors.New("not a string")
+		return nil, NewValidationError("not_string", nil)
 	}
 	return s, nil
 }
@@ -45,14 +44,14 @@ func (v String) Validate(value interface{}) (interface{}, error) {
 
 	s, ok := value.(string)
 	if !ok {
-		return nil, errors.New("not a string")
+		return nil, NewValidationError("not_string", nil)
 	}
 	l := len(s)
 	if l < v.MinLen {
-		return nil, fmt.Errorf("is shorter than %d", v.MinLen)
+		return nil, NewValidationError("too_short", map[string]interface{}{"min": v.MinLen})
 	}
 	if v.MaxLen > 0 && l > v.MaxLen {
-		return nil, fmt.Errorf("is longer than %d", v.MaxLen)
+		return nil, NewValidationError("too_long", map[string]interface{}{"max": v.MaxLen})
 	}
 	if len(v.Allowed) > 0 {
 		found := false
@@ -63,12 +62,12 @@ func (v String) Validate(value interface{}) (interface{}, error) {
 			}
 		}
 		if !found {
-			return nil, fmt.Errorf("not one of [%s]", strings.Join(v.Allowed, ", "))
+			return nil, NewValidationError("not_allowed", map[string]interface{}{"allowed": v.Allowed})
 		}
 	}
 	if v.Regexp != "" {
 		if !v.re.MatchString(s) {
-			return nil, fmt.Errorf("does not match %s", v.Regexp)
+			return nil, NewValidationError("pattern_mismatch", map[string]interface{}{"pattern": v.Regexp})
 		}
 	}
 	return s, nil
@@ -1,7 +1,6 @@
 package schema
 
 import (
-	"errors"
 	"reflect"
 	"testing"
 
@@ -18,7 +17,7 @@ func TestStringQueryValidator(t *testing.T) {
 		{`String.ValidateQuery(string)`, String{}, "foo", "foo", nil},
 		{`String.ValidateQuery(string)-ouf range`, String{MaxLen: 2}, "foo", "foo", nil},
 		{`String.ValidateQuery(string)-not allowed`, String{Allowed: []string{"bar", "baz"}}, "foo", "foo", nil},
-		{"String.ValidateQuery(int)", String{}, 1, nil, errors.New("not a string")},
+		{"String.ValidateQuery(int)", String{}, 1, nil, NewValidationError("not_string", nil)},
 	}
 	for i := range cases {
 		tt := cases[i]
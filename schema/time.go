@@ -2,7 +2,6 @@ package schema
 
 import (
 	"context"
-	"errors"
 	"time"
 )
 
@@ -79,7 +78,7 @@ func (v Time) parse(value interface{}) (interface{}, error) {
 		}
 	}
 	if _, ok := value.(time.Time); !ok {
-		return nil, errors.New("not a time")
+		return nil, NewValidationError("not_time", nil)
 	}
 	return value, nil
 }
@@ -97,7 +96,7 @@ func (v Time) Validate(value interface{}) (interface{}, error) {
 func (v Time) get(value interface{}) (time.Time, error) {
 	t, ok := value.(time.Time)
 	if !ok {
-		return t, errors.New("not a time")
+		return t, NewValidationError("not_time", nil)
 	}
 	return t, nil
 }
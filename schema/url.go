@@ -1,8 +1,6 @@
 package schema
 
 import (
-	"errors"
-	"fmt"
 	"net/url"
 	"strings"
 )
@@ -19,17 +17,17 @@ type URL struct {
 func (v URL) Validate(value interface{}) (interface{}, error) {
 	str, ok := value.(string)
 	if !ok {
-		return nil, errors.New("invalid type")
+		return nil, NewValidationError("invalid_type", nil)
 	}
 	u, err := url.Parse(str)
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %s", err.Error())
+		return nil, NewValidationError("invalid_url", map[string]interface{}{"error": err})
 	}
 	if !v.AllowRelative && !u.IsAbs() {
-		return nil, errors.New("is relative URL")
+		return nil, NewValidationError("relative_url", nil)
 	}
 	if !v.AllowLocale && strings.IndexByte(u.Host, '.') == -1 {
-		return nil, errors.New("invalid domain")
+		return nil, NewValidationError("invalid_domain", nil)
 	}
 	if len(v.AllowedSchemes) > 0 {
 		found := false
@@ -40,10 +38,10 @@ func (v URL) Validate(value interface{}) (interface{}, error) {
 			}
 		}
 		if !found {
-			return nil, errors.New("invalid scheme")
+			return nil, NewValidationError("invalid_scheme", nil)
 		}
 	} else if !v.AllowNonHTTP && u.Scheme != "http" && u.Scheme != "https" {
-		return nil, errors.New("invalid scheme")
+		return nil, NewValidationError("invalid_scheme", nil)
 	}
 	return u.String(), nil
 }
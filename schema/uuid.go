@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// UUID validates RFC 4122 UUIDs. The UUID is normalized to its lower case
+// canonical form (xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx). The "urn:uuid:" prefix,
+// curly braces and the form without hyphens are accepted as input.
+type UUID struct {
+	// Version restricts the accepted UUIDs to the given version (1 to 8). Any
+	// version is accepted when 0.
+	Version int
+	// StoreBinary activates storage of the UUID as its 16 bytes instead of its
+	// 36 characters representation.
+	StoreBinary bool
+}
+
+// ValidateQuery implements FieldQueryValidator interface.
+func (v UUID) ValidateQuery(value interface{}) (interface{}, error) {
+	b, err := v.parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return v.store(b), nil
+}
+
+// Validate implements FieldValidator interface.
+func (v UUID) Validate(value interface{}) (interface{}, error) {
+	b, err := v.parse(value)
+	if err != nil {
+		return nil, err
+	}
+	if v.Version != 0 && int(b[6]>>4) != v.Version {
+		return nil, NewValidationError("invalid_uuid_version", map[string]interface{}{"version": v.Version})
+	}
+	return v.store(b), nil
+}
+
+// Serialize implements FieldSerializer.
+func (v UUID) Serialize(value interface{}) (interface{}, error) {
+	if !v.StoreBinary {
+		return value, nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil, NewValidationError("invalid_type", nil)
+	}
+	if len(b) != 16 {
+		return nil, NewValidationError("invalid_size", nil)
+	}
+	return formatUUID(b), nil
+}
+
+func (v UUID) store(b []byte) interface{} {
+	if v.StoreBinary {
+		return b
+	}
+	return formatUUID(b)
+}
+
+func (v UUID) parse(value interface{}) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, NewValidationError("invalid_type", nil)
+	}
+	if len(s) > 9 && strings.EqualFold(s[:9], "urn:uuid:") {
+		s = s[9:]
+	} else if len(s) > 2 && s[0] == '{' && s[len(s)-1] == '}' {
+		s = s[1 : len(s)-1]
+	}
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return nil, NewValidationError("invalid_uuid", nil)
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	case 32:
+	default:
+		return nil, NewValidationError("invalid_uuid", nil)
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, NewValidationError("invalid_uuid", nil)
+	}
+	return b, nil
+}
+
+func formatUUID(b []byte) string {
+	s := hex.EncodeToString(b)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUUIDValidator(t *testing.T) {
+	const id = "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	for _, input := range []string{
+		id,
+		"F47AC10B-58CC-4372-A567-0E02B2C3D479",
+		"urn:uuid:" + id,
+		"{" + id + "}",
+		"f47ac10b58cc4372a5670e02b2c3d479",
+	} {
+		v, err := UUID{}.Validate(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, id, v, input)
+	}
+	for _, invalid := range []string{"", "f47ac10b-58cc-4372-a567", "f47ac10b_58cc_4372_a567_0e02b2c3d479", "g47ac10b-58cc-4372-a567-0e02b2c3d479"} {
+		v, err := UUID{}.Validate(invalid)
+		assert.EqualError(t, err, "invalid UUID", invalid)
+		assert.Nil(t, v)
+	}
+	v, err := UUID{}.Validate(1)
+	assert.EqualError(t, err, "invalid type")
+	assert.Nil(t, v)
+	v, err = UUID{Version: 4}.Validate(id)
+	assert.NoError(t, err)
+	assert.Equal(t, id, v)
+	v, err = UUID{Version: 1}.Validate(id)
+	assert.EqualError(t, err, "not a version 1 UUID")
+	assert.Nil(t, v)
+	v, err = UUID{StoreBinary: true}.Validate(id)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xf4, 0x7a, 0xc1, 0x0b, 0x58, 0xcc, 0x43, 0x72, 0xa5, 0x67, 0x0e, 0x02, 0xb2, 0xc3, 0xd4, 0x79}, v)
+}
+
+func TestUUIDValidatorSerialize(t *testing.T) {
+	const id = "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	v, err := UUID{}.Serialize(id)
+	assert.NoError(t, err)
+	assert.Equal(t, id, v)
+	v, err = UUID{StoreBinary: true}.Serialize([]byte{0xf4, 0x7a, 0xc1, 0x0b, 0x58, 0xcc, 0x43, 0x72, 0xa5, 0x67, 0x0e, 0x02, 0xb2, 0xc3, 0xd4, 0x79})
+	assert.NoError(t, err)
+	assert.Equal(t, id, v)
+	v, err = UUID{StoreBinary: true}.Serialize(id)
+	assert.EqualError(t, err, "invalid type")
+	assert.Nil(t, v)
+	v, err = UUID{StoreBinary: true}.Serialize([]byte{1, 2})
+	assert.EqualError(t, err, "invalid size")
+	assert.Nil(t, v)
+}
+
+func TestUUIDQueryValidator(t *testing.T) {
+	v, err := UUID{Version: 1}.ValidateQuery("F47AC10B-58CC-4372-A567-0E02B2C3D479")
+	assert.NoError(t, err)
+	assert.Equal(t, "f47ac10b-58cc-4372-a567-0e02b2c3d479", v)
+}
@@ -0,0 +1,30 @@
+package schema
+
+import "fmt"
+
+// UpgradeFunc upgrades a document to the next version of a schema. The
+// document may be modified in place.
+type UpgradeFunc func(doc map[string]interface{}) (map[string]interface{}, error)
+
+// Upgrade applies the upgrade functions needed to bring doc, written with the
+// given version of the schema, to the current version. Versions lower than 1
+// are considered to be version 1.
+func (s Schema) Upgrade(doc map[string]interface{}, version int) (map[string]interface{}, error) {
+	if version < 1 {
+		version = 1
+	}
+	if version > s.Version {
+		return nil, fmt.Errorf("document version %d is newer than schema version %d", version, s.Version)
+	}
+	for ; version < s.Version; version++ {
+		upgrade := s.Upgrades[version]
+		if upgrade == nil {
+			continue
+		}
+		var err error
+		if doc, err = upgrade(doc); err != nil {
+			return nil, fmt.Errorf("upgrade from version %d: %v", version, err)
+		}
+	}
+	return doc, nil
+}
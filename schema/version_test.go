@@ -0,0 +1,65 @@
+package schema_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newVersionedSchema() schema.Schema {
+	return schema.Schema{
+		Fields: schema.Fields{
+			"name": {},
+			"tags": {},
+		},
+		Version: 3,
+		Upgrades: map[int]schema.UpgradeFunc{
+			1: func(doc map[string]interface{}) (map[string]interface{}, error) {
+				doc["name"] = doc["title"]
+				delete(doc, "title")
+				return doc, nil
+			},
+			2: func(doc map[string]interface{}) (map[string]interface{}, error) {
+				if tag, ok := doc["tag"].(string); ok {
+					doc["tags"] = []interface{}{tag}
+					delete(doc, "tag")
+				}
+				return doc, nil
+			},
+		},
+	}
+}
+
+func TestSchemaUpgrade(t *testing.T) {
+	s := newVersionedSchema()
+	assert.NoError(t, s.Compile(nil))
+
+	doc, err := s.Upgrade(map[string]interface{}{"title": "foo", "tag": "bar"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "foo", "tags": []interface{}{"bar"}}, doc)
+
+	doc, err = s.Upgrade(map[string]interface{}{"name": "foo", "tag": "bar"}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "foo", "tags": []interface{}{"bar"}}, doc)
+
+	doc, err = s.Upgrade(map[string]interface{}{"name": "foo"}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "foo"}, doc)
+
+	_, err = s.Upgrade(map[string]interface{}{}, 4)
+	assert.EqualError(t, err, "document version 4 is newer than schema version 3")
+
+	s.Upgrades[2] = func(doc map[string]interface{}) (map[string]interface{}, error) {
+		return nil, errors.New("failed")
+	}
+	_, err = s.Upgrade(map[string]interface{}{}, 1)
+	assert.EqualError(t, err, "upgrade from version 2: failed")
+}
+
+func TestSchemaCompileUpgrades(t *testing.T) {
+	s := newVersionedSchema()
+	s.Version = 2
+	assert.EqualError(t, s.Compile(nil), "upgrade from version 2 out of range for schema version 2")
+}
@@ -21,8 +21,9 @@ type MemoryHandler struct {
 	// all operations.
 	Latency time.Duration
 
-	items map[interface{}][]byte
-	ids   []interface{}
+	items  map[interface{}][]byte
+	ids    []interface{}
+	search *searchIndex
 }
 
 func init() {
@@ -48,6 +49,26 @@ func NewSlowHandler(latency time.Duration) *MemoryHandler {
 	}
 }
 
+// EnableSearchIndex maintains an inverted index of the words of the given
+// string fields. The index speeds up the $search queries on these fields, as
+// only the items containing the searched words are decoded, and is used to
+// rank the results by relevance when sorted by _score. Items already stored
+// are indexed.
+func (m *MemoryHandler) EnableSearchIndex(fields ...string) error {
+	m.Lock()
+	defer m.Unlock()
+	idx := newSearchIndex(fields)
+	for _, id := range m.ids {
+		item, _, err := m.fetch(id)
+		if err != nil {
+			return err
+		}
+		idx.add(item)
+	}
+	m.search = idx
+	return nil
+}
+
 // store serialize the item using gob and store it in the handler's items map.
 func (m *MemoryHandler) store(item *resource.Item) error {
 	var data bytes.Buffer
@@ -56,6 +77,10 @@ func (m *MemoryHandler) store(item *resource.Item) error {
 		return err
 	}
 	m.items[item.ID] = data.Bytes()
+	if m.search != nil {
+		m.search.remove(item.ID)
+		m.search.add(item)
+	}
 	return nil
 }
 
@@ -76,6 +101,9 @@ func (m *MemoryHandler) fetch(id interface{}) (*resource.Item, bool, error) {
 // delete removes an item by this id without locking.
 func (m *MemoryHandler) delete(id interface{}) {
 	delete(m.items, id)
+	if m.search != nil {
+		m.search.remove(id)
+	}
 	// Remove id from id list
 	for i, _id := range m.ids {
 		if _id == id {
@@ -183,13 +211,38 @@ func (m *MemoryHandler) Find(ctx context.Context, q *query.Query) (list *resourc
 }
 
 func (m *MemoryHandler) find(ctx context.Context, q *query.Query) (*resource.ItemList, error) {
+	var searches []*query.Search
+	if sortedByScore(q.Sort) {
+		searches = searchExpressions(q.Predicate)
+	}
+	// Only decode the items containing the searched words when indexed.
+	var candidates map[interface{}]struct{}
+	if m.search != nil {
+		candidates = m.search.candidates(q.Predicate)
+	}
+	// Scoring needs the statistics of all the items, gathered in a temporary
+	// index if the searched fields are not indexed.
+	stats := m.search
+	if len(searches) > 0 && (stats == nil || !stats.covers(searches)) {
+		stats = newSearchIndex(searchFields(searches))
+		candidates = nil
+	}
+
 	// Fetch all items matching the filter
 	list := resource.ItemList{Items: []*resource.Item{}}
 	for _, id := range m.ids {
+		if candidates != nil {
+			if _, found := candidates[id]; !found {
+				continue
+			}
+		}
 		item, _, err := m.fetch(id)
 		if err != nil {
 			return nil, err
 		}
+		if stats != m.search {
+			stats.add(item)
+		}
 		if !q.Predicate.Match(item.Payload) {
 			continue
 		}
@@ -199,7 +252,15 @@ func (m *MemoryHandler) find(ctx context.Context, q *query.Query) (*resource.Ite
 
 	// Apply sort
 	if len(q.Sort) > 0 {
-		s := sortableItems{q.Sort, list.Items}
+		var scores map[interface{}]float64
+		if len(searches) > 0 {
+			score := stats.scorer(searches)
+			scores = make(map[interface{}]float64, len(list.Items))
+			for _, item := range list.Items {
+				scores[item.ID] = score(item.ID)
+			}
+		}
+		s := newSortableItems(q.Sort, list.Items, scores)
 		sort.Sort(s)
 	}
 	// Apply pagination
@@ -0,0 +1,237 @@
+package pgsql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	. "github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/entropyinf/rest-layer/schema/query"
+	"github.com/sirupsen/logrus"
+)
+
+// Aggregate implements the resource.Aggregator interface using GROUP BY.
+func (s store) Aggregate(ctx context.Context, q *query.Query, a *query.Aggregation) ([]map[string]interface{}, error) {
+	sqlStr, args, names, err := buildAggregateQuery(s.table, q, a, s.postGIS)
+	if err != nil {
+		return nil, err
+	}
+
+	pgSqlStr := transformQueryPostgres(sqlStr)
+	pgArgs := transformParamsPostgres(args)
+
+	logrus.Traceln(pgSqlStr)
+	logrus.Traceln(pgArgs...)
+
+	rows, err := s.db.QueryContext(ctx, pgSqlStr, pgArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ops := make(map[string]query.AccumulatorOp, len(a.Accumulators))
+	// Nested group keys, min and max are returned as JSONB and decoded to
+	// keep the type of the values.
+	jsonb := make([]bool, 0, len(names))
+	for _, field := range a.GroupBy {
+		jsonb = append(jsonb, isNested(field))
+	}
+	for _, acc := range a.Accumulators {
+		ops[acc.Name] = acc.Op
+		jsonb = append(jsonb, isNested(acc.Field) && (acc.Op == query.AccumulatorMin || acc.Op == query.AccumulatorMax))
+	}
+
+	groups := []map[string]interface{}{}
+	for rows.Next() {
+		rowVals := make([]any, len(names))
+		rowValPtrs := make([]any, len(names))
+		for i := range names {
+			rowValPtrs[i] = &rowVals[i]
+		}
+		if err := rows.Scan(rowValPtrs...); err != nil {
+			return nil, err
+		}
+
+		group := make(map[string]interface{}, len(names))
+		for i, v := range rowVals {
+			if b, ok := v.([]byte); ok {
+				if jsonb[i] {
+					v = decodeJSONB(b)
+				} else {
+					v = string(b)
+				}
+			}
+			switch ops[names[i]] {
+			case query.AccumulatorCount:
+				if n, ok := v.(int64); ok {
+					v = int(n)
+				}
+			case query.AccumulatorSum, query.AccumulatorAvg:
+				// NUMERIC results are returned as strings.
+				if str, ok := v.(string); ok {
+					if f, err := strconv.ParseFloat(str, 64); err == nil {
+						v = f
+					}
+				} else if n, ok := v.(int64); ok {
+					v = float64(n)
+				}
+			}
+			group[names[i]] = v
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, rows.Err()
+}
+
+// buildAggregateQuery returns the query computing the aggregation, and the
+// names of the returned columns. Columns are aliased by position as the names
+// come from the user and can't be safely quoted as identifiers.
+func buildAggregateQuery(table string, q *query.Query, a *query.Aggregation, postGIS bool) (string, []any, []string, error) {
+	selects := make([]any, 0, len(a.GroupBy)+len(a.Accumulators))
+	names := make([]string, 0, cap(selects))
+	groupBy := make([]any, 0, len(a.GroupBy))
+	orderBy := make([]exp.OrderedExpression, 0, len(a.GroupBy))
+	for i, field := range a.GroupBy {
+		selects = append(selects, jsonbColumn(field).As(C("c"+strconv.Itoa(len(names)))))
+		names = append(names, field)
+		// Group and sort by position: nested fields bind their path as
+		// parameters, and PostgreSQL doesn't consider two expressions with
+		// distinct parameters to be the same.
+		pos := L(strconv.Itoa(i + 1))
+		groupBy = append(groupBy, pos)
+		orderBy = append(orderBy, pos.Asc().NullsFirst())
+	}
+	for _, acc := range a.Accumulators {
+		var fn exp.Aliaseable
+		switch acc.Op {
+		case query.AccumulatorCount:
+			fn = COUNT(Star())
+		case query.AccumulatorSum:
+			fn = COALESCE(SUM(numericColumn(acc.Field)), 0)
+		case query.AccumulatorAvg:
+			fn = AVG(numericColumn(acc.Field))
+		case query.AccumulatorMin:
+			fn = extremum(acc.Field, "ASC")
+		case query.AccumulatorMax:
+			fn = extremum(acc.Field, "DESC")
+		}
+		selects = append(selects, fn.As(C("c"+strconv.Itoa(len(names)))))
+		names = append(names, acc.Name)
+	}
+
+	builder := From(table).Select(selects...)
+	buildWheres(q, builder, postGIS)
+	if len(groupBy) > 0 {
+		builder = builder.GroupBy(groupBy...).Order(orderBy...)
+	}
+
+	sql, args, err := builder.Prepared(true).ToSQL()
+	return sql, args, names, err
+}
+
+// numericColumn returns the column expression of a numeric field. Nested
+// fields are extracted from JSONB columns as text and cast to numeric.
+func numericColumn(field string) exp.Expression {
+	if isNested(field) {
+		return L("(?)::numeric", aggregateColumn(field))
+	}
+	return C(field)
+}
+
+// extremum returns the expression computing the lowest (ASC) or highest (DESC)
+// value of a field. As MIN and MAX don't support JSONB, the values of nested
+// fields are ordered as JSONB, which compares numbers numerically, and the
+// first one is taken.
+func extremum(field, order string) exp.Aliaseable {
+	if !isNested(field) {
+		if order == "ASC" {
+			return MIN(C(field))
+		}
+		return MAX(C(field))
+	}
+	col := jsonbColumn(field)
+	return L("(ARRAY_AGG(? ORDER BY ? "+order+") FILTER (WHERE jsonb_typeof(?) <> 'null'))[1]", col, col, col)
+}
+
+// decodeJSONB decodes a JSONB value, numbers being returned as int when they
+// are integers and float64 otherwise.
+func decodeJSONB(b []byte) interface{} {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return string(b)
+	}
+	return jsonNumbers(v)
+}
+
+func jsonNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = jsonNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = jsonNumbers(e)
+		}
+	}
+	return v
+}
+
+// isNested tells if field is nested in a JSONB column.
+func isNested(field string) bool {
+	return strings.Contains(field, ".")
+}
+
+// aggregatableColumn is a column expression which can be aliased, sorted and
+// compared.
+type aggregatableColumn interface {
+	exp.Expression
+	exp.Aliaseable
+	exp.Orderable
+	exp.Comparable
+	exp.Inable
+	exp.Likeable
+}
+
+// aggregateColumn returns the column expression of a field, extracting nested
+// fields from JSONB columns as text.
+func aggregateColumn(field string) aggregatableColumn {
+	if strings.Contains(field, ".") {
+		return jsonPath("jsonb_extract_path_text", field)
+	}
+	return C(field)
+}
+
+// jsonbColumn returns the column expression of a field, extracting nested
+// fields from JSONB columns as JSONB.
+func jsonbColumn(field string) aggregatableColumn {
+	if strings.Contains(field, ".") {
+		return jsonPath("jsonb_extract_path", field)
+	}
+	return C(field)
+}
+
+// jsonPath returns the call of fn extracting the nested field from its JSONB
+// root column. The keys of the path are bound as parameters, so they can
+// safely come from the user: a Dict accepts any key.
+func jsonPath(fn, field string) exp.LiteralExpression {
+	path := strings.Split(field, ".")
+	args := make([]interface{}, 0, len(path))
+	args = append(args, C(path[0]))
+	for _, key := range path[1:] {
+		args = append(args, key)
+	}
+	return L(fn+"(?"+strings.Repeat(", ?", len(path)-1)+")", args...)
+}
@@ -0,0 +1,95 @@
+package pgsql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/entropyinf/rest-layer/schema/query"
+)
+
+func TestBuildAggregateQueryBindsNestedKeys(t *testing.T) {
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"meta": {
+				Filterable: true,
+				Validator:  &schema.Dict{Values: schema.Field{Filterable: true, Validator: &schema.Integer{}}},
+			},
+		},
+	}
+	key := "x') FROM pg_user; --"
+	a, err := query.ParseAggregation("meta." + key + ",count()")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if err := a.Prepare(s); err != nil {
+		t.Fatalf("unexpected prepare error: %v", err)
+	}
+	sql, args, names, err := buildAggregateQuery("t", &query.Query{}, a, false)
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if strings.Contains(sql, "pg_user") || strings.Contains(sql, "'") {
+		t.Errorf("nested key not bound as a parameter: %s", sql)
+	}
+	want := `SELECT jsonb_extract_path("meta", ?) AS "c0", COUNT(*) AS "c1" FROM "t" GROUP BY 1 ORDER BY 1 ASC NULLS FIRST`
+	if sql != want {
+		t.Errorf("unexpected SQL:\ngot:  %s\nwant: %s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{key}) {
+		t.Errorf("unexpected args: %#v", args)
+	}
+	if want := []string{"meta." + key, "count"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("unexpected names: %#v", names)
+	}
+}
+
+func TestBuildAggregateQueryNestedAccumulators(t *testing.T) {
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"meta": {
+				Filterable: true,
+				Validator:  &schema.Dict{Values: schema.Field{Filterable: true, Validator: &schema.Integer{}}},
+			},
+		},
+	}
+	a, err := query.ParseAggregation("sum(meta.x),avg(meta.x),min(meta.x),max(meta.x)")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if err := a.Prepare(s); err != nil {
+		t.Fatalf("unexpected prepare error: %v", err)
+	}
+	sql, args, _, err := buildAggregateQuery("t", &query.Query{}, a, false)
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	want := `SELECT COALESCE(SUM((jsonb_extract_path_text("meta", ?))::numeric), ?) AS "c0", ` +
+		`AVG((jsonb_extract_path_text("meta", ?))::numeric) AS "c1", ` +
+		`(ARRAY_AGG(jsonb_extract_path("meta", ?) ORDER BY jsonb_extract_path("meta", ?) ASC) FILTER (WHERE jsonb_typeof(jsonb_extract_path("meta", ?)) <> 'null'))[1] AS "c2", ` +
+		`(ARRAY_AGG(jsonb_extract_path("meta", ?) ORDER BY jsonb_extract_path("meta", ?) DESC) FILTER (WHERE jsonb_typeof(jsonb_extract_path("meta", ?)) <> 'null'))[1] AS "c3" ` +
+		`FROM "t"`
+	if sql != want {
+		t.Errorf("unexpected SQL:\ngot:  %s\nwant: %s", sql, want)
+	}
+	if len(args) != 9 {
+		t.Errorf("unexpected args: %#v", args)
+	}
+}
+
+func TestDecodeJSONB(t *testing.T) {
+	tests := map[string]interface{}{
+		`10`:            10,
+		`1.5`:           1.5,
+		`"9"`:           "9",
+		`true`:          true,
+		`null`:          nil,
+		`{"a": [1, 2]}`: map[string]interface{}{"a": []interface{}{1, 2}},
+	}
+	for in, want := range tests {
+		if got := decodeJSONB([]byte(in)); !reflect.DeepEqual(got, want) {
+			t.Errorf("decodeJSONB(%s) = %#v, want %#v", in, got, want)
+		}
+	}
+}
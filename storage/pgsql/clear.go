@@ -19,7 +19,7 @@ func (s store) Clear(ctx context.Context, q *query.Query) (count int, err error)
 
 	builder := Delete(s.table)
 
-	buildDeleteWheres(q, builder)
+	buildDeleteWheres(q, builder, s.postGIS)
 
 	sqlStr, args, err := builder.Prepared(true).ToSQL()
 	if err != nil {
@@ -49,7 +49,7 @@ func (s store) Clear(ctx context.Context, q *query.Query) (count int, err error)
 
 	return int(cnt), err
 }
-func buildDeleteWheres(q *query.Query, builder *DeleteDataset) {
-	expressions := predicteToExpressions(q.Predicate)
+func buildDeleteWheres(q *query.Query, builder *DeleteDataset, postGIS bool) {
+	expressions := predicteToExpressions(q.Predicate, postGIS)
 	*builder = *builder.Where(expressions...)
 }
@@ -1,7 +1,6 @@
 package pgsql
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	. "github.com/doug-martin/goqu/v9"
@@ -9,6 +8,7 @@ import (
 	"github.com/entropyinf/rest-layer/schema"
 	"github.com/entropyinf/rest-layer/schema/query"
 	"github.com/sirupsen/logrus"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -16,8 +16,8 @@ import (
 func (s store) Find(ctx context.Context, q *query.Query) (*resource.ItemList, error) {
 	builder := From(s.table)
 	buildSelects(q, builder)
-	buildWheres(q, builder)
-	buildSorts(q, builder)
+	buildWheres(q, builder, s.postGIS)
+	buildSorts(q, builder, s.postGIS)
 	buildPagination(q, builder)
 
 	sqlStr, args, err := builder.Prepared(true).ToSQL()
@@ -110,7 +110,7 @@ func (s store) Find(ctx context.Context, q *query.Query) (*resource.ItemList, er
 
 func (s store) Count(ctx context.Context, q *query.Query) (int, error) {
 	builder := From(s.table).Select(COUNT(Star()))
-	buildWheres(q, builder)
+	buildWheres(q, builder, s.postGIS)
 
 	sqlStr, args, err := builder.Prepared(true).ToSQL()
 	if err != nil {
@@ -133,7 +133,7 @@ func (s store) Count(ctx context.Context, q *query.Query) (int, error) {
 
 func toJsonNode(field *schema.Field, cell string) any {
 	switch field.Validator.(type) {
-	case *schema.Object, *schema.Dict, nil:
+	case *schema.Object, *schema.Dict, *schema.GeoPoint, nil:
 		jsonNode := make(map[string]any)
 		if err := json.Unmarshal([]byte(cell), &jsonNode); err != nil {
 			return nil
@@ -162,30 +162,74 @@ func buildPagination(q *query.Query, builder *SelectDataset) {
 	*builder = *builder.Offset(uint(offset))
 }
 
-func buildSorts(q *query.Query, builder *SelectDataset) {
+func buildSorts(q *query.Query, builder *SelectDataset, postGIS bool) {
 	for _, field := range q.Sort {
+		if field.Name == query.ScoreField {
+			logrus.Warnln("sort by search relevance not supported. ignored")
+			continue
+		}
+		col := sortColumn(field, postGIS)
 		if field.Reversed {
-			*builder = *builder.Order(C(field.Name).Desc())
+			*builder = *builder.OrderAppend(col.Desc())
 		} else {
-			*builder = *builder.Order(C(field.Name).Asc())
+			*builder = *builder.OrderAppend(col.Asc())
+		}
+	}
+}
+
+// searchToExpression matches the words of a full-text search with case
+// insensitive regular expressions anchored on word boundaries.
+func searchToExpression(t *query.Search) Expression {
+	col := aggregateColumn(t.Field)
+	expressions := []Expression{}
+	for _, term := range t.Terms() {
+		re := `\m` + regexp.QuoteMeta(term.Word)
+		if !term.Prefix {
+			re += `\M`
 		}
+		expressions = append(expressions, L("? ~* ?", col, re))
 	}
+	return And(expressions...)
 }
 
-func buildWheres(q *query.Query, builder *SelectDataset) {
-	expressions := predicteToExpressions(q.Predicate)
+// sortColumn returns the expression to sort a field on. Case insensitive sorts
+// are done on the lower cased value and language specific sorts use the ICU
+// collation of the language. Geo points are sorted on their distance to the
+// sort point.
+func sortColumn(field query.SortField, postGIS bool) aggregatableColumn {
+	if field.Near != nil {
+		return geoDistance(geoColumn(field.Name), *field.Near, postGIS)
+	}
+	switch field.Collation {
+	case "":
+		return aggregateColumn(field.Name)
+	case query.CollationCaseInsensitive:
+		return L("LOWER(?)", aggregateColumn(field.Name))
+	}
+	// The collation is a BCP 47 tag validated by Sort.Validate, quoted as an
+	// identifier.
+	return L("? COLLATE ?", aggregateColumn(field.Name), I(field.Collation+"-x-icu"))
+}
+
+func buildWheres(q *query.Query, builder *SelectDataset, postGIS bool) {
+	expressions := predicteToExpressions(q.Predicate, postGIS)
 	*builder = *builder.Where(expressions...)
 }
 
 func buildSelects(q *query.Query, builder *SelectDataset) {
 	pj := q.Projection
-	if pj == nil || len(pj) == 0 || hasStar(pj) {
+	// A projection made of excluded fields only selects all the other
+	// fields, which are removed when the projection is evaluated.
+	if pj == nil || len(pj) == 0 || hasStar(pj) || !match(pj, isIncluded) {
 		*builder = *builder.Select(Star())
 		return
 	}
 
 	selectFields := make([]any, 0, len(pj))
 	for _, field := range pj {
+		if field.Exclude {
+			continue
+		}
 		if len(field.Alias) > 0 {
 			selectFields = append(selectFields, I(field.Name).As(field.Alias), I(field.Name))
 		} else {
@@ -202,6 +246,10 @@ func hasStar(pj query.Projection) bool {
 	})
 }
 
+func isIncluded(pf query.ProjectionField) bool {
+	return !pf.Exclude
+}
+
 func match(pj query.Projection, predicate func(pf query.ProjectionField) bool) bool {
 	for _, field := range pj {
 		if predicate(field) {
@@ -211,35 +259,73 @@ func match(pj query.Projection, predicate func(pf query.ProjectionField) bool) b
 	return false
 }
 
-func predicteToExpressions(q query.Predicate) (expressions []Expression) {
+func predicteToExpressions(q query.Predicate, postGIS bool) (expressions []Expression) {
 	for _, e := range q {
 		switch t := e.(type) {
 		case *query.And:
 			for _, subExp := range *t {
-				expressions = append(expressions, And(predicteToExpressions(query.Predicate{subExp})...))
+				expressions = append(expressions, And(predicteToExpressions(query.Predicate{subExp}, postGIS)...))
 			}
 		case *query.Or:
 			for _, subExp := range *t {
-				expressions = append(expressions, Or(predicteToExpressions(query.Predicate{subExp})...))
+				expressions = append(expressions, Or(predicteToExpressions(query.Predicate{subExp}, postGIS)...))
 			}
 		case *query.In:
-			expressions = append(expressions, C(postgresJsonbSupport(t.Field)).In(t.Values))
+			if t.CaseInsensitive {
+				values := make([]interface{}, len(t.Values))
+				for i, v := range t.Values {
+					if s, ok := v.(string); ok {
+						v = strings.ToLower(s)
+					}
+					values[i] = v
+				}
+				expressions = append(expressions, L("LOWER(?)", aggregateColumn(t.Field)).In(values))
+				continue
+			}
+			expressions = append(expressions, aggregateColumn(t.Field).In(t.Values))
 		case *query.NotIn:
-			expressions = append(expressions, C(postgresJsonbSupport(t.Field)).NotIn(t.Values))
+			expressions = append(expressions, aggregateColumn(t.Field).NotIn(t.Values))
 		case *query.Equal:
-			expressions = append(expressions, C(postgresJsonbSupport(t.Field)).Eq(t.Value))
+			if s, ok := t.Value.(string); ok && t.CaseInsensitive {
+				expressions = append(expressions, aggregateColumn(t.Field).ILike(escapeLike(s)))
+				continue
+			}
+			expressions = append(expressions, aggregateColumn(t.Field).Eq(t.Value))
 		case *query.NotEqual:
-			expressions = append(expressions, C(postgresJsonbSupport(t.Field)).Neq(t.Value))
+			expressions = append(expressions, aggregateColumn(t.Field).Neq(t.Value))
 		case *query.GreaterThan:
-			expressions = append(expressions, C(postgresJsonbSupport(t.Field)).Gt(t.Value))
+			expressions = append(expressions, aggregateColumn(t.Field).Gt(t.Value))
 		case *query.GreaterOrEqual:
-			expressions = append(expressions, C(postgresJsonbSupport(t.Field)).Gte(t.Value))
+			expressions = append(expressions, aggregateColumn(t.Field).Gte(t.Value))
 		case *query.LowerThan:
-			expressions = append(expressions, C(postgresJsonbSupport(t.Field)).Lt(t.Value))
+			expressions = append(expressions, aggregateColumn(t.Field).Lt(t.Value))
 		case *query.LowerOrEqual:
-			expressions = append(expressions, C(postgresJsonbSupport(t.Field)).Lte(t.Value))
+			expressions = append(expressions, aggregateColumn(t.Field).Lte(t.Value))
 		case *query.Regex:
-			expressions = append(expressions, C(postgresJsonbSupport(t.Field)).RegexpLike(t.Value))
+			expressions = append(expressions, aggregateColumn(t.Field).RegexpLike(t.Value))
+		case *query.Not:
+			expressions = append(expressions, L("NOT ?", And(predicteToExpressions(query.Predicate(*t), postGIS)...)))
+		case *query.Nor:
+			expressions = append(expressions, L("NOT ?", Or(predicteToExpressions(query.Predicate(*t), postGIS)...)))
+		case *query.All:
+			values, err := json.Marshal(t.Values)
+			if err != nil {
+				logrus.Warnln("invalid $all values. ignored")
+				continue
+			}
+			expressions = append(expressions, L("? @> ?::jsonb", jsonbColumn(t.Field), string(values)))
+		case *query.Size:
+			expressions = append(expressions, L("jsonb_array_length(?) = ?", jsonbColumn(t.Field), t.Value))
+		case *query.Type:
+			expressions = append(expressions, typeToExpression(t))
+		case *query.Mod:
+			expressions = append(expressions, L("MOD(TRUNC((?)::numeric)::bigint, ?) = ?", aggregateColumn(t.Field), t.Divisor, t.Remainder))
+		case *query.Near:
+			expressions = append(expressions, nearToExpression(t, postGIS))
+		case *query.GeoWithin:
+			expressions = append(expressions, geoWithinToExpression(t, postGIS))
+		case *query.Search:
+			expressions = append(expressions, searchToExpression(t))
 
 		default:
 			logrus.Warnln("not supported predicate. ignored")
@@ -248,29 +334,27 @@ func predicteToExpressions(q query.Predicate) (expressions []Expression) {
 	return
 }
 
-func postgresJsonbSupport(field string) string {
-	if !strings.Contains(field, ".") {
-		return field
-	}
+// escapeLike escapes the LIKE wildcards of a string so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
 
-	strs := strings.Split(field, ".")
-	buf := bytes.Buffer{}
-	buf.WriteString("jsonb_extract_path_text(")
-	buf.WriteString(strs[0])
-	buf.WriteRune(',')
-
-	substr := strs[1:]
-	length := len(substr)
-	for i, str := range substr {
-		buf.WriteRune('\'')
-		buf.WriteString(str)
-		buf.WriteRune('\'')
-
-		if i < length-1 {
-			buf.WriteRune(',')
-		}
+// typeToExpression matches the JSON type of a column value, as returned by
+// jsonb_typeof. Nested fields are extracted as JSONB to keep their type.
+func typeToExpression(t *query.Type) Expression {
+	col := jsonbColumn(t.Field)
+	switch t.Type {
+	case query.TypeNull:
+		return L("(? IS NULL OR jsonb_typeof(to_jsonb(?)) = 'null')", col, col)
+	case query.TypeDate:
+		return L("pg_typeof(?)::text LIKE 'timestamp%'", col)
+	case query.TypeInt:
+		return L("CASE WHEN jsonb_typeof(to_jsonb(?)) = 'number' "+
+			"THEN to_jsonb(?)::numeric = TRUNC(to_jsonb(?)::numeric) ELSE FALSE END", col, col, col)
+	case query.TypeNumber, query.TypeDouble:
+		return L("jsonb_typeof(to_jsonb(?)) = 'number'", col)
+	case query.TypeBool:
+		return L("jsonb_typeof(to_jsonb(?)) = 'boolean'", col)
 	}
-	buf.WriteRune(')')
-
-	return buf.String()
+	return L("jsonb_typeof(to_jsonb(?)) = ?", col, t.Type)
 }
@@ -0,0 +1,63 @@
+package pgsql
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/doug-martin/goqu/v9"
+	"github.com/entropyinf/rest-layer/schema"
+	"github.com/entropyinf/rest-layer/schema/query"
+)
+
+func TestBuildWheresBindsNestedKeys(t *testing.T) {
+	s := schema.Schema{
+		Fields: schema.Fields{
+			"meta": {
+				Filterable: true,
+				Validator:  &schema.Dict{Values: schema.Field{Filterable: true, Validator: &schema.Integer{}}},
+			},
+		},
+	}
+	key := "x') = 1 OR 1=1 OR TRUE IN (SELECT true"
+	for _, op := range []string{`{"$mod": [2, 0]}`, `{"$type": "int"}`, `1`, `{"$in": [1, 2]}`} {
+		t.Run(op, func(t *testing.T) {
+			p, err := query.ParsePredicate(`{"meta.` + key + `": ` + op + `}`)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			if err := p.Prepare(s); err != nil {
+				t.Fatalf("unexpected prepare error: %v", err)
+			}
+			builder := From("t")
+			buildWheres(&query.Query{Predicate: p}, builder, false)
+			sql, args, err := builder.Prepared(true).ToSQL()
+			if err != nil {
+				t.Fatalf("unexpected build error: %v", err)
+			}
+			if strings.Contains(sql, "1=1") {
+				t.Errorf("nested key not bound as a parameter: %s", sql)
+			}
+			found := false
+			for _, arg := range args {
+				if arg == key {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("nested key not found in args: %#v", args)
+			}
+		})
+	}
+}
+
+func TestBuildSortsCollation(t *testing.T) {
+	builder := From("t")
+	buildSorts(&query.Query{Sort: query.Sort{{Name: "name", Collation: "fr"}}}, builder, false)
+	sql, _, err := builder.Prepared(true).ToSQL()
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if want := `ORDER BY "name" COLLATE "fr-x-icu" ASC`; !strings.Contains(sql, want) {
+		t.Errorf("got %s, wanted it to contain %s", sql, want)
+	}
+}
@@ -0,0 +1,118 @@
+package pgsql
+
+import (
+	"strconv"
+	"strings"
+
+	. "github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/entropyinf/rest-layer/schema/query"
+)
+
+// geoColumn returns the JSONB expression of a geo point field, stored as a
+// GeoJSON point.
+func geoColumn(field string) exp.Expression {
+	if !strings.Contains(field, ".") {
+		return C(field)
+	}
+	path := strings.Split(field, ".")
+	args := []interface{}{C(path[0])}
+	for _, p := range path[1:] {
+		args = append(args, p)
+	}
+	return L("jsonb_extract_path(?"+strings.Repeat(", ?", len(path)-1)+")", args...)
+}
+
+// geoCoordinates returns the longitude and latitude expressions of a GeoJSON
+// point column.
+func geoCoordinates(col exp.Expression) (lng, lat exp.LiteralExpression) {
+	return L("(?->'coordinates'->>0)::float8", col), L("(?->'coordinates'->>1)::float8", col)
+}
+
+// geoGeometry returns the PostGIS geometry of a GeoJSON point column.
+func geoGeometry(col exp.Expression) exp.LiteralExpression {
+	return L("ST_SetSRID(ST_GeomFromGeoJSON(?::text), 4326)", col)
+}
+
+// geoPoint returns the PostGIS geometry of a point.
+func geoPoint(p query.GeoCoordinates) exp.LiteralExpression {
+	return L("ST_SetSRID(ST_MakePoint(?::float8, ?::float8), 4326)", p.Lng, p.Lat)
+}
+
+// geoDistance returns the distance in meters between a GeoJSON point column
+// and a point. Without PostGIS, the distance is computed using the haversine
+// formula.
+func geoDistance(col exp.Expression, p query.GeoCoordinates, postGIS bool) exp.LiteralExpression {
+	if postGIS {
+		return L("ST_Distance(?::geography, ?::geography)", geoGeometry(col), geoPoint(p))
+	}
+	lng, lat := geoCoordinates(col)
+	return L("2 * ?::float8 * ASIN(SQRT(LEAST(1, "+
+		"POWER(SIN(RADIANS(? - ?::float8) / 2), 2) + "+
+		"COS(RADIANS(?::float8)) * COS(RADIANS(?)) * POWER(SIN(RADIANS(? - ?::float8) / 2), 2))))",
+		query.EarthRadius, lat, p.Lat, p.Lat, lat, lng, p.Lng)
+}
+
+// geoWithinDistance matches GeoJSON point columns within a distance in meters
+// of a point.
+func geoWithinDistance(col exp.Expression, p query.GeoCoordinates, distance float64, postGIS bool) Expression {
+	if postGIS {
+		return L("ST_DWithin(?::geography, ?::geography, ?::float8)", geoGeometry(col), geoPoint(p), distance)
+	}
+	return L("? <= ?::float8", geoDistance(col, p, false), distance)
+}
+
+func nearToExpression(t *query.Near, postGIS bool) Expression {
+	col := geoColumn(t.Field)
+	if t.MaxDistance == 0 {
+		return L("? IS NOT NULL", col)
+	}
+	return geoWithinDistance(col, t.Point, t.MaxDistance, postGIS)
+}
+
+func geoWithinToExpression(t *query.GeoWithin, postGIS bool) Expression {
+	col := geoColumn(t.Field)
+	switch s := t.Shape.(type) {
+	case query.GeoBox:
+		if postGIS {
+			return L("ST_Covers(ST_MakeEnvelope(?::float8, ?::float8, ?::float8, ?::float8, 4326), ?)",
+				s.MinLng, s.MinLat, s.MaxLng, s.MaxLat, geoGeometry(col))
+		}
+		lng, lat := geoCoordinates(col)
+		return L("(? BETWEEN ?::float8 AND ?::float8 AND ? BETWEEN ?::float8 AND ?::float8)",
+			lng, s.MinLng, s.MaxLng, lat, s.MinLat, s.MaxLat)
+	case query.GeoCircle:
+		return geoWithinDistance(col, s.Center, s.Radius, postGIS)
+	case query.GeoPolygon:
+		if postGIS {
+			return L("ST_Covers(ST_GeomFromText(?, 4326), ?)", polygonWKT(s), geoGeometry(col))
+		}
+		lng, lat := geoCoordinates(col)
+		return L("point(?, ?) <@ ?::polygon", lng, lat, polygonText(s))
+	}
+	return L("FALSE")
+}
+
+// polygonText returns the representation of a polygon as expected by the
+// PostgreSQL polygon type: ((lng,lat),...).
+func polygonText(p query.GeoPolygon) string {
+	points := make([]string, 0, len(p))
+	for _, c := range p {
+		points = append(points, "("+formatFloat(c.Lng)+","+formatFloat(c.Lat)+")")
+	}
+	return "(" + strings.Join(points, ",") + ")"
+}
+
+// polygonWKT returns the Well-Known Text representation of a polygon, closed
+// on its first point: POLYGON((lng lat, ...)).
+func polygonWKT(p query.GeoPolygon) string {
+	points := make([]string, 0, len(p)+1)
+	for _, c := range append(p, p[0]) {
+		points = append(points, formatFloat(c.Lng)+" "+formatFloat(c.Lat))
+	}
+	return "POLYGON((" + strings.Join(points, ", ") + "))"
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
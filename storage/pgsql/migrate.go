@@ -3,6 +3,7 @@ package pgsql
 import (
 	"context"
 	"fmt"
+	"github.com/entropyinf/rest-layer/resource"
 	"github.com/entropyinf/rest-layer/schema"
 	"github.com/sirupsen/logrus"
 	"log"
@@ -64,7 +65,7 @@ func buildCreateTable(s *schema.Schema) (sqlQuery string, sqlParams []any, err e
 			fieldStrings = append(fieldStrings, fmt.Sprintf("%s VARCHAR", fieldName))
 		case *schema.Reference:
 			fieldStrings = append(fieldStrings, fmt.Sprintf("%s BIGINT", fieldName))
-		case *schema.Object, *schema.Dict, *schema.Array:
+		case *schema.Object, *schema.Dict, *schema.Array, *schema.GeoPoint:
 			fieldStrings = append(fieldStrings, fmt.Sprintf("%s JSONB", fieldName))
 		case nil:
 			log.Fatalln("validator required")
@@ -74,6 +75,9 @@ func buildCreateTable(s *schema.Schema) (sqlQuery string, sqlParams []any, err e
 	}
 
 	fieldStrings = append(fieldStrings, "etag CHAR(32)")
+	if s.Version > 0 {
+		fieldStrings = append(fieldStrings, fmt.Sprintf(`"%s" INTEGER`, resource.SchemaVersionField))
+	}
 
 	return strings.Join(fieldStrings, ","), []any{}, nil
 }
@@ -15,6 +15,7 @@ type store struct {
 	db         *sql.DB
 	schema     *schema.Schema
 	jsonFields schema.Fields
+	postGIS    bool
 }
 
 func NewStore(table string, db *sql.DB, sc *schema.Schema, options ...Option) resource.Storer {
@@ -36,7 +37,7 @@ func getJsonFields(fields schema.Fields) schema.Fields {
 	jsonColumns := make(map[string]schema.Field, 0)
 	for name, field := range fields {
 		switch field.Validator.(type) {
-		case *schema.Object, *schema.Array, *schema.Dict:
+		case *schema.Object, *schema.Array, *schema.Dict, *schema.GeoPoint:
 			jsonColumns[name] = field
 		case nil:
 			if field.Schema != nil {
@@ -55,3 +56,11 @@ func AutoMigrate() Option {
 		}
 	}
 }
+
+// PostGIS makes geospatial queries use the PostGIS extension functions instead
+// of plain SQL math. Geo points are still stored as GeoJSON in JSONB columns.
+func PostGIS() Option {
+	return func(s *store) {
+		s.postGIS = true
+	}
+}